@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log/slog"
 	"os"
 	"os/signal"
@@ -11,8 +12,10 @@ import (
 
 	apiserver "github.com/irbgeo/apartment-bot/internal/api/server"
 	"github.com/irbgeo/apartment-bot/internal/client"
+	"github.com/irbgeo/apartment-bot/internal/client/payment"
 	tgbot "github.com/irbgeo/apartment-bot/internal/client/tg"
 	"github.com/irbgeo/apartment-bot/internal/client/tg/message"
+	"github.com/irbgeo/apartment-bot/internal/tracing"
 )
 
 type configuration struct {
@@ -21,10 +24,24 @@ type configuration struct {
 	TelegramBotSendPeriod                    time.Duration `envconfig:"TELEGRAM_BOT_SEND_PERIOD" default:"10s"`
 	TelegramBotMaxCountSendMessagesPerPeriod int           `envconfig:"TELEGRAM_BOT_MAX_COUNT_SEND_MESSAGES_PER_PERIOD" default:"3"`
 	TelegramBotAdminUsername                 string        `envconfig:"TELEGRAM_BOT_ADMIN_USERNAME" default:"geoirb"`
+	TelegramBotAdminChatID                   int64         `envconfig:"TELEGRAM_BOT_ADMIN_CHAT_ID" default:"0"`
 	TelegramBotDisabledParameters            []string      `envconfig:"TELEGRAM_BOT_DISABLED_PARAMS" default:""`
+	TelegramBotNotifyRemovedApartments       bool          `envconfig:"TELEGRAM_BOT_NOTIFY_REMOVED_APARTMENTS" default:"false"`
+	TelegramBotPaymentProvider               string        `envconfig:"TELEGRAM_BOT_PAYMENT_PROVIDER" default:"telegram"`
+	TelegramBotPaymentToken                  string        `envconfig:"TELEGRAM_BOT_PAYMENT_TOKEN" default:""`
+	TelegramBotPaymentCurrency               string        `envconfig:"TELEGRAM_BOT_PAYMENT_CURRENCY" default:"XTR"`
+	TelegramBotWebhookListen                 string        `envconfig:"TELEGRAM_BOT_WEBHOOK_LISTEN" default:":8443"`
+	TelegramBotWebhookPublicURL              string        `envconfig:"TELEGRAM_BOT_WEBHOOK_PUBLIC_URL" default:""`
+	TelegramBotWebhookSecretToken            string        `envconfig:"TELEGRAM_BOT_WEBHOOK_SECRET_TOKEN" default:""`
+	TelegramBotWebhookCertFile               string        `envconfig:"TELEGRAM_BOT_WEBHOOK_CERT_FILE" default:""`
+	TelegramBotWebhookKeyFile                string        `envconfig:"TELEGRAM_BOT_WEBHOOK_KEY_FILE" default:""`
 	FirstCities                              []string      `envconfig:"FIRST_CITIES" default:"Tbilisi,Batumi"`
 	AuthToken                                string        `envconfig:"AUTH_TOKEN" require:"true"`
+	AdminAuthToken                           string        `envconfig:"ADMIN_AUTH_TOKEN" default:""`
 	ClientTag                                int64         `envconfig:"CLIENT_TAG" default:"1"`
+	TracingEndpoint                          string        `envconfig:"TRACING_ENDPOINT" default:""`
+	TracingInsecure                          bool          `envconfig:"TRACING_INSECURE" default:"true"`
+	TracingSampleRatio                       float64       `envconfig:"TRACING_SAMPLE_RATIO" default:"1"`
 }
 
 func main() {
@@ -38,7 +55,20 @@ func main() {
 
 	slog.Info("configuration", "cfg", cfg)
 
-	serverCli, err := apiserver.NewClient(cfg.ServerURL, cfg.AuthToken, cfg.ClientTag)
+	tracingCfg := tracing.Config{
+		ServiceName: "apartment-bot-client",
+		Endpoint:    cfg.TracingEndpoint,
+		Insecure:    cfg.TracingInsecure,
+		SampleRatio: cfg.TracingSampleRatio,
+	}
+	shutdownTracing, err := tracing.Init(tracingCfg)
+	if err != nil {
+		slog.Error("init tracing", "err", err)
+		os.Exit(1)
+	}
+	defer shutdownTracing(context.Background()) // nolint: errcheck
+
+	serverCli, err := apiserver.NewClient(cfg.ServerURL, cfg.AuthToken, cfg.AdminAuthToken, cfg.ClientTag)
 	if err != nil {
 		slog.Error("init server cli", "err", err)
 		os.Exit(1)
@@ -62,14 +92,31 @@ func main() {
 		Token:               cfg.TelegramBotToken,
 		DisabledParameters:  cfg.TelegramBotDisabledParameters,
 		AdminUsername:       cfg.TelegramBotAdminUsername,
+		AdminChatID:         cfg.TelegramBotAdminChatID,
 		MaxPhotoCount:       cfg.TelegramBotMaxCountSendMessagesPerPeriod,
 		MessageSendInterval: cfg.TelegramBotSendPeriod,
+
+		NotifyRemovedApartments: cfg.TelegramBotNotifyRemovedApartments,
+		Webhook: tgbot.WebhookConfig{
+			Listen:      cfg.TelegramBotWebhookListen,
+			PublicURL:   cfg.TelegramBotWebhookPublicURL,
+			SecretToken: cfg.TelegramBotWebhookSecretToken,
+			CertFile:    cfg.TelegramBotWebhookCertFile,
+			KeyFile:     cfg.TelegramBotWebhookKeyFile,
+		},
+	}
+
+	payments, err := payment.New(cfg.TelegramBotPaymentProvider, cfg.TelegramBotPaymentToken, cfg.TelegramBotPaymentCurrency)
+	if err != nil {
+		slog.Error("init payments", "err", err)
+		os.Exit(1)
 	}
 
 	b, err := tgbot.NewService(
 		botCfg,
 		cli,
 		massageStack,
+		payments,
 	)
 	if err != nil {
 		slog.Error("init bot", "err", err)
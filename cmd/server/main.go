@@ -11,27 +11,75 @@ import (
 	"github.com/kelseyhightower/envconfig"
 
 	"github.com/irbgeo/apartment-bot/internal/apartment"
+	"github.com/irbgeo/apartment-bot/internal/apartment/provider/generic"
+	"github.com/irbgeo/apartment-bot/internal/apartment/provider/replay"
 	"github.com/irbgeo/apartment-bot/internal/apartment/provider/ssge"
 	"github.com/irbgeo/apartment-bot/internal/api/health"
 	api "github.com/irbgeo/apartment-bot/internal/api/server"
+	"github.com/irbgeo/apartment-bot/internal/currency"
+	"github.com/irbgeo/apartment-bot/internal/estimate"
 	"github.com/irbgeo/apartment-bot/internal/filter"
+	"github.com/irbgeo/apartment-bot/internal/gazetteer"
+	"github.com/irbgeo/apartment-bot/internal/score"
 	"github.com/irbgeo/apartment-bot/internal/server"
+	"github.com/irbgeo/apartment-bot/internal/stats"
 	"github.com/irbgeo/apartment-bot/internal/storage/mongo"
+	"github.com/irbgeo/apartment-bot/internal/tracing"
 )
 
 type configuration struct {
-	Address                 string        `envconfig:"ADDRESS" default:":9000"`
-	HealthAddress           string        `envconfig:"HEALTH_ADDRESS" default:":9005"`
-	MongoAddress            string        `envconfig:"MONGO_ADDRESS" default:"localhost:27017"`
-	MongoUsername           string        `envconfig:"MONGO_USERNAME" default:"root"`
-	MongoPassword           string        `envconfig:"MONGO_PASSWORD" default:"password"`
-	MongoDatabase           string        `envconfig:"MONGO_DATABASE" default:"apartment"`
-	MaxFetchPages           int64         `envconfig:"MAX_FETCH_PAGES" default:"30"`
-	ApartmentUpdateInterval time.Duration `envconfig:"APARTMENT_UPDATE_INTERVAL" default:"1m"`
-	ApartmentDayToLive      int64         `envconfig:"APARTMENT_DAY_TO_LIVE" default:"7"`
-	RefreshTokenInterval    time.Duration `envconfig:"REFRESH_TOKEN_INTERVAL" default:"10m"`
-	WithRefreshApartments   bool          `envconfig:"WITH_REFRESH_APARTMENTS" default:"false"`
-	AuthToken               string        `envconfig:"AUTH_TOKEN" default:"test"`
+	Address                     string        `envconfig:"ADDRESS" default:":9000"`
+	HealthAddress               string        `envconfig:"HEALTH_ADDRESS" default:":9005"`
+	HealthCheckInterval         time.Duration `envconfig:"HEALTH_CHECK_INTERVAL" default:"10s"`
+	HealthCheckTimeout          time.Duration `envconfig:"HEALTH_CHECK_TIMEOUT" default:"5s"`
+	MongoAddress                string        `envconfig:"MONGO_ADDRESS" default:"localhost:27017"`
+	MongoUsername               string        `envconfig:"MONGO_USERNAME" default:"root"`
+	MongoPassword               string        `envconfig:"MONGO_PASSWORD" default:"password"`
+	MongoDatabase               string        `envconfig:"MONGO_DATABASE" default:"apartment"`
+	MongoArchiveRetention       time.Duration `envconfig:"MONGO_ARCHIVE_RETENTION" default:"8760h"`
+	MaxFetchPages               int64         `envconfig:"MAX_FETCH_PAGES" default:"30"`
+	ApartmentUpdateInterval     time.Duration `envconfig:"APARTMENT_UPDATE_INTERVAL" default:"1m"`
+	ApartmentDayToLive          int64         `envconfig:"APARTMENT_DAY_TO_LIVE" default:"7"`
+	ApartmentMaxUpdateLag       time.Duration `envconfig:"APARTMENT_MAX_UPDATE_LAG" default:"15m"`
+	ApartmentBackfillInterval   time.Duration `envconfig:"APARTMENT_BACKFILL_INTERVAL" default:"1h"`
+	ApartmentBreakerMaxFailures int           `envconfig:"APARTMENT_BREAKER_MAX_FAILURES" default:"5"`
+	ApartmentBreakerOpenTimeout time.Duration `envconfig:"APARTMENT_BREAKER_OPEN_TIMEOUT" default:"5m"`
+	RefreshTokenInterval        time.Duration `envconfig:"REFRESH_TOKEN_INTERVAL" default:"10m"`
+	SSGEWorkers                 int           `envconfig:"SSGE_WORKERS" default:"4"`
+	SSGERateLimit               float64       `envconfig:"SSGE_RATE_LIMIT" default:"5"`
+	SSGERateBurst               int           `envconfig:"SSGE_RATE_BURST" default:"5"`
+	SSGEMaxRetries              int           `envconfig:"SSGE_MAX_RETRIES" default:"3"`
+	Provider                    string        `envconfig:"PROVIDER" default:"ssge"`
+	ProviderRecordDir           string        `envconfig:"PROVIDER_RECORD_DIR" default:""`
+	ProviderReplayDir           string        `envconfig:"PROVIDER_REPLAY_DIR" default:""`
+	ProviderReplaySpeed         float64       `envconfig:"PROVIDER_REPLAY_SPEED" default:"1"`
+	ProviderConfig              string        `envconfig:"PROVIDER_CONFIG" default:""`
+	ScoreUpdateInterval         time.Duration `envconfig:"SCORE_UPDATE_INTERVAL" default:"1h"`
+	StatsUpdateInterval         time.Duration `envconfig:"STATS_UPDATE_INTERVAL" default:"1h"`
+	EstimateTrainInterval       time.Duration `envconfig:"ESTIMATE_TRAIN_INTERVAL" default:"6h"`
+	CurrencySource              string        `envconfig:"CURRENCY_SOURCE" default:"static"`
+	CurrencyRates               string        `envconfig:"CURRENCY_RATES" default:"GEL:2.7,EUR:0.92"`
+	CurrencyUpdateInterval      time.Duration `envconfig:"CURRENCY_UPDATE_INTERVAL" default:"6h"`
+	WithRefreshApartments       bool          `envconfig:"WITH_REFRESH_APARTMENTS" default:"false"`
+	PlansFile                   string        `envconfig:"PLANS_FILE" default:""`
+	GazetteerFile               string        `envconfig:"GAZETTEER_FILE" default:""`
+	AuthToken                   string        `envconfig:"AUTH_TOKEN" default:"test"`
+	AdminAuthToken              string        `envconfig:"ADMIN_AUTH_TOKEN" default:""`
+	TracingEndpoint             string        `envconfig:"TRACING_ENDPOINT" default:""`
+	TracingInsecure             bool          `envconfig:"TRACING_INSECURE" default:"true"`
+	TracingSampleRatio          float64       `envconfig:"TRACING_SAMPLE_RATIO" default:"1"`
+}
+
+// apartmentProvider is the source of the apartments selected by PROVIDER
+type apartmentProvider interface {
+	health.Checker
+	Name() string
+	Listings(ctx context.Context, page int64, scope server.CrawlScope) ([]apartment.Listing, error)
+	Apartments(ctx context.Context, listings []apartment.Listing) []server.Apartment
+	Check(ctx context.Context, a server.Apartment) (server.RemovalReason, error)
+	InCache(id int64) bool
+	SetInCache(a server.Apartment)
+	DeleteFromCache(a server.Apartment)
 }
 
 func main() {
@@ -45,11 +93,26 @@ func main() {
 
 	slog.Info("configuration", "cfg", cfg)
 
+	tracingCfg := tracing.Config{
+		ServiceName: "apartment-bot-server",
+		Endpoint:    cfg.TracingEndpoint,
+		Insecure:    cfg.TracingInsecure,
+		SampleRatio: cfg.TracingSampleRatio,
+	}
+	shutdownTracing, err := tracing.Init(tracingCfg)
+	if err != nil {
+		slog.Error("init tracing", "err", err)
+		os.Exit(1)
+	}
+	defer shutdownTracing(context.Background()) // nolint: errcheck
+
 	mongoCfg := mongo.Config{
 		Address:  cfg.MongoAddress,
 		Username: cfg.MongoUsername,
 		Password: cfg.MongoPassword,
 		Database: cfg.MongoDatabase,
+
+		ArchiveRetention: cfg.MongoArchiveRetention,
 	}
 	stor, err := mongo.NewStorage(mongoCfg)
 	if err != nil {
@@ -57,37 +120,147 @@ func main() {
 		os.Exit(1)
 	}
 
-	filterProvider, err := filter.New(stor)
+	// start scorer for calculating district median prices
+	scorer := score.New(stor)
+	if err := scorer.Start(cfg.ScoreUpdateInterval); err != nil {
+		slog.Error("start scorer", "err", err)
+		os.Exit(1)
+	}
+	defer scorer.Stop()
+
+	// start currency rates, the static rates are the fallback of the online source
+	staticRates, err := currency.ParseRates(cfg.CurrencyRates)
+	if err != nil {
+		slog.Error("parse currency rates", "err", err)
+		os.Exit(1)
+	}
+
+	var ratesSource currency.Source
+	switch cfg.CurrencySource {
+	case "static":
+		ratesSource = currency.NewStatic(staticRates)
+	case "nbg":
+		ratesSource = currency.NewNBG()
+	default:
+		slog.Error("unknown currency source", "source", cfg.CurrencySource)
+		os.Exit(1)
+	}
+
+	rates := currency.NewService(ratesSource, staticRates)
+	if err := rates.Start(cfg.CurrencyUpdateInterval); err != nil {
+		slog.Error("start currency rates", "err", err)
+		os.Exit(1)
+	}
+	defer rates.Stop()
+
+	filterProvider, err := filter.New(stor, scorer, rates)
 	if err != nil {
 		slog.Error("init filters", "err", err)
 		os.Exit(1)
 	}
 
-	ssProvider := ssge.NewSSGEProvider()
+	// start estimator for fair prices of apartments
+	estimator := estimate.New(stor)
+	if err := estimator.Start(cfg.EstimateTrainInterval); err != nil {
+		slog.Error("start estimator", "err", err)
+		os.Exit(1)
+	}
+	defer estimator.Stop()
+
+	places, err := gazetteer.New(cfg.GazetteerFile)
+	if err != nil {
+		slog.Error("load gazetteer", "err", err)
+		os.Exit(1)
+	}
+
+	providerCfg := ssge.Config{
+		Workers:    cfg.SSGEWorkers,
+		RateLimit:  cfg.SSGERateLimit,
+		RateBurst:  cfg.SSGERateBurst,
+		MaxRetries: cfg.SSGEMaxRetries,
+		Places:     places,
+	}
+
+	var provider apartmentProvider
+	switch cfg.Provider {
+	case "ssge":
+		// record the responses of ss.ge for the replay
+		if cfg.ProviderRecordDir != "" {
+			providerCfg.Transport, err = replay.NewRecorder(cfg.ProviderRecordDir, nil)
+			if err != nil {
+				slog.Error("create provider recorder", "err", err)
+				os.Exit(1)
+			}
+		}
+	case "replay":
+		providerCfg.Name = "replay"
+		transport, err := replay.NewTransport(replay.Config{
+			Dir:   cfg.ProviderReplayDir,
+			Speed: cfg.ProviderReplaySpeed,
+		})
+		if err != nil {
+			slog.Error("load provider recordings", "err", err)
+			os.Exit(1)
+		}
+		providerCfg.Transport = transport
+		providerCfg.Now = transport.Now
+	case "generic":
+		genericCfg, err := generic.LoadConfig(cfg.ProviderConfig)
+		if err != nil {
+			slog.Error("load provider config", "err", err)
+			os.Exit(1)
+		}
+		provider = generic.New(genericCfg)
+	default:
+		slog.Error("unknown provider", "provider", cfg.Provider)
+		os.Exit(1)
+	}
+
+	if provider == nil {
+		ssProvider := ssge.NewSSGEProvider(providerCfg)
+
+		// start provider service for refreshing access token
+		if err := ssProvider.Start(cfg.RefreshTokenInterval); err != nil {
+			slog.Error("start ssge provider", "err", err)
+			os.Exit(1)
+		}
+		defer ssProvider.Stop()
+
+		provider = ssProvider
+	}
 
 	apartmentCfg := apartment.Config{
-		MaxFetchPages: cfg.MaxFetchPages,
-		ApartmentTTL:  time.Duration(cfg.ApartmentDayToLive) * 24 * time.Hour,
+		MaxFetchPages:    cfg.MaxFetchPages,
+		ApartmentTTL:     time.Duration(cfg.ApartmentDayToLive) * 24 * time.Hour,
+		MaxUpdateLag:     cfg.ApartmentMaxUpdateLag,
+		BackfillInterval: cfg.ApartmentBackfillInterval,
+
+		BreakerMaxFailures: cfg.ApartmentBreakerMaxFailures,
+		BreakerOpenTimeout: cfg.ApartmentBreakerOpenTimeout,
 	}
 
 	apartmentSvc := apartment.NewService(
 		apartmentCfg,
-		ssProvider,
+		provider,
+		stor,
 	)
 
+	plans, err := server.LoadPlans(cfg.PlansFile)
+	if err != nil {
+		slog.Error("load plans", "err", err)
+		os.Exit(1)
+	}
+
 	srv := server.NewService(
 		apartmentSvc,
 		stor,
 		filterProvider,
+		estimator,
+		rates,
+		places,
+		plans,
 	)
 
-	// start provider service for refreshing access token
-	if err := ssProvider.Start(cfg.RefreshTokenInterval); err != nil {
-		slog.Error("start ssge provider", "err", err)
-		os.Exit(1)
-	}
-	defer ssProvider.Stop()
-
 	// refresh apartments
 	if cfg.WithRefreshApartments {
 		if err := srv.RefreshApartments(); err != nil {
@@ -119,6 +292,14 @@ func main() {
 		os.Exit(1)
 	}
 
+	// start daily market statistics
+	statsSvc := stats.NewService(stor)
+	if err := statsSvc.Start(cfg.StatsUpdateInterval); err != nil {
+		slog.Error("start stats service", "err", err)
+		os.Exit(1)
+	}
+	defer statsSvc.Stop()
+
 	if err := srv.Start(); err != nil {
 		slog.Error("start server", "err", err)
 		os.Exit(1)
@@ -127,15 +308,29 @@ func main() {
 
 	// start api
 	go func() {
-		if err := api.ListenAndServe(cfg.Address, cfg.AuthToken, srv); err != nil {
+		if err := api.ListenAndServe(cfg.Address, cfg.AuthToken, cfg.AdminAuthToken, srv); err != nil {
 			slog.Error("turn on server server", "err", err)
 			os.Exit(1)
 		}
 	}()
 
 	// start healthcheck
+	healthCfg := health.Config{
+		Address:       cfg.HealthAddress,
+		CheckInterval: cfg.HealthCheckInterval,
+		CheckTimeout:  cfg.HealthCheckTimeout,
+	}
+	components := []health.Component{
+		{Name: "mongo", Checker: stor, Readiness: true},
+		{Name: provider.Name(), Checker: provider, Readiness: true},
+		{Name: "apartment", Checker: apartmentSvc, Liveness: true, Readiness: true},
+		{Name: "provider", Checker: health.CheckerFunc(apartmentSvc.ProviderHealth)},
+		{Name: "currency", Checker: rates},
+		{Name: "subscribers", Checker: srv},
+	}
+
 	go func() {
-		if err := health.ListenAndServe(cfg.HealthAddress); err != nil {
+		if err := health.ListenAndServe(healthCfg, components...); err != nil {
 			slog.Error("turn on health server", "err", err)
 			os.Exit(1)
 		}
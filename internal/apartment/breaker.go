@@ -0,0 +1,132 @@
+package apartment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+// breaker is the circuit breaker of the provider, it opens after maxFailures failures in a row,
+// after openTimeout the single probe request is let through in the half-open state.
+type breaker struct {
+	mu          sync.Mutex
+	state       server.ProviderState
+	failures    int
+	isProbing   bool
+	openedAt    time.Time
+	maxFailures int
+	openTimeout time.Duration
+	now         func() time.Time
+
+	// stateCh receives the state when the provider goes down or recovers
+	stateCh chan server.ProviderState
+}
+
+func newBreaker(provider string, maxFailures int, openTimeout time.Duration) *breaker {
+	return &breaker{
+		state: server.ProviderState{
+			Provider: provider,
+			State:    server.BreakerClosed,
+			Since:    time.Now(),
+		},
+		maxFailures: max(maxFailures, 1),
+		openTimeout: openTimeout,
+		now:         time.Now,
+		stateCh:     make(chan server.ProviderState, 10),
+	}
+}
+
+// allow returns ErrProviderUnavailable while the breaker is open or the probe is in flight.
+func (b *breaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state.State {
+	case server.BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.openTimeout {
+			return ErrProviderUnavailable
+		}
+		b.setState(server.BreakerHalfOpen)
+		b.isProbing = true
+		return nil
+	case server.BreakerHalfOpen:
+		if b.isProbing {
+			return ErrProviderUnavailable
+		}
+		b.isProbing = true
+	}
+	return nil
+}
+
+// done records the result of the allowed request, the canceled requests aren't counted.
+func (b *breaker) done(err error) {
+	if errors.Is(err, context.Canceled) {
+		b.mu.Lock()
+		b.isProbing = false
+		b.mu.Unlock()
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.isProbing = false
+	if err == nil {
+		b.failures = 0
+		if b.state.State != server.BreakerClosed {
+			b.state.Err = ""
+			b.setState(server.BreakerClosed)
+		}
+		return
+	}
+
+	b.failures++
+	b.state.Err = err.Error()
+	if b.state.State == server.BreakerHalfOpen || b.failures >= b.maxFailures {
+		b.setState(server.BreakerOpen)
+	}
+}
+
+func (b *breaker) State() server.ProviderState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Err returns the error while the breaker isn't closed.
+func (b *breaker) Err() error {
+	st := b.State()
+	if st.State == server.BreakerClosed {
+		return nil
+	}
+	return fmt.Errorf("provider %s is unavailable since %s, breaker is %s: %s",
+		st.Provider, st.Since.Format(time.RFC3339), st.State, st.Err)
+}
+
+// setState notifies only the changes between the closed state and the other ones,
+// the probes of the half-open state aren't reported.
+func (b *breaker) setState(state server.BreakerState) {
+	prev := b.state.State
+	b.state.State = state
+	if state == server.BreakerOpen {
+		b.openedAt = b.now()
+	}
+
+	if (prev == server.BreakerClosed) == (state == server.BreakerClosed) {
+		return
+	}
+	b.state.Since = b.now()
+
+	slog.Warn("provider breaker", "provider", b.state.Provider, "state", state, "err", b.state.Err)
+
+	select {
+	case b.stateCh <- b.state:
+	default:
+		slog.Error("provider state dropped", "provider", b.state.Provider, "state", state)
+	}
+}
@@ -0,0 +1,69 @@
+package apartment
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	errUnavailable := errors.New("status code: 503")
+
+	b := newBreaker("fake", 2, time.Minute)
+	b.now = func() time.Time { return now }
+
+	// the failures below the threshold don't open the breaker
+	require.NoError(t, b.allow())
+	b.done(errUnavailable)
+	require.Equal(t, server.BreakerClosed, b.State().State)
+	require.NoError(t, b.Err())
+
+	// the canceled request isn't the failure
+	require.NoError(t, b.allow())
+	b.done(context.Canceled)
+	require.Equal(t, server.BreakerClosed, b.State().State)
+
+	require.NoError(t, b.allow())
+	b.done(errUnavailable)
+	require.Equal(t, server.BreakerOpen, b.State().State)
+	require.Equal(t, "status code: 503", b.State().Err)
+	require.Error(t, b.Err())
+	require.ErrorIs(t, b.allow(), ErrProviderUnavailable)
+
+	st := <-b.stateCh
+	require.Equal(t, server.BreakerOpen, st.State)
+	require.Equal(t, now, st.Since)
+
+	// the single probe is let through after the timeout, the failed probe opens the breaker again
+	now = now.Add(time.Minute)
+	require.NoError(t, b.allow())
+	require.Equal(t, server.BreakerHalfOpen, b.State().State)
+	require.ErrorIs(t, b.allow(), ErrProviderUnavailable)
+	b.done(errUnavailable)
+	require.Equal(t, server.BreakerOpen, b.State().State)
+	require.ErrorIs(t, b.allow(), ErrProviderUnavailable)
+
+	// the successful probe closes the breaker
+	now = now.Add(time.Minute)
+	require.NoError(t, b.allow())
+	b.done(nil)
+	require.Equal(t, server.BreakerClosed, b.State().State)
+	require.NoError(t, b.Err())
+
+	st = <-b.stateCh
+	require.Equal(t, server.BreakerClosed, st.State)
+	require.Equal(t, now, st.Since)
+
+	// the probes of the half-open state aren't reported
+	select {
+	case st := <-b.stateCh:
+		t.Fatalf("unexpected state %s", st.State)
+	default:
+	}
+}
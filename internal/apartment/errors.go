@@ -5,4 +5,6 @@ import "errors"
 var (
 	ErrNilProvider     = errors.New("provider is nil")
 	ErrInvalidPageSize = errors.New("max fetch pages must be positive")
+
+	ErrProviderUnavailable = errors.New("provider is unavailable")
 )
@@ -0,0 +1,9 @@
+package generic
+
+import "errors"
+
+var (
+	ErrInvalidConfig = errors.New("name, list.url, list.items, list.id and list.order_date are required")
+
+	errNotFound = errors.New("listing not found")
+)
@@ -0,0 +1,492 @@
+package generic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+	"gopkg.in/yaml.v3"
+
+	apartmentsvc "github.com/irbgeo/apartment-bot/internal/apartment"
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+var (
+	requestTimeout  = 1 * time.Minute
+	maxPageFetchAge = 10 * time.Minute
+	defaultMaxAge   = 7 * 24 * time.Hour
+	defaultPageSize = int64(20)
+
+	pagePaging   = "page"
+	offsetPaging = "offset"
+
+	adTypes = map[string]int64{
+		"rent": server.RentAdType,
+		"sale": server.SaleAdType,
+	}
+	buildingStatuses = map[string]int64{
+		"new":                server.NewBuildingStatus,
+		"under_construction": server.UnderConstructionBuildingStatus,
+		"old":                server.OldBuildingStatus,
+	}
+)
+
+// source is the provider of the JSON API described by the config.
+type source struct {
+	cfg    Config
+	client *http.Client
+
+	pageFetchedAt atomic.Int64
+
+	// items keeps the list items till the details are fetched
+	itemsMutex sync.Mutex
+	items      map[int64]any
+
+	cacheID sync.Map
+	// rawIDs are the ids of the source by the hashed ids, they're requested by the detail URL
+	rawIDs sync.Map
+}
+
+// LoadConfig reads the YAML config of the source.
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	return ParseConfig(data)
+}
+
+// ParseConfig parses and validates the YAML config, the defaults are set.
+func ParseConfig(data []byte) (Config, error) {
+	cfg := Config{}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return Config{}, err
+	}
+
+	// the incremental crawl stops at the listings older than the cursor by the order date
+	if cfg.Name == "" || cfg.List.URL == "" || cfg.List.Items == "" || cfg.List.ID == "" || cfg.List.OrderDate == "" {
+		return Config{}, ErrInvalidConfig
+	}
+
+	if cfg.List.Paging == "" {
+		cfg.List.Paging = pagePaging
+	}
+	if cfg.List.Paging != pagePaging && cfg.List.Paging != offsetPaging {
+		return Config{}, fmt.Errorf("%w: unknown paging %q", ErrInvalidConfig, cfg.List.Paging)
+	}
+	if cfg.List.FirstPage == nil {
+		firstPage := int64(1)
+		cfg.List.FirstPage = &firstPage
+	}
+	if cfg.List.PageSize == 0 {
+		cfg.List.PageSize = defaultPageSize
+	}
+	if cfg.List.Method == "" {
+		cfg.List.Method = http.MethodGet
+	}
+	if cfg.Detail.Method == "" {
+		cfg.Detail.Method = http.MethodGet
+	}
+	if cfg.MaxAge == 0 {
+		cfg.MaxAge = defaultMaxAge
+	}
+
+	for _, t := range cfg.AdTypes {
+		if _, ok := adTypes[t]; !ok {
+			return Config{}, fmt.Errorf("%w: unknown ad type %q", ErrInvalidConfig, t)
+		}
+	}
+	if _, ok := adTypes[cfg.DefaultAdType]; cfg.DefaultAdType != "" && !ok {
+		return Config{}, fmt.Errorf("%w: unknown ad type %q", ErrInvalidConfig, cfg.DefaultAdType)
+	}
+	for _, st := range cfg.BuildingStatuses {
+		if _, ok := buildingStatuses[st]; !ok {
+			return Config{}, fmt.Errorf("%w: unknown building status %q", ErrInvalidConfig, st)
+		}
+	}
+
+	for _, c := range cfg.Currencies {
+		if _, ok := server.ParseCurrency(c); !ok {
+			return Config{}, fmt.Errorf("%w: unknown currency %q", ErrInvalidConfig, c)
+		}
+	}
+	if _, ok := server.ParseCurrency(cfg.DefaultCurrency); cfg.DefaultCurrency != "" && !ok {
+		return Config{}, fmt.Errorf("%w: unknown currency %q", ErrInvalidConfig, cfg.DefaultCurrency)
+	}
+
+	return cfg, nil
+}
+
+func New(cfg Config) *source {
+	s := &source{
+		cfg: cfg,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		items: make(map[int64]any),
+	}
+
+	s.pageFetchedAt.Store(time.Now().UnixNano())
+	return s
+}
+
+// Health reports whether pages are fetched successfully.
+func (s *source) Health(_ context.Context) error {
+	fetchAge := time.Since(time.Unix(0, s.pageFetchedAt.Load()))
+	if fetchAge > maxPageFetchAge {
+		return fmt.Errorf("page was fetched %s ago", fetchAge.Round(time.Second))
+	}
+	return nil
+}
+
+func (s *source) Name() string {
+	return s.cfg.Name
+}
+
+// Listings requests the page of the list, the items of the listings which aren't in the cache are kept for Apartments.
+func (s *source) Listings(ctx context.Context, page int64, scope server.CrawlScope) ([]apartmentsvc.Listing, error) {
+	listURL, err := s.listURL(page, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := s.request(ctx, s.cfg.List.Method, listURL, s.replacePage(s.cfg.List.Body, page))
+	if err != nil {
+		return nil, err
+	}
+
+	v, ok := lookup(doc, s.cfg.List.Items)
+	if !ok {
+		return nil, fmt.Errorf("items %q not found", s.cfg.List.Items)
+	}
+	items, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("items %q is not an array", s.cfg.List.Items)
+	}
+
+	s.pageFetchedAt.Store(time.Now().UnixNano())
+
+	s.itemsMutex.Lock()
+	defer s.itemsMutex.Unlock()
+
+	result := make([]apartmentsvc.Listing, 0, len(items))
+	for _, item := range items {
+		rawID := lookupString(item, s.cfg.List.ID)
+		id, ok := toID(rawID)
+		if !ok {
+			continue
+		}
+		if strconv.FormatInt(id, 10) != rawID {
+			s.rawIDs.Store(id, rawID)
+		}
+
+		if !s.InCache(id) {
+			s.items[id] = item
+		}
+		result = append(result, apartmentsvc.Listing{
+			ID:        id,
+			OrderDate: lookupTime(item, s.cfg.List.OrderDate),
+		})
+	}
+	return result, nil
+}
+
+// Apartments fetches the details of the listings, the list items are used without the detail URL.
+func (s *source) Apartments(ctx context.Context, listings []apartmentsvc.Listing) []server.Apartment {
+	result := make([]server.Apartment, 0, len(listings))
+	for _, l := range listings {
+		s.itemsMutex.Lock()
+		item, ok := s.items[l.ID]
+		delete(s.items, l.ID)
+		s.itemsMutex.Unlock()
+
+		if s.cfg.Detail.URL != "" {
+			var err error
+			item, err = s.detail(ctx, l.ID)
+			if err != nil {
+				s.rawIDs.Delete(l.ID)
+				continue
+			}
+		} else if !ok {
+			s.rawIDs.Delete(l.ID)
+			continue
+		}
+
+		a, ok := s.toApartment(l.ID, item)
+		if !ok || s.removalReason(item, a) != server.NotRemoved {
+			s.rawIDs.Delete(l.ID)
+			continue
+		}
+
+		s.cacheID.Store(a.ID, struct{}{})
+		result = append(result, a)
+	}
+	return result
+}
+
+// Check requests the details, the missing listing is removed as not found.
+// The listings are always available without the detail URL.
+func (s *source) Check(ctx context.Context, a server.Apartment) (server.RemovalReason, error) {
+	if s.cfg.Detail.URL == "" {
+		// the listing can't be requested, so only its age is checked
+		if s.isExpired(a) {
+			return server.ExpiredRemoval, nil
+		}
+		return server.NotRemoved, nil
+	}
+
+	item, err := s.detail(ctx, a.ID)
+	if errors.Is(err, errNotFound) {
+		return server.NotFoundRemoval, nil
+	}
+	if err != nil {
+		return server.NotRemoved, err
+	}
+
+	return s.removalReason(item, a), nil
+}
+
+func (s *source) InCache(id int64) bool {
+	_, isExist := s.cacheID.Load(id)
+	return isExist
+}
+
+func (s *source) SetInCache(a server.Apartment) {
+	s.cacheID.Store(a.ID, struct{}{})
+}
+
+func (s *source) DeleteFromCache(a server.Apartment) {
+	s.cacheID.Delete(a.ID)
+	s.rawIDs.Delete(a.ID)
+}
+
+func (s *source) detail(ctx context.Context, id int64) (any, error) {
+	detailURL := strings.ReplaceAll(s.cfg.Detail.URL, "{id}", url.PathEscape(s.rawID(id)))
+
+	doc, err := s.request(ctx, s.cfg.Detail.Method, detailURL, "")
+	if err != nil {
+		return nil, err
+	}
+
+	item, ok := lookup(doc, s.cfg.Detail.Root)
+	if !ok {
+		return nil, errNotFound
+	}
+	return item, nil
+}
+
+// rawID returns the id of the source, the hashed ids are known after the list is fetched.
+func (s *source) rawID(id int64) string {
+	if v, ok := s.rawIDs.Load(id); ok {
+		return v.(string) // nolint: errcheck
+	}
+	return strconv.FormatInt(id, 10)
+}
+
+func (s *source) removalReason(item any, a server.Apartment) server.RemovalReason {
+	if isActive, ok := lookupBool(item, s.cfg.Fields.IsActive); ok && !isActive {
+		return server.InactiveRemoval
+	}
+	if s.isExpired(a) {
+		return server.ExpiredRemoval
+	}
+	return server.NotRemoved
+}
+
+// isExpired reports whether the listing is older than the max age, the listing without the date isn't.
+func (s *source) isExpired(a server.Apartment) bool {
+	return !a.OrderDate.IsZero() && time.Since(a.OrderDate) >= s.cfg.MaxAge
+}
+
+func (s *source) toApartment(id int64, item any) (server.Apartment, bool) {
+	f := s.cfg.Fields
+
+	a := server.Apartment{
+		ID:        id,
+		Rooms:     lookupFloat(item, f.Rooms),
+		Bedrooms:  int64(lookupFloat(item, f.Bedrooms)),
+		Floor:     int64(lookupFloat(item, f.Floor)),
+		Area:      lookupFloat(item, f.Area),
+		Phone:     lookupString(item, f.Phone),
+		City:      prepareTitle(lookupString(item, f.City)),
+		District:  prepareTitle(lookupString(item, f.District)),
+		Comment:   lookupString(item, f.Comment),
+		OrderDate: lookupTime(item, f.OrderDate),
+		PhotoURLs: lookupStrings(item, f.Photos),
+		URL:       lookupString(item, f.URL),
+	}
+
+	adType := s.cfg.DefaultAdType
+	if t, ok := s.cfg.AdTypes[lookupString(item, f.AdType)]; ok {
+		adType = t
+	}
+
+	var ok bool
+	if a.AdType, ok = adTypes[adType]; !ok {
+		return server.Apartment{}, false
+	}
+
+	a.OriginalPrice, a.Currency = lookupFloat(item, f.Price), s.currency(item)
+	if a.Currency == server.USD {
+		a.Price = a.OriginalPrice
+	}
+
+	a.BuildingStatus = buildingStatuses[s.cfg.BuildingStatuses[lookupString(item, f.BuildingStatus)]]
+
+	if a.URL == "" && f.URLTemplate != "" {
+		a.URL = strings.ReplaceAll(f.URLTemplate, "{id}", url.PathEscape(s.rawID(id)))
+	}
+
+	if len(f.OwnerValues) > 0 {
+		owner := lookupString(item, f.IsOwner)
+		for _, v := range f.OwnerValues {
+			a.IsOwner = a.IsOwner || strings.EqualFold(owner, v)
+		}
+	} else {
+		a.IsOwner, _ = lookupBool(item, f.IsOwner)
+	}
+
+	lat, lng := lookupFloat(item, f.Lat), lookupFloat(item, f.Lng)
+	if lat != 0 && lng != 0 {
+		a.Coordinates = &server.Coordinates{Lat: lat, Lng: lng}
+	}
+
+	return a, true
+}
+
+// currency returns the currency of the price, the price in the other currency is converted by the server.
+func (s *source) currency(item any) server.Currency {
+	code := s.cfg.DefaultCurrency
+	if c, ok := s.cfg.Currencies[lookupString(item, s.cfg.Fields.Currency)]; ok {
+		code = c
+	}
+
+	currency, _ := server.ParseCurrency(code)
+	return currency.OrUSD()
+}
+
+func (s *source) listURL(page int64, scope server.CrawlScope) (string, error) {
+	u, err := url.Parse(s.replacePage(s.cfg.List.URL, page))
+	if err != nil {
+		return "", err
+	}
+
+	if len(s.cfg.List.ScopeQuery) == 0 {
+		return u.String(), nil
+	}
+
+	q := u.Query()
+	if name := s.cfg.List.ScopeQuery["city"]; name != "" {
+		for _, city := range scope.Cities {
+			q.Add(name, city)
+		}
+	}
+	if name := s.cfg.List.ScopeQuery["ad_type"]; name != "" && scope.AdType != nil {
+		for v, t := range s.cfg.AdTypes {
+			if adTypes[t] == *scope.AdType {
+				q.Set(name, v)
+			}
+		}
+	}
+	if name := s.cfg.List.ScopeQuery["min_price"]; name != "" && scope.MinPrice != nil {
+		q.Set(name, strconv.FormatFloat(*scope.MinPrice, 'f', -1, 64))
+	}
+	if name := s.cfg.List.ScopeQuery["max_price"]; name != "" && scope.MaxPrice != nil {
+		q.Set(name, strconv.FormatFloat(*scope.MaxPrice, 'f', -1, 64))
+	}
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
+
+// replacePage replaces {page}, {offset} and {limit} by the paging of the config.
+func (s *source) replacePage(template string, page int64) string {
+	l := s.cfg.List
+
+	number := *l.FirstPage + page - 1
+	if l.Paging == offsetPaging {
+		number = (page - 1) * l.PageSize
+	}
+
+	return strings.NewReplacer(
+		"{page}", strconv.FormatInt(number, 10),
+		"{offset}", strconv.FormatInt((page-1)*l.PageSize, 10),
+		"{limit}", strconv.FormatInt(l.PageSize, 10),
+	).Replace(template)
+}
+
+func (s *source) request(ctx context.Context, method, url, body string) (any, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, method, url, bytes.NewReader([]byte(body)))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range s.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil, errNotFound
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status code: %d", res.StatusCode)
+	}
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// toID parses the numeric id, the other ids are hashed.
+func toID(v string) (int64, bool) {
+	if v == "" {
+		return 0, false
+	}
+
+	if id, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return id, true
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(v)) // nolint: errcheck
+	return int64(h.Sum64() & math.MaxInt64), true
+}
+
+func prepareTitle(title string) string {
+	return cases.Title(language.Und).String(strings.ToLower(title))
+}
@@ -0,0 +1,231 @@
+package generic
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	apartmentsvc "github.com/irbgeo/apartment-bot/internal/apartment"
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+func TestLookup(t *testing.T) {
+	doc := map[string]any{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"data": {"items": [{"id": 1, "tags": ["a", "b"]}, {"id": "x", "tags": []}]},
+		"matrix": [[1, 2], [3, 4]]
+	}`), &doc))
+
+	testCases := []struct {
+		path     string
+		expected any
+		isFound  bool
+	}{
+		{path: "data.items[0].id", expected: 1.0, isFound: true},
+		{path: "$.data.items[1].id", expected: "x", isFound: true},
+		{path: "data.items[*].id", expected: []any{1.0, "x"}, isFound: true},
+		{path: "data.items[0].tags[1]", expected: "b", isFound: true},
+		{path: "matrix[1][0]", expected: 3.0, isFound: true},
+		{path: "data.items[2].id"},
+		{path: "data.missing"},
+		{path: "data.items.id"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			v, ok := lookup(doc, tc.path)
+			require.Equal(t, tc.isFound, ok)
+			require.Equal(t, tc.expected, v)
+		})
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	testCases := []struct {
+		testCaseName string
+		config       string
+		isValid      bool
+	}{
+		{
+			testCaseName: "minimal",
+			config:       "name: a\nlist: {url: 'https://a.ge', items: items, id: id, order_date: date}\ndefault_ad_type: rent",
+			isValid:      true,
+		},
+		{
+			testCaseName: "without order date",
+			config:       "name: a\nlist: {url: 'https://a.ge', items: items, id: id}",
+		},
+		{
+			testCaseName: "without items",
+			config:       "name: a\nlist: {url: 'https://a.ge', id: id}",
+		},
+		{
+			testCaseName: "unknown paging",
+			config:       "name: a\nlist: {url: 'https://a.ge', items: items, id: id, paging: cursor}",
+		},
+		{
+			testCaseName: "unknown ad type",
+			config:       "name: a\nlist: {url: 'https://a.ge', items: items, id: id}\nad_types: {x: lease}",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCaseName, func(t *testing.T) {
+			cfg, err := ParseConfig([]byte(tc.config))
+			if !tc.isValid {
+				require.ErrorIs(t, err, ErrInvalidConfig)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, pagePaging, cfg.List.Paging)
+			require.Equal(t, int64(1), *cfg.List.FirstPage)
+			require.Equal(t, defaultPageSize, cfg.List.PageSize)
+			require.Equal(t, defaultMaxAge, cfg.MaxAge)
+		})
+	}
+}
+
+func TestSource(t *testing.T) {
+	updatedAt := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	items := map[string]string{
+		"a-1": `{"id": "a-1", "updated_at": "` + updatedAt.Format(time.RFC3339) + `", "active": true,
+			"price": {"usd": 800}, "rooms": "3", "bedrooms": 2, "floor": 5, "area": 70.5,
+			"contacts": [{"phone": "555"}], "address": {"city": "TBILISI", "district": "vake"},
+			"location": [41.7, 44.77], "description": "flat", "images": [{"url": "1.jpg"}, {"url": "2.jpg"}],
+			"deal": "lease", "condition": "newly_built", "seller": "owner"}`,
+		"2": `{"id": 2, "updated_at": ` + "1700000000" + `, "deal": "buy", "address": {"city": "batumi"}}`,
+		"3": `{"id": 3, "updated_at": "` + updatedAt.Format(time.RFC3339) + `", "deal": "exchange"}`,
+		"4": `{"id": 4, "updated_at": "` + updatedAt.Format(time.RFC3339) + `", "deal": "buy", "active": false}`,
+	}
+
+	var listQueries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "key", r.Header.Get("X-Api-Key"))
+
+		switch {
+		case r.URL.Path == "/search":
+			listQueries = append(listQueries, r.URL.RawQuery)
+			if r.URL.Query().Get("page") != "0" {
+				_, _ = rw.Write([]byte(`{"data": {"items": []}}`))
+				return
+			}
+			_, _ = rw.Write([]byte(`{"data": {"items": [` + items["a-1"] + `,` + items["2"] + `,` + items["3"] + `,` + items["4"] + `]}}`))
+		case strings.HasPrefix(r.URL.Path, "/items/"):
+			item, ok := items[strings.TrimPrefix(r.URL.Path, "/items/")]
+			if !ok {
+				rw.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = rw.Write([]byte(`{"item": ` + item + `}`))
+		}
+	}))
+	defer srv.Close()
+
+	data, err := os.ReadFile("testdata/example.yaml")
+	require.NoError(t, err)
+	cfg, err := ParseConfig([]byte(strings.ReplaceAll(string(data), "https://api.example.com", srv.URL)))
+	require.NoError(t, err)
+
+	s := New(cfg)
+	require.Equal(t, "example", s.Name())
+
+	maxPrice := 1000.0
+	listings, err := s.Listings(context.Background(), 1, server.CrawlScope{Cities: []string{"Tbilisi"}, MaxPrice: &maxPrice})
+	require.NoError(t, err)
+	require.Len(t, listings, 4)
+	require.Equal(t, updatedAt, listings[0].OrderDate)
+	require.Equal(t, apartmentsvc.Listing{ID: 2, OrderDate: time.Unix(1700000000, 0).UTC()}, listings[1])
+
+	empty, err := s.Listings(context.Background(), 2, server.CrawlScope{})
+	require.NoError(t, err)
+	require.Empty(t, empty)
+	require.Equal(t, []string{"city=Tbilisi&page=0&price_to=1000&size=2", "page=1&size=2"}, listQueries)
+
+	// the old, unknown deal type and inactive listings are skipped
+	apartments := s.Apartments(context.Background(), listings)
+	require.Len(t, apartments, 1)
+	require.Equal(t, server.Apartment{
+		ID:             listings[0].ID,
+		AdType:         server.RentAdType,
+		BuildingStatus: server.NewBuildingStatus,
+		Price:          800,
+		Rooms:          3,
+		Bedrooms:       2,
+		Floor:          5,
+		Area:           70.5,
+		Phone:          "555",
+		District:       "Vake",
+		City:           "Tbilisi",
+		Coordinates:    &server.Coordinates{Lat: 41.7, Lng: 44.77},
+		Comment:        "flat",
+		OrderDate:      updatedAt,
+		URL:            "https://example.com/listing/a-1",
+		PhotoURLs:      []string{"1.jpg", "2.jpg"},
+		IsOwner:        true,
+		OriginalPrice:  800,
+		Currency:       server.USD,
+	}, apartments[0])
+	require.True(t, s.InCache(apartments[0].ID))
+	require.Empty(t, s.items)
+
+	// the items of the cached listings aren't kept
+	_, err = s.Listings(context.Background(), 1, server.CrawlScope{})
+	require.NoError(t, err)
+	require.NotContains(t, s.items, apartments[0].ID)
+	require.Len(t, s.items, 3)
+
+	reason, err := s.Check(context.Background(), apartments[0])
+	require.NoError(t, err)
+	require.Equal(t, server.NotRemoved, reason)
+
+	delete(items, "a-1")
+	reason, err = s.Check(context.Background(), apartments[0])
+	require.NoError(t, err)
+	require.Equal(t, server.NotFoundRemoval, reason)
+
+	s.DeleteFromCache(apartments[0])
+	_, ok := s.rawIDs.Load(apartments[0].ID)
+	require.False(t, ok)
+
+	require.NoError(t, s.Health(context.Background()))
+}
+
+func TestCheckWithoutDetail(t *testing.T) {
+	s := New(Config{MaxAge: time.Hour})
+
+	testCases := []struct {
+		testCaseName   string
+		orderDate      time.Time
+		expectedReason server.RemovalReason
+	}{
+		{
+			testCaseName:   "fresh",
+			orderDate:      time.Now().Add(-time.Minute),
+			expectedReason: server.NotRemoved,
+		},
+		{
+			testCaseName:   "expired",
+			orderDate:      time.Now().Add(-2 * time.Hour),
+			expectedReason: server.ExpiredRemoval,
+		},
+		{
+			testCaseName:   "no date",
+			expectedReason: server.NotRemoved,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCaseName, func(t *testing.T) {
+			reason, err := s.Check(context.Background(), server.Apartment{ID: 1, OrderDate: tc.orderDate})
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedReason, reason)
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package generic
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lookup returns the value by the path like data.items[0].price, [*] collects the values of all elements.
+func lookup(doc any, path string) (any, bool) {
+	return walk(doc, splitPath(path))
+}
+
+// splitPath splits a.b[0][*] into a, b, [0], [*].
+func splitPath(path string) []string {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+
+	var tokens []string
+	for _, part := range strings.Split(path, ".") {
+		for part != "" {
+			i := strings.IndexByte(part, '[')
+			if i < 0 {
+				tokens = append(tokens, part)
+				break
+			}
+			if i > 0 {
+				tokens = append(tokens, part[:i])
+			}
+
+			j := strings.IndexByte(part, ']')
+			if j < i {
+				tokens = append(tokens, part[i:])
+				break
+			}
+			tokens = append(tokens, part[i:j+1])
+			part = part[j+1:]
+		}
+	}
+	return tokens
+}
+
+func walk(v any, tokens []string) (any, bool) {
+	if len(tokens) == 0 {
+		return v, v != nil
+	}
+
+	token, rest := tokens[0], tokens[1:]
+	if !strings.HasPrefix(token, "[") {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		next, ok := obj[token]
+		if !ok {
+			return nil, false
+		}
+		return walk(next, rest)
+	}
+
+	arr, ok := v.([]any)
+	if !ok {
+		return nil, false
+	}
+
+	index := strings.TrimSuffix(strings.TrimPrefix(token, "["), "]")
+	if index == "*" {
+		result := make([]any, 0, len(arr))
+		for _, e := range arr {
+			if r, ok := walk(e, rest); ok {
+				result = append(result, r)
+			}
+		}
+		return result, true
+	}
+
+	i, err := strconv.Atoi(index)
+	if err != nil || i < 0 || i >= len(arr) {
+		return nil, false
+	}
+	return walk(arr[i], rest)
+}
+
+func lookupString(doc any, path string) string {
+	if path == "" {
+		return ""
+	}
+
+	v, ok := lookup(doc, path)
+	if !ok {
+		return ""
+	}
+	return formatValue(v)
+}
+
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+func lookupFloat(doc any, path string) float64 {
+	f, _ := strconv.ParseFloat(lookupString(doc, path), 64)
+	return f
+}
+
+func lookupBool(doc any, path string) (bool, bool) {
+	b, err := strconv.ParseBool(lookupString(doc, path))
+	return b, err == nil
+}
+
+func lookupStrings(doc any, path string) []string {
+	if path == "" {
+		return nil
+	}
+
+	v, ok := lookup(doc, path)
+	if !ok {
+		return nil
+	}
+
+	arr, ok := v.([]any)
+	if !ok {
+		arr = []any{v}
+	}
+
+	result := make([]string, 0, len(arr))
+	for _, e := range arr {
+		if s := formatValue(e); s != "" {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// lookupTime parses RFC 3339 or the unix seconds.
+func lookupTime(doc any, path string) time.Time {
+	s := lookupString(doc, path)
+	if s == "" {
+		return time.Time{}
+	}
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t
+	}
+
+	if sec, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(sec, 0).UTC()
+	}
+	return time.Time{}
+}
@@ -0,0 +1,81 @@
+package generic
+
+import "time"
+
+// Config maps the JSON API of the source to the apartments, it's read from YAML.
+type Config struct {
+	Name    string            `yaml:"name"`
+	Headers map[string]string `yaml:"headers"`
+	// MaxAge skips the older listings, 7 days by default
+	MaxAge time.Duration `yaml:"max_age"`
+
+	List   ListConfig   `yaml:"list"`
+	Detail DetailConfig `yaml:"detail"`
+	Fields Fields       `yaml:"fields"`
+
+	// AdTypes maps the values of the ad type field to rent or sale
+	AdTypes       map[string]string `yaml:"ad_types"`
+	DefaultAdType string            `yaml:"default_ad_type"`
+	// BuildingStatuses maps the values of the building status field to new, under_construction or old
+	BuildingStatuses map[string]string `yaml:"building_statuses"`
+	// Currencies maps the values of the currency field to USD, GEL or EUR, the price is in USD by default
+	Currencies      map[string]string `yaml:"currencies"`
+	DefaultCurrency string            `yaml:"default_currency"`
+}
+
+// ListConfig is the search request, {page}, {offset} and {limit} are replaced in the URL and the body.
+// The items must be sorted from the newest.
+type ListConfig struct {
+	URL    string `yaml:"url"`
+	Method string `yaml:"method"`
+	Body   string `yaml:"body"`
+	// Paging is page or offset, FirstPage is the number of the first page, 1 by default
+	Paging    string `yaml:"paging"`
+	FirstPage *int64 `yaml:"first_page"`
+	PageSize  int64  `yaml:"page_size"`
+	// Items is the path to the array of the listings
+	Items string `yaml:"items"`
+	ID    string `yaml:"id"`
+	// OrderDate is the path to the date the items are sorted by, the crawl cursor is moved by it
+	OrderDate string `yaml:"order_date"`
+	// ScopeQuery adds the crawl scope to the URL query by the names of the parameters:
+	// city, ad_type, min_price and max_price
+	ScopeQuery map[string]string `yaml:"scope_query"`
+}
+
+// DetailConfig is the request of the listing details, {id} is replaced in the URL,
+// the fields are read from the list item when the URL is empty and the listings are checked by the max age only.
+type DetailConfig struct {
+	URL    string `yaml:"url"`
+	Method string `yaml:"method"`
+	// Root is the path to the listing in the response
+	Root string `yaml:"root"`
+}
+
+// Fields are the paths to the values of the apartment, e.g. data.price.usd or images[*].url.
+type Fields struct {
+	Price          string `yaml:"price"`
+	Currency       string `yaml:"currency"`
+	Rooms          string `yaml:"rooms"`
+	Bedrooms       string `yaml:"bedrooms"`
+	Floor          string `yaml:"floor"`
+	Area           string `yaml:"area"`
+	Phone          string `yaml:"phone"`
+	City           string `yaml:"city"`
+	District       string `yaml:"district"`
+	Lat            string `yaml:"lat"`
+	Lng            string `yaml:"lng"`
+	Comment        string `yaml:"comment"`
+	OrderDate      string `yaml:"order_date"`
+	Photos         string `yaml:"photos"`
+	AdType         string `yaml:"ad_type"`
+	BuildingStatus string `yaml:"building_status"`
+	// URL is the path to the link of the listing, URLTemplate with {id} is used when it's empty
+	URL         string `yaml:"url"`
+	URLTemplate string `yaml:"url_template"`
+	// IsOwner is the boolean or the value from OwnerValues
+	IsOwner     string   `yaml:"is_owner"`
+	OwnerValues []string `yaml:"owner_values"`
+	// IsActive is the boolean, the listing without the field is active
+	IsActive string `yaml:"is_active"`
+}
@@ -0,0 +1,107 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+// redactedCookie replaces the values of the recorded cookies, the replay doesn't check the tokens.
+const redactedCookie = "redacted"
+
+// Recorder is the transport which stores the responses of the provider in the directory.
+type Recorder struct {
+	dir  string
+	next http.RoundTripper
+	seq  atomic.Int64
+	now  func() time.Time
+}
+
+// NewRecorder creates the recorder, the requests are sent by next or http.DefaultTransport.
+func NewRecorder(dir string, next http.RoundTripper) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &Recorder{
+		dir:  dir,
+		next: next,
+		now:  time.Now,
+	}, nil
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var requestBody []byte
+	if req.Body != nil {
+		var err error
+		requestBody, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(requestBody))
+	}
+
+	res, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	rec := recording{
+		Method:      req.Method,
+		URL:         req.URL.String(),
+		RequestBody: string(requestBody),
+		StatusCode:  res.StatusCode,
+		Header:      redactCookies(res.Header),
+		Body:        string(body),
+		RecordedAt:  r.now().UTC(),
+	}
+	if err := r.save(rec); err != nil {
+		return nil, fmt.Errorf("save recording: %w", err)
+	}
+
+	return res, nil
+}
+
+func (r *Recorder) save(rec recording) error {
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%06d.json", rec.RecordedAt.Format("20060102T150405"), r.seq.Add(1))
+	return os.WriteFile(filepath.Join(r.dir, name), data, 0o600)
+}
+
+func redactCookies(h http.Header) http.Header {
+	h = h.Clone()
+
+	cookies := h.Values("Set-Cookie")
+	h.Del("Set-Cookie")
+	for _, c := range cookies {
+		cookie, err := http.ParseSetCookie(c)
+		if err != nil {
+			continue
+		}
+		cookie.Value = redactedCookie
+		h.Add("Set-Cookie", cookie.String())
+	}
+	return h
+}
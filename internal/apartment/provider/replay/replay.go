@@ -0,0 +1,135 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Transport serves the recordings of the Recorder instead of the provider,
+// the request gets the latest response recorded before the replay time.
+type Transport struct {
+	recordings map[string][]recording
+	speed      float64
+	now        func() time.Time
+
+	mu        sync.Mutex
+	startedAt time.Time
+	// replayedFrom is the replay time at startedAt
+	replayedFrom time.Time
+}
+
+// NewTransport loads the recordings, the replay time starts at the first recording.
+func NewTransport(cfg Config) (*Transport, error) {
+	files, err := filepath.Glob(filepath.Join(cfg.Dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no recordings in %s", cfg.Dir)
+	}
+
+	t := &Transport{
+		recordings: make(map[string][]recording),
+		speed:      cfg.Speed,
+		now:        time.Now,
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		rec := recording{}
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+
+		t.recordings[rec.key()] = append(t.recordings[rec.key()], rec)
+		if t.replayedFrom.IsZero() || rec.RecordedAt.Before(t.replayedFrom) {
+			t.replayedFrom = rec.RecordedAt
+		}
+	}
+
+	for _, recs := range t.recordings {
+		sort.SliceStable(recs, func(i, j int) bool {
+			return recs[i].RecordedAt.Before(recs[j].RecordedAt)
+		})
+	}
+
+	t.startedAt = t.now()
+	return t, nil
+}
+
+// Seek moves the replay time.
+func (t *Transport) Seek(replayTime time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.startedAt = t.now()
+	t.replayedFrom = replayTime
+}
+
+// Now returns the replay time.
+func (t *Transport) Now() time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	elapsed := t.now().Sub(t.startedAt)
+	return t.replayedFrom.Add(time.Duration(float64(elapsed) * t.speed))
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rec, ok := t.recording(requestKey(req.Method, req.URL.String(), string(body)))
+	if !ok {
+		return &http.Response{
+			StatusCode: http.StatusNotFound,
+			Status:     http.StatusText(http.StatusNotFound),
+			Header:     make(http.Header),
+			Body:       io.NopCloser(bytes.NewReader(nil)),
+			Request:    req,
+		}, nil
+	}
+
+	return &http.Response{
+		StatusCode:    rec.StatusCode,
+		Status:        http.StatusText(rec.StatusCode),
+		Header:        rec.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader([]byte(rec.Body))),
+		ContentLength: int64(len(rec.Body)),
+		Request:       req,
+	}, nil
+}
+
+// recording returns the latest recording before the replay time,
+// the first one is returned when the request was recorded later.
+func (t *Transport) recording(key string) (recording, bool) {
+	recs, ok := t.recordings[key]
+	if !ok {
+		return recording{}, false
+	}
+
+	now := t.Now()
+	i := sort.Search(len(recs), func(i int) bool {
+		return recs[i].RecordedAt.After(now)
+	})
+	return recs[max(i-1, 0)], true
+}
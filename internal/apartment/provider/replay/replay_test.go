@@ -0,0 +1,179 @@
+package replay
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/irbgeo/apartment-bot/internal/apartment"
+	"github.com/irbgeo/apartment-bot/internal/apartment/provider/ssge"
+	"github.com/irbgeo/apartment-bot/internal/estimate"
+	"github.com/irbgeo/apartment-bot/internal/filter"
+	"github.com/irbgeo/apartment-bot/internal/gazetteer"
+	"github.com/irbgeo/apartment-bot/internal/score"
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+type fakeCursorStorage struct{}
+
+func (fakeCursorStorage) CrawlCursor(_ context.Context, provider string) (server.CrawlCursor, error) {
+	return server.CrawlCursor{Provider: provider}, nil
+}
+
+func (fakeCursorStorage) SaveCrawlCursor(context.Context, server.CrawlCursor) error {
+	return nil
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	responses := []string{"first", "second"}
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		http.SetCookie(rw, &http.Cookie{Name: "ss-session-token", Value: "secret"})
+		_, _ = rw.Write([]byte(responses[0]))
+		responses = responses[1:]
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	recorder, err := NewRecorder(dir, nil)
+	require.NoError(t, err)
+
+	recordedAt := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	recorder.now = func() time.Time { return recordedAt }
+
+	client := &http.Client{Transport: recorder}
+	send := func(c *http.Client) string {
+		res, err := c.Post(srv.URL+"/list", "application/json", strings.NewReader(`{"page":1}`))
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return string(body)
+	}
+
+	require.Equal(t, "first", send(client))
+	recordedAt = recordedAt.Add(time.Hour)
+	require.Equal(t, "second", send(client))
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	data, err := os.ReadFile(filepath.Join(dir, files[0].Name()))
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "secret")
+
+	transport, err := NewTransport(Config{Dir: dir})
+	require.NoError(t, err)
+	client = &http.Client{Transport: transport}
+
+	// the replay time starts at the first recording and is moved by Seek only
+	require.Equal(t, time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC), transport.Now())
+	require.Equal(t, "first", send(client))
+
+	transport.Seek(time.Date(2026, 10, 1, 9, 59, 0, 0, time.UTC))
+	require.Equal(t, "first", send(client))
+
+	transport.Seek(time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC))
+	require.Equal(t, "second", send(client))
+
+	// the request which wasn't recorded
+	res, err := client.Get(srv.URL + "/unknown")
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	require.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestReplayTransportSpeed(t *testing.T) {
+	transport, err := NewTransport(Config{Dir: "testdata/day", Speed: 60})
+	require.NoError(t, err)
+
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	transport.now = func() time.Time { return now }
+	transport.Seek(time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC))
+
+	// the minute of the wall time is the hour of the replay
+	now = now.Add(time.Minute)
+	require.Equal(t, time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC), transport.Now())
+}
+
+// TestReplayDay replays the recorded day through the ssge provider, the apartment service
+// and the server, the apartments are delivered to the users of the matched filters.
+func TestReplayDay(t *testing.T) {
+	transport, err := NewTransport(Config{Dir: "testdata/day"})
+	require.NoError(t, err)
+
+	provider := ssge.NewSSGEProvider(ssge.Config{
+		Name:      "replay",
+		Transport: transport,
+		Now:       transport.Now,
+	})
+	require.NoError(t, provider.Start(time.Hour))
+	defer provider.Stop()
+	require.Equal(t, "replay", provider.Name())
+
+	// the filter without the price keeps the crawl scope unrestricted, the recorded requests are matched
+	cheap, expensive := 800.0, 800.0
+	st := newMemoryStorage(
+		server.Filter{ID: "cheap", Name: ptr("cheap"), User: &server.User{ID: 1}, MaxPrice: &cheap},
+		server.Filter{ID: "expensive", Name: ptr("expensive"), User: &server.User{ID: 2}, MinPrice: &expensive},
+		server.Filter{ID: "rooms", Name: ptr("rooms"), User: &server.User{ID: 3}, MinRooms: ptr(4.0)},
+	)
+
+	f, err := filter.New(st, score.New(st), fakeRates{})
+	require.NoError(t, err)
+
+	places, err := gazetteer.New("")
+	require.NoError(t, err)
+
+	svc := apartment.NewService(apartment.Config{MaxFetchPages: 5}, provider, fakeCursorStorage{})
+	require.NoError(t, svc.Start(10*time.Millisecond))
+	defer svc.Stop()
+
+	srv := server.NewService(svc, st, f, estimate.New(st), fakeRates{}, places, server.DefaultPlans)
+	apartmentCh := srv.Subscribe(context.Background())
+	require.NoError(t, srv.Start())
+	defer srv.Stop()
+
+	receive := func() server.Apartment {
+		select {
+		case a := <-apartmentCh:
+			return a
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "the apartment isn't delivered")
+		}
+		return server.Apartment{}
+	}
+
+	a := receive()
+	require.Equal(t, int64(1), a.ID)
+	require.Equal(t, "Tbilisi", a.City)
+	require.Equal(t, "Vake", a.District)
+	require.Equal(t, 700.0, a.Price)
+	require.Equal(t, map[int64][]string{1: {"cheap"}}, a.Filter)
+
+	// the known apartment is in the cache, the new one is replayed at its time
+	transport.Seek(time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC))
+	a = receive()
+	require.Equal(t, int64(2), a.ID)
+	require.Equal(t, 900.0, a.Price)
+	require.Equal(t, map[int64][]string{2: {"expensive"}}, a.Filter)
+
+	stored, err := st.Apartment(context.Background(), 2)
+	require.NoError(t, err)
+	require.Equal(t, "Vake", stored.District)
+
+	// the listings expire by the replay time
+	transport.Seek(time.Date(2026, 10, 9, 12, 0, 0, 0, time.UTC))
+	reason, err := provider.Check(context.Background(), a)
+	require.NoError(t, err)
+	require.Equal(t, server.ExpiredRemoval, reason)
+}
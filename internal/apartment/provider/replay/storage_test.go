@@ -0,0 +1,194 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+// memoryStorage keeps the apartments and the filters of the replay in memory,
+// the other data of the server isn't stored.
+type memoryStorage struct {
+	mu         sync.RWMutex
+	apartments map[int64]server.Apartment
+	filters    []server.Filter
+}
+
+func newMemoryStorage(filters ...server.Filter) *memoryStorage {
+	return &memoryStorage{
+		apartments: make(map[int64]server.Apartment),
+		filters:    filters,
+	}
+}
+
+func (s *memoryStorage) SaveApartment(_ context.Context, a server.Apartment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.apartments[a.ID]; ok {
+		return errors.New("apartment exists")
+	}
+	s.apartments[a.ID] = a
+	return nil
+}
+
+func (s *memoryStorage) UpdateApartment(_ context.Context, a server.Apartment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.apartments[a.ID] = a
+	return nil
+}
+
+func (s *memoryStorage) Apartment(_ context.Context, id int64) (server.Apartment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	a, ok := s.apartments[id]
+	if !ok {
+		return server.Apartment{}, errors.New("apartment not found")
+	}
+	return a, nil
+}
+
+func (s *memoryStorage) Apartments(context.Context, server.Filter) (<-chan server.Apartment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	resultCh := make(chan server.Apartment, len(s.apartments))
+	for _, a := range s.apartments {
+		resultCh <- a
+	}
+	close(resultCh)
+	return resultCh, nil
+}
+
+func (s *memoryStorage) ApartmentCount(context.Context, server.Filter) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return int64(len(s.apartments)), nil
+}
+
+func (s *memoryStorage) ApartmentCountByHost(context.Context) (map[string]int64, error) {
+	return nil, nil
+}
+
+func (s *memoryStorage) SearchApartments(context.Context, server.ApartmentSearch) ([]server.Apartment, error) {
+	return nil, nil
+}
+
+func (s *memoryStorage) SearchApartmentCount(context.Context, server.ApartmentSearch) (int64, error) {
+	return 0, nil
+}
+
+func (s *memoryStorage) ApartmentSortKey(context.Context, server.ApartmentSearch, int64) (float64, error) {
+	return 0, server.ErrNotFound
+}
+
+func (s *memoryStorage) ArchiveApartment(_ context.Context, a server.Apartment, _ server.RemovalReason) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.apartments, a.ID)
+	return nil
+}
+
+func (s *memoryStorage) ArchiveApartments(context.Context, server.RemovalReason) error {
+	return nil
+}
+
+func (s *memoryStorage) ArchivedApartments(context.Context, server.ArchiveFilter) (<-chan server.ArchivedApartment, error) {
+	resultCh := make(chan server.ArchivedApartment)
+	close(resultCh)
+	return resultCh, nil
+}
+
+func (s *memoryStorage) InsertUser(context.Context, server.User) error {
+	return nil
+}
+
+func (s *memoryStorage) User(context.Context, server.Filter) (server.User, error) {
+	return server.User{}, errors.New("user not found")
+}
+
+func (s *memoryStorage) Users(context.Context) ([]server.User, error) {
+	return nil, nil
+}
+
+func (s *memoryStorage) DeleteUser(context.Context, server.User) error {
+	return nil
+}
+
+func (s *memoryStorage) SaveCity(context.Context, server.City) error {
+	return nil
+}
+
+func (s *memoryStorage) Cities(context.Context) ([]server.City, error) {
+	return nil, nil
+}
+
+func (s *memoryStorage) Stats(context.Context, server.StatsFilter) ([]server.Stats, error) {
+	return nil, nil
+}
+
+func (s *memoryStorage) SaveApartmentMark(context.Context, server.ApartmentMark) error {
+	return nil
+}
+
+func (s *memoryStorage) ApartmentMarks(context.Context, server.ApartmentMarkFilter) ([]server.ApartmentMark, error) {
+	return nil, nil
+}
+
+func (s *memoryStorage) SaveDelivery(context.Context, server.Delivery) error {
+	return nil
+}
+
+func (s *memoryStorage) Deliveries(context.Context, server.DeliveryFilter) ([]server.Delivery, error) {
+	return nil, nil
+}
+
+func (s *memoryStorage) DeleteDeliveries(context.Context, server.DeliveryFilter) error {
+	return nil
+}
+
+func (s *memoryStorage) SavePendingDelivery(context.Context, server.PendingDelivery) error {
+	return nil
+}
+
+func (s *memoryStorage) PendingDeliveries(context.Context, server.PendingDeliveryFilter) ([]server.PendingDelivery, error) {
+	return nil, nil
+}
+
+func (s *memoryStorage) DeletePendingDeliveries(context.Context, server.PendingDeliveryFilter) error {
+	return nil
+}
+
+func (s *memoryStorage) SaveFilter(_ context.Context, f server.Filter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.filters = append(s.filters, f)
+	return nil
+}
+
+func (s *memoryStorage) Filters(context.Context, server.Filter) ([]server.Filter, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.filters, nil
+}
+
+func (s *memoryStorage) DeleteFilter(context.Context, server.Filter) error {
+	return nil
+}
+
+type fakeRates struct{}
+
+func (fakeRates) Rates() server.Rates {
+	return nil
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,33 @@
+package replay
+
+import (
+	"net/http"
+	"time"
+)
+
+// Config contains configuration for creating a new replay transport
+type Config struct {
+	// Dir contains the recordings of the Recorder
+	Dir string
+	// Speed is the factor of the replay time, the replay time is moved by Seek only when it's zero
+	Speed float64
+}
+
+// recording is the HTTP exchange with the provider stored in the file.
+type recording struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	RequestBody string      `json:"request_body,omitempty"`
+	StatusCode  int         `json:"status_code"`
+	Header      http.Header `json:"header"`
+	Body        string      `json:"body"`
+	RecordedAt  time.Time   `json:"recorded_at"`
+}
+
+func (r recording) key() string {
+	return requestKey(r.Method, r.URL, r.RequestBody)
+}
+
+func requestKey(method, url, body string) string {
+	return method + " " + url + "\n" + body
+}
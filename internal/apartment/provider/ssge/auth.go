@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"time"
 )
 
 var (
@@ -37,27 +38,24 @@ func (s *ssge) refreshToken() error {
 
 	s.addRefreshHeaders(req)
 
-	s.requestMutex.Lock()
 	res, err := s.client.Do(req)
 	if err != nil {
-		s.requestMutex.Unlock()
 		return err
 	}
-	s.requestMutex.Unlock()
 	defer res.Body.Close()
 
 	if res.StatusCode != http.StatusOK {
 		return fmt.Errorf("status code: %d", res.StatusCode)
 	}
 
-	s.requestMutex.Lock()
-	defer s.requestMutex.Unlock()
-
 	for _, c := range res.Cookies() {
 		if c.Name == cookieTokenName {
+			s.tokenMutex.Lock()
 			s.token = c.Value
+			s.tokenMutex.Unlock()
+			s.setTokenRefreshedAt(time.Now())
 
-			slog.Info("refresh token", "token", s.token)
+			slog.Info("refresh token", "token", c.Value)
 			return nil
 		}
 	}
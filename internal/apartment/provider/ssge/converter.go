@@ -20,6 +20,12 @@ var dealTypeMap = map[int64]int64{
 	saleRealEstateDealType: server.SaleAdType,
 }
 
+// currencyTypeMap is the currency the owner set the price in, it's the currencyId of the search
+var currencyTypeMap = map[int64]server.Currency{
+	1: server.USD,
+	2: server.GEL,
+}
+
 var realEstateStatusIDMap = map[int64]int64{
 	2:   server.NewBuildingStatus,
 	3:   server.UnderConstructionBuildingStatus,
@@ -40,6 +46,11 @@ func toServerApartment(in apartment) server.Apartment {
 		OrderDate:      in.OrderDate,
 	}
 
+	out.Currency, out.OriginalPrice = server.USD, in.Price.PriceUSD
+	if currencyTypeMap[in.Price.CurrencyType] == server.GEL && in.Price.PriceGeo > 0 {
+		out.Currency, out.OriginalPrice = server.GEL, in.Price.PriceGeo
+	}
+
 	out.Rooms, _ = strconv.ParseFloat(in.Rooms, 64)
 	out.Area, _ = strconv.ParseFloat(in.TotalArea, 64)
 	out.Floor, _ = strconv.ParseInt(in.Floor, 10, 64)
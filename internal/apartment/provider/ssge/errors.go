@@ -0,0 +1,8 @@
+package ssge
+
+import "errors"
+
+var (
+	// errNotFound is the response 404, the listing is deleted from the provider
+	errNotFound = errors.New("not found")
+)
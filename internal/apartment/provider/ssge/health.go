@@ -0,0 +1,37 @@
+package ssge
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+var (
+	tokenMaxAgeFactor time.Duration = 2
+	maxPageFetchAge                 = 10 * time.Minute
+)
+
+// Health reports whether the access token is fresh and pages are fetched successfully.
+func (s *ssge) Health(_ context.Context) error {
+	s.tokenMutex.RLock()
+	tokenAge := time.Since(s.tokenRefreshedAt)
+	s.tokenMutex.RUnlock()
+
+	if tokenAge > s.tokenMaxAge {
+		return fmt.Errorf("token was refreshed %s ago", tokenAge.Round(time.Second))
+	}
+
+	fetchAge := time.Since(time.Unix(0, s.pageFetchedAt.Load()))
+	if fetchAge > maxPageFetchAge {
+		return fmt.Errorf("page was fetched %s ago", fetchAge.Round(time.Second))
+	}
+
+	return nil
+}
+
+func (s *ssge) setTokenRefreshedAt(t time.Time) {
+	s.tokenMutex.Lock()
+	defer s.tokenMutex.Unlock()
+
+	s.tokenRefreshedAt = t
+}
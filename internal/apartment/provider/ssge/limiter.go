@@ -0,0 +1,115 @@
+package ssge
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// limiter is the token bucket, the bucket is refilled with rate tokens per second up to burst tokens.
+type limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newLimiter(rate float64, burst int) *limiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &limiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+	}
+}
+
+// reserve takes the token and returns the delay after which the token is available.
+func (l *limiter) reserve(now time.Time) time.Duration {
+	if l.rate <= 0 {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// limiter returns the limiter of the host, the limiters are created on the first request.
+func (s *ssge) limiter(host string) *limiter {
+	s.limitersMutex.Lock()
+	defer s.limitersMutex.Unlock()
+
+	l, ok := s.limiters[host]
+	if !ok {
+		l = newLimiter(s.rateLimit, s.rateBurst)
+		s.limiters[host] = l
+	}
+	return l
+}
+
+// retryDelay returns the delay before the next attempt, Retry-After of the response is preferred.
+func retryDelay(res *http.Response, attempt int) time.Duration {
+	if res != nil {
+		if d, ok := parseRetryAfter(res.Header.Get("Retry-After")); ok {
+			return min(d, maxRetryDelay)
+		}
+	}
+
+	return min(retryBaseDelay<<attempt, maxRetryDelay)
+}
+
+// parseRetryAfter parses the header in seconds or in HTTP date.
+func parseRetryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0), true
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0), true
+	}
+
+	return 0, false
+}
+
+func isRetryable(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
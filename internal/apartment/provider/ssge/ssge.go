@@ -4,14 +4,20 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+
+	apartmentsvc "github.com/irbgeo/apartment-bot/internal/apartment"
 	"github.com/irbgeo/apartment-bot/internal/server"
+	"github.com/irbgeo/apartment-bot/internal/tracing"
 )
 
 var (
@@ -24,30 +30,72 @@ var (
 	apartmentListURL      = "https://api-gateway.ss.ge/v1/RealEstate/LegendSearch"
 	authTokenTemplate     = "Bearer %s"
 	apartmentActiveStatus = "active"
+	providerName          = "ssge"
 
 	requestTimeout       = 1 * time.Minute
 	pageSize       int64 = 16
 	apartmentTTL         = 7 * 24 * time.Hour
+
+	retryBaseDelay = 500 * time.Millisecond
+	maxRetryDelay  = 1 * time.Minute
 )
 
 type ssge struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
-	requestMutex sync.Mutex
-	client       *http.Client
+	client *http.Client
+	name   string
+	now    func() time.Time
+
+	workers    int
+	maxRetries int
 
-	tokenMutex sync.RWMutex
-	token      string
+	limitersMutex sync.Mutex
+	limiters      map[string]*limiter
+	rateLimit     float64
+	rateBurst     int
+	// wait sleeps before the limited and retried requests
+	wait func(ctx context.Context, d time.Duration) error
+
+	tokenMutex       sync.RWMutex
+	token            string
+	tokenRefreshedAt time.Time
+	tokenMaxAge      time.Duration
+
+	pageFetchedAt atomic.Int64
 
 	cacheID sync.Map
+
+	// cityIDs are learned from the listings by the title, the search request takes the ids
+	cityMutex sync.RWMutex
+	cityIDs   map[string]int64
+	places    places
 }
 
-func NewSSGEProvider() *ssge {
+func NewSSGEProvider(cfg Config) *ssge {
 	p := &ssge{
 		client: &http.Client{
-			Timeout: 10 * time.Second,
+			Timeout:   10 * time.Second,
+			Transport: cfg.Transport,
 		},
+		name:       providerName,
+		now:        time.Now,
+		workers:    max(cfg.Workers, 1),
+		maxRetries: max(cfg.MaxRetries, 0),
+		limiters:   make(map[string]*limiter),
+		cityIDs:    make(map[string]int64),
+		rateLimit:  cfg.RateLimit,
+		rateBurst:  cfg.RateBurst,
+		wait:       sleep,
+		places:     cfg.Places,
+	}
+
+	if cfg.Name != "" {
+		p.name = cfg.Name
+	}
+	if cfg.Now != nil {
+		p.now = cfg.Now
 	}
 
 	p.ctx, p.cancel = context.WithCancel(context.Background())
@@ -55,6 +103,9 @@ func NewSSGEProvider() *ssge {
 }
 
 func (s *ssge) Start(refreshTokenInterval time.Duration) error {
+	s.tokenMaxAge = tokenMaxAgeFactor * refreshTokenInterval
+	s.pageFetchedAt.Store(time.Now().UnixNano())
+
 	err := s.refreshToken()
 	if err != nil {
 		return err
@@ -85,11 +136,18 @@ func (s *ssge) Stop() {
 	s.cancel()
 }
 
-func (s *ssge) Apartments(ctx context.Context, page int64) ([]server.Apartment, error) {
+// Name returns the name of the provider, it's the key of the crawl cursor.
+func (s *ssge) Name() string {
+	return s.name
+}
+
+// Listings returns the listings of the page in the scope ordered from the newest.
+func (s *ssge) Listings(ctx context.Context, page int64, scope server.CrawlScope) ([]apartmentsvc.Listing, error) {
+	ctx, span := tracing.Start(ctx, "ssge.Listings", attribute.Int64("page", page))
+	defer span.End()
+
 	requestBody := requestApartmentListBody{
-		AdvancedSearch: advancedSearch{
-			WithImageOnly: true,
-		},
+		AdvancedSearch: s.advancedSearch(scope),
 		RealEstateType: 5,
 		CurrencyID:     1,
 		Order:          1,
@@ -101,47 +159,165 @@ func (s *ssge) Apartments(ctx context.Context, page int64) ([]server.Apartment,
 
 	apartmentsData, err := s.request(ctx, http.MethodPost, apartmentListURL, body)
 	if err != nil {
+		tracing.Error(span, err)
 		return nil, err
 	}
 
 	apartments := &apartmentList{}
 	err = json.Unmarshal(apartmentsData, apartments)
 	if err != nil {
+		tracing.Error(span, err)
 		return nil, err
 	}
 
-	result := make([]server.Apartment, 0, pageSize)
-	for _, apartment := range apartments.Data {
-		_, isExist := s.cacheID.Load(apartment.ApplicationID)
-		if isExist {
-			continue
+	s.pageFetchedAt.Store(time.Now().UnixNano())
+
+	s.learnCities(apartments.Data)
+
+	result := make([]apartmentsvc.Listing, 0, len(apartments.Data))
+	for _, a := range apartments.Data {
+		result = append(result, apartmentsvc.Listing{
+			ID:        a.ApplicationID,
+			OrderDate: a.OrderDate,
+		})
+	}
+
+	span.SetAttributes(attribute.Int("listings", len(result)))
+	return result, nil
+}
+
+// advancedSearch pushes the scope to the search request, the cities aren't restricted
+// until the ids of all of them are learned by the broad crawl.
+func (s *ssge) advancedSearch(scope server.CrawlScope) advancedSearch {
+	search := advancedSearch{
+		WithImageOnly: true,
+		PriceFrom:     scope.MinPrice,
+		PriceTo:       scope.MaxPrice,
+	}
+
+	if scope.AdType != nil {
+		for dealType, adType := range dealTypeMap {
+			if adType == *scope.AdType {
+				search.RealEstateDealType = &dealType
+			}
 		}
+	}
 
-		a, err := s.apartment(ctx, apartment.ApplicationID)
-		if err != nil {
-			slog.Error("get apartment", "err", err)
-			continue
+	s.cityMutex.RLock()
+	defer s.cityMutex.RUnlock()
+
+	for _, city := range scope.Cities {
+		id, ok := s.cityIDs[city]
+		if !ok {
+			slog.Debug("unknown city of the crawl scope", "city", city)
+			search.CityIDList = nil
+			break
 		}
+		search.CityIDList = append(search.CityIDList, id)
+	}
+	return search
+}
+
+// learnCities stores the ids of the cities by the titles of the apartments.
+func (s *ssge) learnCities(listings []data) {
+	s.cityMutex.Lock()
+	defer s.cityMutex.Unlock()
 
-		if !check(a) {
-			continue
+	for _, l := range listings {
+		if l.Address.CityID != 0 && l.Address.CityTitle != "" {
+			s.cityIDs[s.city(l.Address.CityTitle)] = l.Address.CityID
 		}
+	}
+}
 
-		s.cacheID.Store(a.ApplicationID, struct{}{})
+// city returns the canonical name of the city title.
+func (s *ssge) city(title string) string {
+	if s.places == nil {
+		return prepareTitle(title)
+	}
+
+	p, _ := s.places.Resolve(prepareTitle(title), "")
+	return p.City
+}
 
-		result = append(result, toServerApartment(*a))
+// Apartments fetches details of the listings by the pool of the workers,
+// the unsuitable listings are skipped, the order of the listings is kept.
+func (s *ssge) Apartments(ctx context.Context, listings []apartmentsvc.Listing) []server.Apartment {
+	type result struct {
+		apartment server.Apartment
+		ok        bool
 	}
 
-	return result, nil
+	results := make([]result, len(listings))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(s.workers, len(listings)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				a, ok := s.newApartment(ctx, listings[i].ID)
+				results[i] = result{apartment: a, ok: ok}
+			}
+		}()
+	}
+
+	for i := range listings {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	apartments := make([]server.Apartment, 0, len(listings))
+	for _, r := range results {
+		if r.ok {
+			apartments = append(apartments, r.apartment)
+		}
+	}
+	return apartments
+}
+
+func (s *ssge) InCache(id int64) bool {
+	_, isExist := s.cacheID.Load(id)
+	return isExist
 }
 
-func (s *ssge) IsAvailable(ctx context.Context, a server.Apartment) (bool, error) {
+// newApartment fetches details of the listing and starts its trace.
+func (s *ssge) newApartment(ctx context.Context, id int64) (server.Apartment, bool) {
+	ctx, span := tracing.Start(ctx, "ssge.apartment", attribute.Int64("apartment.id", id))
+	defer span.End()
+
+	a, err := s.apartment(ctx, id)
+	if err != nil {
+		tracing.Error(span, err)
+		slog.Error("get apartment", "err", err)
+		return server.Apartment{}, false
+	}
+
+	if s.removalReason(a) != server.NotRemoved {
+		return server.Apartment{}, false
+	}
+
+	s.cacheID.Store(a.ApplicationID, struct{}{})
+
+	result := toServerApartment(*a)
+	result.TraceContext = tracing.Inject(ctx)
+
+	return result, true
+}
+
+// Check returns the reason of the removal of the apartment, the deleted listing isn't found.
+func (s *ssge) Check(ctx context.Context, a server.Apartment) (server.RemovalReason, error) {
 	aData, err := s.apartment(ctx, a.ID)
+	if errors.Is(err, errNotFound) {
+		return server.NotFoundRemoval, nil
+	}
 	if err != nil {
-		return true, err
+		return server.NotRemoved, err
 	}
 
-	return check(aData), nil
+	return s.removalReason(aData), nil
 }
 
 func (s *ssge) SetInCache(a server.Apartment) {
@@ -158,42 +334,81 @@ func (s *ssge) apartment(ctx context.Context, id int64) (*apartment, error) {
 		return nil, err
 	}
 
+	// the broken response isn't the inactive apartment, the error keeps it from deleting
 	a := &apartment{}
-	json.Unmarshal(apartmentData, a) // nolint:errcheck
+	if err := json.Unmarshal(apartmentData, a); err != nil {
+		return nil, err
+	}
 
 	return a, nil
 }
 
+// request sends the request limited by the host, 429 and 5xx responses are retried with backoff.
 func (s *ssge) request(ctx context.Context, method, url string, body []byte) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		res, err := s.do(ctx, method, url, body)
+		if err != nil {
+			return nil, err
+		}
+
+		if res.StatusCode == http.StatusOK {
+			defer res.Body.Close()
+			return io.ReadAll(res.Body)
+		}
+
+		res.Body.Close()
+		if res.StatusCode == http.StatusNotFound {
+			return nil, errNotFound
+		}
+
+		err = fmt.Errorf("status code: %d", res.StatusCode)
+		if !isRetryable(res.StatusCode) || attempt >= s.maxRetries {
+			return nil, err
+		}
+
+		delay := retryDelay(res, attempt)
+		slog.Warn("retry request", "url", url, "attempt", attempt+1, "delay", delay, "err", err)
+		if err := s.wait(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (s *ssge) do(ctx context.Context, method, url string, body []byte) (*http.Response, error) {
 	reqCtx, cancel := context.WithTimeout(ctx, requestTimeout)
-	defer cancel()
 
 	req, err := http.NewRequestWithContext(reqCtx, method, url, bytes.NewReader(body))
 	if err != nil {
+		cancel()
 		return nil, err
 	}
 
 	s.addRequestHeaders(req)
 
-	s.requestMutex.Lock()
-	res, err := s.client.Do(req)
-	if err != nil {
-		s.requestMutex.Unlock()
+	if err := s.wait(ctx, s.limiter(req.URL.Host).reserve(time.Now())); err != nil {
+		cancel()
 		return nil, err
 	}
-	s.requestMutex.Unlock()
-	defer res.Body.Close()
 
-	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("status code: %d", res.StatusCode)
-	}
-
-	body, err = io.ReadAll(res.Body)
+	res, err := s.client.Do(req)
 	if err != nil {
+		cancel()
 		return nil, err
 	}
 
-	return body, nil
+	res.Body = &cancelBody{ReadCloser: res.Body, cancel: cancel}
+	return res, nil
+}
+
+// cancelBody cancels the context of the request on close, the body is read after do returns.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
 }
 
 func (s *ssge) addRequestHeaders(req *http.Request) {
@@ -213,7 +428,12 @@ func (s *ssge) addRequestHeaders(req *http.Request) {
 	req.Header.Set("Authorization", fmt.Sprintf(authTokenTemplate, s.token))
 }
 
-func check(a *apartment) bool {
-	_, ok := dealTypeMap[a.RealEstateDealTypeID]
-	return !a.IsInactiveApplication && time.Since(a.OrderDate) < apartmentTTL && ok
+func (s *ssge) removalReason(a *apartment) server.RemovalReason {
+	if _, ok := dealTypeMap[a.RealEstateDealTypeID]; !ok || a.IsInactiveApplication {
+		return server.InactiveRemoval
+	}
+	if s.now().Sub(a.OrderDate) >= apartmentTTL {
+		return server.ExpiredRemoval
+	}
+	return server.NotRemoved
 }
@@ -0,0 +1,302 @@
+package ssge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	apartmentsvc "github.com/irbgeo/apartment-bot/internal/apartment"
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+// newTestProvider creates the provider which records the waits instead of sleeping.
+func newTestProvider(cfg Config) (*ssge, *[]time.Duration) {
+	var (
+		mu    sync.Mutex
+		waits []time.Duration
+	)
+
+	p := NewSSGEProvider(cfg)
+	p.wait = func(ctx context.Context, d time.Duration) error {
+		if d > 0 {
+			mu.Lock()
+			waits = append(waits, d)
+			mu.Unlock()
+		}
+		return ctx.Err()
+	}
+	return p, &waits
+}
+
+func TestRequest(t *testing.T) {
+	testCases := []struct {
+		testCaseName     string
+		responses        []int
+		retryAfter       string
+		maxRetries       int
+		expectedErr      bool
+		expectedRequests int64
+		expectedWaits    []time.Duration
+	}{
+		{
+			testCaseName:     "ok",
+			responses:        []int{http.StatusOK},
+			maxRetries:       3,
+			expectedRequests: 1,
+		},
+		{
+			testCaseName:     "retry on server error with backoff",
+			responses:        []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK},
+			maxRetries:       3,
+			expectedRequests: 3,
+			expectedWaits:    []time.Duration{retryBaseDelay, 2 * retryBaseDelay},
+		},
+		{
+			testCaseName:     "retry after",
+			responses:        []int{http.StatusTooManyRequests, http.StatusOK},
+			retryAfter:       "7",
+			maxRetries:       3,
+			expectedRequests: 2,
+			expectedWaits:    []time.Duration{7 * time.Second},
+		},
+		{
+			testCaseName:     "retries are exhausted",
+			responses:        []int{http.StatusInternalServerError},
+			maxRetries:       2,
+			expectedErr:      true,
+			expectedRequests: 3,
+			expectedWaits:    []time.Duration{retryBaseDelay, 2 * retryBaseDelay},
+		},
+		{
+			testCaseName:     "client error isn't retried",
+			responses:        []int{http.StatusNotFound},
+			maxRetries:       3,
+			expectedErr:      true,
+			expectedRequests: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCaseName, func(t *testing.T) {
+			var requests atomic.Int64
+			srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				i := min(int(requests.Add(1))-1, len(tc.responses)-1)
+				if tc.retryAfter != "" {
+					rw.Header().Set("Retry-After", tc.retryAfter)
+				}
+				rw.WriteHeader(tc.responses[i])
+				_, _ = rw.Write([]byte("body"))
+			}))
+			defer srv.Close()
+
+			p, waits := newTestProvider(Config{MaxRetries: tc.maxRetries})
+			body, err := p.request(context.Background(), http.MethodPost, srv.URL, []byte("{}"))
+
+			if tc.expectedErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, "body", string(body))
+			}
+			require.Equal(t, tc.expectedRequests, requests.Load())
+			require.Equal(t, tc.expectedWaits, *waits)
+		})
+	}
+}
+
+func TestApartments(t *testing.T) {
+	var (
+		inFlight    atomic.Int64
+		maxInFlight atomic.Int64
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		id, _ := strconv.ParseInt(r.URL.Query().Get("applicationId"), 10, 64)
+		a := apartment{
+			ApplicationID:         id,
+			RealEstateDealTypeID:  rentRealEstateDealType,
+			IsInactiveApplication: id%2 == 0,
+			OrderDate:             time.Now(),
+		}
+		require.NoError(t, json.NewEncoder(rw).Encode(a))
+	}))
+	defer srv.Close()
+
+	defaultTemplate := apartmentURLTemplate
+	apartmentURLTemplate = srv.URL + "/details?applicationId=%d"
+	defer func() { apartmentURLTemplate = defaultTemplate }()
+
+	p, waits := newTestProvider(Config{Workers: 3, RateLimit: 1000, RateBurst: 100})
+
+	listings := make([]apartmentsvc.Listing, 0, 9)
+	for id := int64(1); id <= 9; id++ {
+		listings = append(listings, apartmentsvc.Listing{ID: id})
+	}
+
+	apartments := p.Apartments(context.Background(), listings)
+
+	ids := make([]int64, 0, len(apartments))
+	for _, a := range apartments {
+		ids = append(ids, a.ID)
+		require.True(t, p.InCache(a.ID))
+	}
+	require.Equal(t, []int64{1, 3, 5, 7, 9}, ids)
+	require.Greater(t, maxInFlight.Load(), int64(1))
+	require.LessOrEqual(t, maxInFlight.Load(), int64(3))
+	require.Empty(t, *waits)
+}
+
+func TestCheck(t *testing.T) {
+	now := time.Now()
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		a := apartment{RealEstateDealTypeID: rentRealEstateDealType, OrderDate: now}
+
+		switch r.URL.Query().Get("applicationId") {
+		case "2":
+			a.IsInactiveApplication = true
+		case "3":
+			a.OrderDate = now.Add(-apartmentTTL)
+		case "4":
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		case "5":
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		require.NoError(t, json.NewEncoder(rw).Encode(a))
+	}))
+	defer srv.Close()
+
+	defaultTemplate := apartmentURLTemplate
+	apartmentURLTemplate = srv.URL + "/details?applicationId=%d"
+	defer func() { apartmentURLTemplate = defaultTemplate }()
+
+	testCases := []struct {
+		testCaseName   string
+		id             int64
+		expectedReason server.RemovalReason
+		expectedErr    bool
+	}{
+		{testCaseName: "active", id: 1, expectedReason: server.NotRemoved},
+		{testCaseName: "inactive", id: 2, expectedReason: server.InactiveRemoval},
+		{testCaseName: "expired", id: 3, expectedReason: server.ExpiredRemoval},
+		{testCaseName: "not found", id: 4, expectedReason: server.NotFoundRemoval},
+		{testCaseName: "failed request keeps apartment", id: 5, expectedReason: server.NotRemoved, expectedErr: true},
+	}
+
+	p, _ := newTestProvider(Config{})
+	for _, tc := range testCases {
+		t.Run(tc.testCaseName, func(t *testing.T) {
+			reason, err := p.Check(context.Background(), server.Apartment{ID: tc.id})
+			require.Equal(t, tc.expectedErr, err != nil)
+			require.Equal(t, tc.expectedReason, reason)
+		})
+	}
+}
+
+func TestLimiterReserve(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	l := newLimiter(2, 2)
+	require.Equal(t, time.Duration(0), l.reserve(now))
+	require.Equal(t, time.Duration(0), l.reserve(now))
+	require.Equal(t, 500*time.Millisecond, l.reserve(now))
+	require.Equal(t, time.Second, l.reserve(now))
+	// the bucket is refilled, the burst isn't exceeded
+	require.Equal(t, time.Duration(0), l.reserve(now.Add(10*time.Second)))
+	require.Equal(t, time.Duration(0), l.reserve(now.Add(10*time.Second)))
+	require.Equal(t, 500*time.Millisecond, l.reserve(now.Add(10*time.Second)))
+
+	unlimited := newLimiter(0, 0)
+	require.Equal(t, time.Duration(0), unlimited.reserve(now))
+	require.Equal(t, time.Duration(0), unlimited.reserve(now))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	d, ok := parseRetryAfter("3")
+	require.True(t, ok)
+	require.Equal(t, 3*time.Second, d)
+
+	d, ok = parseRetryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	require.True(t, ok)
+	require.InDelta(t, time.Hour, d, float64(2*time.Second))
+
+	_, ok = parseRetryAfter("")
+	require.False(t, ok)
+
+	_, ok = parseRetryAfter("soon")
+	require.False(t, ok)
+}
+
+func TestListingsScope(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		search []advancedSearch
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body := requestApartmentListBody{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		mu.Lock()
+		search = append(search, body.AdvancedSearch)
+		mu.Unlock()
+
+		_, _ = rw.Write([]byte(`{"realStateItemModel":[
+			{"applicationId":2,"orderDate":"2026-10-01T12:00:00Z","address":{"cityId":95,"cityTitle":"tbilisi"}},
+			{"applicationId":1,"orderDate":"2026-10-01T11:00:00Z","address":{"cityId":96,"cityTitle":"batumi"}}
+		]}`))
+	}))
+	defer srv.Close()
+
+	defaultURL := apartmentListURL
+	apartmentListURL = srv.URL
+	defer func() { apartmentListURL = defaultURL }()
+
+	var (
+		rent     = server.RentAdType
+		maxPrice = 800.0
+	)
+	scope := server.CrawlScope{
+		Cities:   []string{"Batumi", "Tbilisi"},
+		AdType:   &rent,
+		MaxPrice: &maxPrice,
+	}
+
+	p, _ := newTestProvider(Config{})
+
+	// the cities aren't known before the first page
+	listings, err := p.Listings(context.Background(), 1, scope)
+	require.NoError(t, err)
+	require.Equal(t, []apartmentsvc.Listing{
+		{ID: 2, OrderDate: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)},
+		{ID: 1, OrderDate: time.Date(2026, 10, 1, 11, 0, 0, 0, time.UTC)},
+	}, listings)
+
+	_, err = p.Listings(context.Background(), 1, scope)
+	require.NoError(t, err)
+
+	_, err = p.Listings(context.Background(), 1, server.CrawlScope{Cities: []string{"Kutaisi"}})
+	require.NoError(t, err)
+
+	require.Equal(t, []advancedSearch{
+		{WithImageOnly: true, RealEstateDealType: &rentRealEstateDealType, PriceTo: &maxPrice},
+		{WithImageOnly: true, RealEstateDealType: &rentRealEstateDealType, PriceTo: &maxPrice, CityIDList: []int64{96, 95}},
+		{WithImageOnly: true},
+	}, search)
+}
@@ -1,26 +1,59 @@
 package ssge
 
-import "time"
+import (
+	"net/http"
+	"time"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
 
 type StartOpts struct {
 	RefreshTokenInterval time.Duration
 }
 
+// Config contains configuration for creating a new provider
+type Config struct {
+	// Workers is the number of the concurrent detail fetches
+	Workers int
+	// RateLimit is the number of the requests per second to the host, zero disables the limit
+	RateLimit float64
+	RateBurst int
+	// MaxRetries is the number of the retries of the request on 429 and 5xx
+	MaxRetries int
+	// Name is the name of the provider, the replayed provider doesn't share the crawl cursor with ssge
+	Name string
+	// Transport sends the requests, e.g. records or replays them, http.DefaultTransport is used when nil
+	Transport http.RoundTripper
+	// Now is the time the listings expire by, the replay passes the time of the recordings
+	Now func() time.Time
+	// Places resolves the titles of the cities to the canonical names of the crawl scope, the titles are kept when nil
+	Places places
+}
+
+type places interface {
+	Resolve(city, district string) (server.Place, bool)
+}
+
 type apartmentList struct {
 	Data []data `json:"realStateItemModel"`
 }
 
 type data struct {
-	ApplicationID int64 `json:"applicationId"`
+	ApplicationID int64     `json:"applicationId"`
+	OrderDate     time.Time `json:"orderDate"`
+	Address       address   `json:"address"`
 }
 
 type address struct {
+	CityID           int64  `json:"cityId"`
 	CityTitle        string `json:"cityTitle"`
 	SubdistrictTitle string `json:"subdistrictTitle"`
 }
 
 type price struct {
-	PriceUSD float64 `json:"priceUsd"`
+	PriceUSD     float64 `json:"priceUsd"`
+	PriceGeo     float64 `json:"priceGeo"`
+	CurrencyType int64   `json:"currencyType"`
 }
 
 type appImage struct {
@@ -73,5 +106,9 @@ type requestApartmentListBody struct {
 }
 
 type advancedSearch struct {
-	WithImageOnly bool `json:"withImageOnly"`
+	WithImageOnly      bool     `json:"withImageOnly"`
+	RealEstateDealType *int64   `json:"realEstateDealType,omitempty"`
+	CityIDList         []int64  `json:"cityIdList,omitempty"`
+	PriceFrom          *float64 `json:"priceFrom,omitempty"`
+	PriceTo            *float64 `json:"priceTo,omitempty"`
 }
@@ -2,8 +2,12 @@ package apartment
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"reflect"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/irbgeo/apartment-bot/internal/server"
@@ -16,44 +20,94 @@ type service struct {
 	apartmentCh chan server.Apartment
 	errCh       chan error
 
-	maxFetchPages int64
-	apartmentTTL  time.Duration
+	maxFetchPages    int64
+	apartmentTTL     time.Duration
+	maxUpdateLag     time.Duration
+	backfillInterval time.Duration
+	updatedAt        atomic.Int64
 
 	provider provider
 	mu       sync.RWMutex
+	breaker  *breaker
+
+	storage cursorStorage
+	// crawlMutex guards the cursor, the crawls don't run together
+	crawlMutex sync.Mutex
+	cursor     server.CrawlCursor
+
+	scopeMutex sync.RWMutex
+	scope      server.CrawlScope
+	// isScopeChanged requests the backfill, the listings of the widened scope are older than the cursor
+	isScopeChanged atomic.Bool
 }
 
 // provider represents the data provider interface
 type provider interface {
-	Apartments(ctx context.Context, page int64) ([]server.Apartment, error)
-	IsAvailable(ctx context.Context, a server.Apartment) (bool, error)
+	Name() string
+	// Listings returns the listings of the page in the scope, the pages are sorted from the newest
+	Listings(ctx context.Context, page int64, scope server.CrawlScope) ([]Listing, error)
+	// Apartments fetches the details of the listings, the unsuitable listings are skipped
+	Apartments(ctx context.Context, listings []Listing) []server.Apartment
+	// Check returns the reason of the removal of the listing, NotRemoved while it's available
+	Check(ctx context.Context, a server.Apartment) (server.RemovalReason, error)
+	InCache(id int64) bool
 	SetInCache(a server.Apartment)
 	DeleteFromCache(a server.Apartment)
 }
 
+//go:generate mockery --name cursorStorage --structname CursorStorage
+type cursorStorage interface {
+	CrawlCursor(ctx context.Context, provider string) (server.CrawlCursor, error)
+	SaveCrawlCursor(ctx context.Context, c server.CrawlCursor) error
+}
+
 // NewService creates a new apartment service instance
 func NewService(
 	cfg Config,
 	p provider,
+	storage cursorStorage,
 ) *service {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &service{
-		ctx:           ctx,
-		cancel:        cancel,
-		maxFetchPages: cfg.MaxFetchPages,
-		apartmentTTL:  cfg.ApartmentTTL,
-		apartmentCh:   make(chan server.Apartment, 10),
-		errCh:         make(chan error, 10),
-		provider:      p,
+		ctx:              ctx,
+		cancel:           cancel,
+		maxFetchPages:    cfg.MaxFetchPages,
+		apartmentTTL:     cfg.ApartmentTTL,
+		maxUpdateLag:     cfg.MaxUpdateLag,
+		backfillInterval: cfg.BackfillInterval,
+		apartmentCh:      make(chan server.Apartment, 10),
+		errCh:            make(chan error, 10),
+		provider:         p,
+		breaker:          newBreaker(p.Name(), cfg.BreakerMaxFailures, cfg.BreakerOpenTimeout),
+		storage:          storage,
 	}
 }
 
+// Start loads the cursor of the provider, so the restart doesn't crawl the known listings again.
 func (s *service) Start(updateInterval time.Duration) error {
+	cursor, err := s.storage.CrawlCursor(s.ctx, s.provider.Name())
+	if err != nil {
+		return fmt.Errorf("load crawl cursor: %w", err)
+	}
+	cursor.Provider = s.provider.Name()
+	s.cursor = cursor
+
+	s.updatedAt.Store(time.Now().UnixNano())
+
 	go s.startUpdateLoop(updateInterval)
 	return nil
 }
 
+// Health reports whether apartments were updated recently.
+func (s *service) Health(_ context.Context) error {
+	lag := time.Since(time.Unix(0, s.updatedAt.Load()))
+	if lag > s.maxUpdateLag {
+		return fmt.Errorf("apartments were updated %s ago", lag.Round(time.Second))
+	}
+	return nil
+}
+
 func (s *service) Stop() {
 	s.cancel()
 }
@@ -62,10 +116,53 @@ func (s *service) Watcher() <-chan server.Apartment {
 	return s.apartmentCh
 }
 
-func (s *service) IsAvailable(ctx context.Context, a server.Apartment) (bool, error) {
+// Check returns ErrProviderUnavailable while the breaker is open.
+func (s *service) Check(ctx context.Context, a server.Apartment) (server.RemovalReason, error) {
+	if err := s.breaker.allow(); err != nil {
+		return server.NotRemoved, err
+	}
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return s.provider.IsAvailable(ctx, a)
+
+	reason, err := s.provider.Check(ctx, a)
+	s.breaker.done(err)
+	return reason, err
+}
+
+// ProviderHealth reports whether the breaker of the provider is closed,
+// the service keeps serving the stored apartments while it's open.
+func (s *service) ProviderHealth(_ context.Context) error {
+	return s.breaker.Err()
+}
+
+// SetCrawlScope restricts the listings of the next crawls.
+func (s *service) SetCrawlScope(scope server.CrawlScope) {
+	s.scopeMutex.Lock()
+	defer s.scopeMutex.Unlock()
+
+	if reflect.DeepEqual(s.scope, scope) {
+		return
+	}
+
+	s.scope = scope
+	s.isScopeChanged.Store(true)
+	slog.Info("crawl scope", "scope", scope)
+}
+
+func (s *service) crawlScope() server.CrawlScope {
+	s.scopeMutex.RLock()
+	defer s.scopeMutex.RUnlock()
+	return s.scope
+}
+
+func (s *service) ProviderState() server.ProviderState {
+	return s.breaker.State()
+}
+
+// StateWatcher receives the state of the provider when it goes down or recovers.
+func (s *service) StateWatcher() <-chan server.ProviderState {
+	return s.breaker.stateCh
 }
 
 func (s *service) SetInCache(a server.Apartment) {
@@ -96,60 +193,153 @@ func (s *service) startUpdateLoop(interval time.Duration) {
 		select {
 		case <-s.ctx.Done():
 			return
-		case <-ticker.C:
-			s.update()
+		case now := <-ticker.C:
+			s.update(s.isBackfillDue(now))
 		}
 	}
 }
 
+// isBackfillDue reports whether all pages should be crawled, the time of the last backfill
+// is kept in the cursor, so the restart doesn't walk all pages again.
+func (s *service) isBackfillDue(now time.Time) bool {
+	if s.isScopeChanged.Swap(false) {
+		return true
+	}
+
+	s.crawlMutex.Lock()
+	defer s.crawlMutex.Unlock()
+	return now.Sub(s.cursor.BackfilledAt) >= s.backfillInterval
+}
+
 func (s *service) fetchApartments(resultCh chan<- server.Apartment) {
-	var wg sync.WaitGroup
 	defer close(resultCh)
 
 	for page := int64(1); page <= s.maxFetchPages; page++ {
-		apartments, err := s.fetchPage(page)
+		listings, err := s.fetchListings(page)
+		if errors.Is(err, ErrProviderUnavailable) {
+			return
+		}
 		if err != nil {
 			slog.Error("fetch apartments page", "page", page, "error", err)
 			continue
 		}
 
-		if len(apartments) == 0 {
+		if len(listings) == 0 {
 			break
 		}
 
-		wg.Add(len(apartments))
-		for _, apt := range apartments {
-			go func(a server.Apartment) {
-				defer wg.Done()
-				resultCh <- a
-			}(apt)
+		for _, a := range s.newApartments(listings) {
+			resultCh <- a
 		}
 	}
-
-	wg.Wait()
 }
 
-func (s *service) update() {
+// update crawls the new apartments from the first page, the incremental crawl stops
+// at the page with the listings known by the cursor, the backfill crawl walks all pages.
+// The cursor isn't moved when a page fails, the listings of the page would be skipped otherwise.
+func (s *service) update(isBackfill bool) {
+	s.crawlMutex.Lock()
+	defer s.crawlMutex.Unlock()
+
+	newest := s.cursor
+	if isBackfill {
+		newest.BackfilledAt = time.Now()
+	}
+
+	isFailed := false
 	for page := int64(1); page <= s.maxFetchPages; page++ {
-		apartments, err := s.fetchPage(page)
+		listings, err := s.fetchListings(page)
+		// the cursor isn't moved, the next crawl continues from the known listings
+		if errors.Is(err, ErrProviderUnavailable) {
+			return
+		}
 		if err != nil {
 			slog.Error("update apartments page", "page", page, "error", err)
+			isFailed = true
 			continue
 		}
 
-		if len(apartments) == 0 {
-			return
+		s.updatedAt.Store(time.Now().UnixNano())
+
+		if len(listings) == 0 {
+			break
+		}
+
+		isKnownReached := false
+		for _, l := range listings {
+			if isNewer(l, newest) {
+				newest.OrderDate, newest.ApartmentID = l.OrderDate, l.ID
+			}
+			if !isNewer(l, s.cursor) {
+				isKnownReached = true
+			}
 		}
 
+		apartments := s.newApartments(listings)
 		s.broadcastApartments(apartments)
-		slog.Info("fetched apartments", "page", page, "count", len(apartments))
+		slog.Info("fetched apartments", "page", page, "count", len(apartments), "backfill", isBackfill)
+
+		if isKnownReached && !isBackfill {
+			break
+		}
+	}
+
+	if isFailed {
+		newest.OrderDate, newest.ApartmentID = s.cursor.OrderDate, s.cursor.ApartmentID
+	}
+	s.saveCursor(newest)
+}
+
+// isNewer reports whether the listing is newer than the cursor,
+// the listings of the same time are ordered by ID.
+func isNewer(l Listing, c server.CrawlCursor) bool {
+	if !l.OrderDate.Equal(c.OrderDate) {
+		return l.OrderDate.After(c.OrderDate)
 	}
+	return l.ID > c.ApartmentID
 }
 
-func (s *service) fetchPage(page int64) ([]server.Apartment, error) {
+func (s *service) saveCursor(c server.CrawlCursor) {
+	if c == s.cursor {
+		return
+	}
+
+	if err := s.storage.SaveCrawlCursor(s.ctx, c); err != nil {
+		slog.Error("save crawl cursor", "provider", c.Provider, "error", err)
+		return
+	}
+	s.cursor = c
+}
+
+func (s *service) fetchListings(page int64) ([]Listing, error) {
+	if err := s.breaker.allow(); err != nil {
+		return nil, err
+	}
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return s.provider.Apartments(s.ctx, page)
+
+	listings, err := s.provider.Listings(s.ctx, page, s.crawlScope())
+	s.breaker.done(err)
+	return listings, err
+}
+
+// newApartments fetches the details of the listings which aren't in the cache.
+func (s *service) newApartments(listings []Listing) []server.Apartment {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	notCached := make([]Listing, 0, len(listings))
+	for _, l := range listings {
+		if !s.provider.InCache(l.ID) {
+			notCached = append(notCached, l)
+		}
+	}
+	if len(notCached) == 0 {
+		return nil
+	}
+
+	return s.provider.Apartments(s.ctx, notCached)
 }
 
 func (s *service) broadcastApartments(apartments []server.Apartment) {
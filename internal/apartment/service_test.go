@@ -0,0 +1,245 @@
+package apartment
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+type fakeProvider struct {
+	provider
+
+	pages [][]Listing
+	// failedPage returns the error
+	failedPage int64
+
+	mu        sync.Mutex
+	requested []int64
+	cache     map[int64]struct{}
+}
+
+func (p *fakeProvider) Name() string { return "fake" }
+
+func (p *fakeProvider) Listings(_ context.Context, page int64, _ server.CrawlScope) ([]Listing, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.requested = append(p.requested, page)
+	if page == p.failedPage {
+		return nil, errors.New("status code: 502")
+	}
+	if int(page) > len(p.pages) {
+		return nil, nil
+	}
+	return p.pages[page-1], nil
+}
+
+func (p *fakeProvider) Apartments(_ context.Context, listings []Listing) []server.Apartment {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	result := make([]server.Apartment, 0, len(listings))
+	for _, l := range listings {
+		p.cache[l.ID] = struct{}{}
+		result = append(result, server.Apartment{ID: l.ID})
+	}
+	return result
+}
+
+func (p *fakeProvider) InCache(id int64) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_, ok := p.cache[id]
+	return ok
+}
+
+type fakeCursorStorage struct {
+	cursor server.CrawlCursor
+	saved  []server.CrawlCursor
+}
+
+func (s *fakeCursorStorage) CrawlCursor(_ context.Context, provider string) (server.CrawlCursor, error) {
+	c := s.cursor
+	c.Provider = provider
+	return c, nil
+}
+
+func (s *fakeCursorStorage) SaveCrawlCursor(_ context.Context, c server.CrawlCursor) error {
+	s.saved = append(s.saved, c)
+	return nil
+}
+
+func TestUpdate(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	pages := [][]Listing{
+		{{ID: 6, OrderDate: now}, {ID: 5, OrderDate: now.Add(-time.Minute)}},
+		{{ID: 4, OrderDate: now.Add(-2 * time.Minute)}, {ID: 3, OrderDate: now.Add(-3 * time.Minute)}},
+		{{ID: 2, OrderDate: now.Add(-4 * time.Minute)}, {ID: 1, OrderDate: now.Add(-5 * time.Minute)}},
+	}
+	newest := server.CrawlCursor{Provider: "fake", OrderDate: now, ApartmentID: 6}
+
+	testCases := []struct {
+		testCaseName      string
+		cursor            server.CrawlCursor
+		failedPage        int64
+		isBackfill        bool
+		expectedPages     []int64
+		expectedApartment []int64
+		expectedSaved     []server.CrawlCursor
+	}{
+		{
+			testCaseName:      "first crawl",
+			isBackfill:        false,
+			expectedPages:     []int64{1, 2, 3, 4},
+			expectedApartment: []int64{1, 2, 3, 4, 5, 6},
+			expectedSaved:     []server.CrawlCursor{newest},
+		},
+		{
+			testCaseName:      "stop at known listing",
+			cursor:            server.CrawlCursor{OrderDate: now.Add(-3 * time.Minute), ApartmentID: 3},
+			isBackfill:        false,
+			expectedPages:     []int64{1, 2},
+			expectedApartment: []int64{4, 5, 6},
+			expectedSaved:     []server.CrawlCursor{newest},
+		},
+		{
+			testCaseName:      "same time listing is ordered by id",
+			cursor:            server.CrawlCursor{OrderDate: now, ApartmentID: 5},
+			isBackfill:        false,
+			expectedPages:     []int64{1},
+			expectedApartment: []int64{6},
+			expectedSaved:     []server.CrawlCursor{newest},
+		},
+		{
+			testCaseName:  "nothing new",
+			cursor:        newest,
+			isBackfill:    false,
+			expectedPages: []int64{1},
+		},
+		{
+			testCaseName:      "backfill walks all pages",
+			cursor:            newest,
+			isBackfill:        true,
+			expectedPages:     []int64{1, 2, 3, 4},
+			expectedApartment: []int64{},
+			expectedSaved:     []server.CrawlCursor{newest},
+		},
+		{
+			testCaseName:      "failed page keeps the cursor",
+			cursor:            server.CrawlCursor{OrderDate: now.Add(-3 * time.Minute), ApartmentID: 3},
+			failedPage:        1,
+			isBackfill:        false,
+			expectedPages:     []int64{1, 2},
+			expectedApartment: []int64{4},
+		},
+		{
+			testCaseName:      "failed backfill keeps the cursor and the backfill time",
+			cursor:            server.CrawlCursor{OrderDate: now.Add(-3 * time.Minute), ApartmentID: 3},
+			failedPage:        2,
+			isBackfill:        true,
+			expectedPages:     []int64{1, 2, 3, 4},
+			expectedApartment: []int64{5, 6},
+			expectedSaved:     []server.CrawlCursor{{Provider: "fake", OrderDate: now.Add(-3 * time.Minute), ApartmentID: 3}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCaseName, func(t *testing.T) {
+			p := &fakeProvider{
+				pages:      pages,
+				failedPage: tc.failedPage,
+				cache:      make(map[int64]struct{}),
+			}
+			// the listings known by the cursor are in the cache
+			for id := int64(1); id <= tc.cursor.ApartmentID; id++ {
+				p.cache[id] = struct{}{}
+			}
+			storage := &fakeCursorStorage{cursor: tc.cursor}
+
+			s := NewService(Config{MaxFetchPages: 10, BreakerMaxFailures: 10}, p, storage)
+			defer s.Stop()
+			// the update loop isn't started, the crawl is called directly
+			cursor, err := storage.CrawlCursor(s.ctx, p.Name())
+			require.NoError(t, err)
+			s.cursor = cursor
+
+			s.update(tc.isBackfill)
+
+			require.Equal(t, tc.expectedPages, p.requested)
+			// the backfill time is saved with the cursor
+			for i, c := range storage.saved {
+				require.Equal(t, tc.isBackfill, !c.BackfilledAt.IsZero())
+				storage.saved[i].BackfilledAt = time.Time{}
+			}
+			require.Equal(t, tc.expectedSaved, storage.saved)
+
+			received := make([]int64, 0, len(tc.expectedApartment))
+			for range tc.expectedApartment {
+				select {
+				case a := <-s.Watcher():
+					received = append(received, a.ID)
+				case <-time.After(time.Second):
+					t.Fatal("the apartment isn't broadcast")
+				}
+			}
+			require.ElementsMatch(t, tc.expectedApartment, received)
+
+			select {
+			case a := <-s.Watcher():
+				t.Fatalf("unexpected apartment %d", a.ID)
+			case <-time.After(10 * time.Millisecond):
+			}
+		})
+	}
+}
+
+func TestIsBackfillDue(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		testCaseName   string
+		backfilledAt   time.Time
+		isScopeChanged bool
+		expected       bool
+	}{
+		{
+			testCaseName: "never backfilled",
+			expected:     true,
+		},
+		{
+			testCaseName: "backfilled before the restart",
+			backfilledAt: now.Add(-time.Hour),
+			expected:     false,
+		},
+		{
+			testCaseName: "backfill interval passed",
+			backfilledAt: now.Add(-7 * time.Hour),
+			expected:     true,
+		},
+		{
+			testCaseName:   "scope changed",
+			backfilledAt:   now.Add(-time.Hour),
+			isScopeChanged: true,
+			expected:       true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCaseName, func(t *testing.T) {
+			s := NewService(Config{BackfillInterval: 6 * time.Hour}, &fakeProvider{}, &fakeCursorStorage{})
+			defer s.Stop()
+			s.cursor = server.CrawlCursor{BackfilledAt: tc.backfilledAt}
+			s.isScopeChanged.Store(tc.isScopeChanged)
+
+			require.Equal(t, tc.expected, s.isBackfillDue(now))
+			require.False(t, s.isScopeChanged.Load())
+		})
+	}
+}
@@ -6,4 +6,19 @@ import "time"
 type Config struct {
 	MaxFetchPages int64
 	ApartmentTTL  time.Duration
+	// MaxUpdateLag is the time without a successful update after which the service is unhealthy
+	MaxUpdateLag time.Duration
+	// BackfillInterval is the period of the crawl of all MaxFetchPages,
+	// the crawls between them stop at the known listings
+	BackfillInterval time.Duration
+	// BreakerMaxFailures is the number of the failed requests in a row which opens the breaker
+	BreakerMaxFailures int
+	// BreakerOpenTimeout is the time after which the open breaker lets the probe request through
+	BreakerOpenTimeout time.Duration
+}
+
+// Listing is the apartment on the list page of the provider, the pages are sorted from the newest.
+type Listing struct {
+	ID        int64
+	OrderDate time.Time
 }
@@ -2,25 +2,41 @@ package health
 
 import (
 	"context"
+	"log/slog"
 	"net"
+	"sync"
+	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	health "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 )
 
 type server struct {
+	components    []Component
+	checkInterval time.Duration
+	checkTimeout  time.Duration
+
+	mu       sync.RWMutex
+	statuses map[string]health.HealthCheckResponse_ServingStatus
+	reports  map[string]string
+	watchers map[chan struct{}]struct{}
 }
 
 func ListenAndServe(
-	addr string,
+	cfg Config,
+	components ...Component,
 ) error {
-	l, err := net.Listen("tcp", addr)
+	l, err := net.Listen("tcp", cfg.Address)
 	if err != nil {
 		return err
 	}
 
-	srv := &server{}
+	srv := newServer(cfg, components...)
+	go srv.startCheckLoop()
 
 	s := grpc.NewServer()
 
@@ -31,10 +47,182 @@ func ListenAndServe(
 	return s.Serve(l)
 }
 
+func newServer(cfg Config, components ...Component) *server {
+	s := &server{
+		components:    components,
+		checkInterval: cfg.CheckInterval,
+		checkTimeout:  cfg.CheckTimeout,
+		statuses:      make(map[string]health.HealthCheckResponse_ServingStatus),
+		reports:       make(map[string]string),
+		watchers:      make(map[chan struct{}]struct{}),
+	}
+
+	s.statuses[LivenessService] = health.HealthCheckResponse_SERVING
+	s.statuses[ReadinessService] = health.HealthCheckResponse_NOT_SERVING
+	s.statuses[""] = health.HealthCheckResponse_NOT_SERVING
+	for _, c := range components {
+		s.statuses[c.Name] = health.HealthCheckResponse_NOT_SERVING
+	}
+
+	return s
+}
+
 func (s *server) Check(ctx context.Context, in *health.HealthCheckRequest) (*health.HealthCheckResponse, error) {
-	return &health.HealthCheckResponse{Status: health.HealthCheckResponse_SERVING}, nil
+	st, ok := s.status(in.Service)
+	if !ok {
+		return nil, status.Error(codes.NotFound, "unknown service")
+	}
+
+	if md := s.reportMetadata(in.Service); md.Len() > 0 {
+		if err := grpc.SetHeader(ctx, md); err != nil {
+			slog.Error("health_report", "err", err)
+		}
+	}
+
+	return &health.HealthCheckResponse{Status: st}, nil
+}
+
+func (s *server) Watch(in *health.HealthCheckRequest, stream health.Health_WatchServer) error {
+	update := s.subscribe()
+	defer s.unsubscribe(update)
+
+	var (
+		last    health.HealthCheckResponse_ServingStatus
+		started bool
+	)
+	for {
+		st, ok := s.status(in.Service)
+		if !ok {
+			st = health.HealthCheckResponse_SERVICE_UNKNOWN
+		}
+
+		if !started || st != last {
+			if err := stream.Send(&health.HealthCheckResponse{Status: st}); err != nil {
+				return err
+			}
+			last, started = st, true
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-update:
+		}
+	}
+}
+
+func (s *server) startCheckLoop() {
+	s.check()
+
+	ticker := time.NewTicker(s.checkInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.check()
+	}
+}
+
+// check evaluates all components and notifies watchers if any status changed.
+func (s *server) check() {
+	statuses := make(map[string]health.HealthCheckResponse_ServingStatus, len(s.components)+3)
+	reports := make(map[string]string)
+
+	isLive, isReady := true, true
+	for _, c := range s.components {
+		ctx, cancel := context.WithTimeout(context.Background(), s.checkTimeout)
+		err := c.Checker.Health(ctx)
+		cancel()
+
+		statuses[c.Name] = health.HealthCheckResponse_SERVING
+		if err != nil {
+			statuses[c.Name] = health.HealthCheckResponse_NOT_SERVING
+			isLive = isLive && !c.Liveness
+			isReady = isReady && !c.Readiness
+		}
+
+		if r, ok := c.Checker.(Reporter); ok {
+			reports[c.Name] = r.HealthReport()
+		}
+
+		if prev, _ := s.status(c.Name); prev != statuses[c.Name] {
+			slog.Info("health_status", "component", c.Name, "status", statuses[c.Name], "reason", err, "report", reports[c.Name])
+		}
+	}
+
+	statuses[LivenessService] = servingStatus(isLive)
+	statuses[ReadinessService] = servingStatus(isReady)
+	statuses[""] = statuses[ReadinessService]
+
+	s.setStatuses(statuses, reports)
+}
+
+func (s *server) status(service string) (health.HealthCheckResponse_ServingStatus, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	st, ok := s.statuses[service]
+	return st, ok
 }
 
-func (s *server) Watch(in *health.HealthCheckRequest, _ health.Health_WatchServer) error {
-	return nil
+// reportMetadata returns the report of the component or the reports of all components for the aggregated services.
+func (s *server) reportMetadata(service string) metadata.MD {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	md := metadata.MD{}
+	for name, report := range s.reports {
+		if service == name || service == "" || service == LivenessService || service == ReadinessService {
+			md.Set(name, report)
+		}
+	}
+	return md
+}
+
+func (s *server) setStatuses(statuses map[string]health.HealthCheckResponse_ServingStatus, reports map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reports = reports
+
+	isChanged := false
+	for name, st := range statuses {
+		if s.statuses[name] != st {
+			isChanged = true
+		}
+		s.statuses[name] = st
+	}
+
+	if !isChanged {
+		return
+	}
+
+	for ch := range s.watchers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (s *server) subscribe() chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch := make(chan struct{}, 1)
+	s.watchers[ch] = struct{}{}
+	return ch
+}
+
+func (s *server) unsubscribe(ch chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.watchers, ch)
+}
+
+func servingStatus(ok bool) health.HealthCheckResponse_ServingStatus {
+	if ok {
+		return health.HealthCheckResponse_SERVING
+	}
+	return health.HealthCheckResponse_NOT_SERVING
 }
@@ -0,0 +1,151 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	health "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+)
+
+type checkerFunc func(ctx context.Context) error
+
+func (f checkerFunc) Health(ctx context.Context) error {
+	return f(ctx)
+}
+
+type reporterFunc struct {
+	checkerFunc
+	report string
+}
+
+func (r reporterFunc) HealthReport() string {
+	return r.report
+}
+
+// transportStream keeps the header set by the handler
+type transportStream struct {
+	grpc.ServerTransportStream
+	header metadata.MD
+}
+
+func (s *transportStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+type watchStream struct {
+	grpc.ServerStream
+	ctx context.Context
+	ch  chan health.HealthCheckResponse_ServingStatus
+}
+
+func (s *watchStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *watchStream) Send(r *health.HealthCheckResponse) error {
+	s.ch <- r.Status
+	return nil
+}
+
+func TestCheck(t *testing.T) {
+	var dbErr error
+	components := []Component{
+		{Name: "db", Checker: checkerFunc(func(context.Context) error { return dbErr }), Readiness: true},
+		{Name: "subscribers", Checker: checkerFunc(func(context.Context) error { return errors.New("no subscribers") })},
+	}
+
+	s := newServer(Config{CheckTimeout: time.Second}, components...)
+	s.check()
+
+	testCases := []struct {
+		service  string
+		expected health.HealthCheckResponse_ServingStatus
+	}{
+		{service: "db", expected: health.HealthCheckResponse_SERVING},
+		{service: "subscribers", expected: health.HealthCheckResponse_NOT_SERVING},
+		{service: LivenessService, expected: health.HealthCheckResponse_SERVING},
+		{service: ReadinessService, expected: health.HealthCheckResponse_SERVING},
+		{service: "", expected: health.HealthCheckResponse_SERVING},
+	}
+
+	for _, tc := range testCases {
+		res, err := s.Check(context.Background(), &health.HealthCheckRequest{Service: tc.service})
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, res.Status, tc.service)
+	}
+
+	dbErr = errors.New("ping failed")
+	s.check()
+
+	res, err := s.Check(context.Background(), &health.HealthCheckRequest{Service: ReadinessService})
+	require.NoError(t, err)
+	require.Equal(t, health.HealthCheckResponse_NOT_SERVING, res.Status)
+
+	_, err = s.Check(context.Background(), &health.HealthCheckRequest{Service: "unknown"})
+	require.Error(t, err)
+}
+
+func TestCheckReport(t *testing.T) {
+	healthy := checkerFunc(func(context.Context) error { return nil })
+	s := newServer(
+		Config{CheckTimeout: time.Second},
+		Component{Name: "db", Checker: healthy, Readiness: true},
+		Component{Name: "subscribers", Checker: reporterFunc{checkerFunc: healthy, report: "3"}},
+	)
+	s.check()
+
+	testCases := []struct {
+		service  string
+		expected metadata.MD
+	}{
+		{service: "subscribers", expected: metadata.Pairs("subscribers", "3")},
+		{service: ReadinessService, expected: metadata.Pairs("subscribers", "3")},
+		{service: "db"},
+	}
+
+	for _, tc := range testCases {
+		stream := &transportStream{}
+		ctx := grpc.NewContextWithServerTransportStream(context.Background(), stream)
+
+		_, err := s.Check(ctx, &health.HealthCheckRequest{Service: tc.service})
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, stream.header, tc.service)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	var dbErr error
+	s := newServer(
+		Config{CheckTimeout: time.Second},
+		Component{Name: "db", Checker: checkerFunc(func(context.Context) error { return dbErr }), Readiness: true},
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := &watchStream{
+		ctx: ctx,
+		ch:  make(chan health.HealthCheckResponse_ServingStatus, 10),
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- s.Watch(&health.HealthCheckRequest{Service: ReadinessService}, stream)
+	}()
+
+	require.Equal(t, health.HealthCheckResponse_NOT_SERVING, <-stream.ch)
+
+	s.check()
+	require.Equal(t, health.HealthCheckResponse_SERVING, <-stream.ch)
+
+	dbErr = errors.New("ping failed")
+	s.check()
+	require.Equal(t, health.HealthCheckResponse_NOT_SERVING, <-stream.ch)
+
+	cancel()
+	require.NoError(t, <-done)
+}
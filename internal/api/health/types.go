@@ -0,0 +1,47 @@
+package health
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// LivenessService reports whether the process should be restarted
+	LivenessService = "liveness"
+	// ReadinessService reports whether the process is able to serve users
+	ReadinessService = "readiness"
+)
+
+// Config contains configuration for the health server
+type Config struct {
+	Address       string
+	CheckInterval time.Duration
+	CheckTimeout  time.Duration
+}
+
+// Component is a dependency whose health is reported as a separate service.
+type Component struct {
+	Name    string
+	Checker Checker
+	// Liveness and Readiness define which aggregated status depends on the component
+	Liveness  bool
+	Readiness bool
+}
+
+// Checker returns nil if the component is healthy and the reason otherwise.
+type Checker interface {
+	Health(ctx context.Context) error
+}
+
+// Reporter is implemented by the checkers reporting the state of the component along with the status,
+// the report is returned in the header of the check response under the component name.
+type Reporter interface {
+	HealthReport() string
+}
+
+// CheckerFunc is the function used as the Checker.
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) Health(ctx context.Context) error {
+	return f(ctx)
+}
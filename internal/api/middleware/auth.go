@@ -14,50 +14,47 @@ import (
 )
 
 const (
-	authKey = "auth_key"
-	idKey   = "id_key"
+	authKey  = "auth_key"
+	adminKey = "admin_key"
+	idKey    = "id_key"
+
+	// adminMethodPrefix marks the methods of the admin scope
+	adminMethodPrefix = "/Admin"
 )
 
-func AddMetadataUnaryInterceptor(tokenAuth string, id int64) grpc.UnaryClientInterceptor {
+func AddMetadataUnaryInterceptor(tokenAuth, tokenAdmin string, id int64) grpc.UnaryClientInterceptor {
 	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
-		md := metadata.New(map[string]string{
-			authKey: tokenAuth,
-			idKey:   strconv.FormatInt(id, 10),
-		})
-		ctx = metadata.NewOutgoingContext(ctx, md)
+		ctx = metadata.NewOutgoingContext(ctx, outgoingMetadata(tokenAuth, tokenAdmin, id))
 		return invoker(ctx, method, req, reply, cc, opts...)
 	}
 }
 
-func AddMetadataStreamInterceptor(tokenAuth string, id int64) grpc.StreamClientInterceptor {
+func AddMetadataStreamInterceptor(tokenAuth, tokenAdmin string, id int64) grpc.StreamClientInterceptor {
 	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
-		md := metadata.New(map[string]string{
-			authKey: tokenAuth,
-			idKey:   strconv.FormatInt(id, 10),
-		})
-		ctx = metadata.NewOutgoingContext(ctx, md)
+		ctx = metadata.NewOutgoingContext(ctx, outgoingMetadata(tokenAuth, tokenAdmin, id))
 		return streamer(ctx, desc, cc, method, opts...)
 	}
 }
 
-func CheckMetadataUnaryInterceptor(tokenAuth string) grpc.UnaryServerInterceptor {
+func outgoingMetadata(tokenAuth, tokenAdmin string, id int64) metadata.MD {
+	md := metadata.New(map[string]string{
+		authKey: tokenAuth,
+		idKey:   strconv.FormatInt(id, 10),
+	})
+	if tokenAdmin != "" {
+		md.Set(adminKey, tokenAdmin)
+	}
+	return md
+}
+
+func CheckMetadataUnaryInterceptor(tokenAuth, tokenAdmin string) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 		if strings.Contains(info.FullMethod, "Health") {
 			return handler(ctx, req)
 		}
 
-		md, ok := metadata.FromIncomingContext(ctx)
-		if !ok {
-			return nil, status.Error(codes.InvalidArgument, "missing metadata")
-		}
-
-		token, ok := md[authKey]
-		if !ok {
-			return nil, status.Error(codes.Unauthenticated, "missing token")
-		}
-
-		if token[0] != tokenAuth {
-			return nil, status.Error(codes.Unauthenticated, "invalid token")
+		if err := checkMetadata(ctx, info.FullMethod, tokenAuth, tokenAdmin); err != nil {
+			return nil, err
 		}
 
 		ctx = utils.PackVar(ctx, utils.IDKey, GetID(ctx))
@@ -66,28 +63,45 @@ func CheckMetadataUnaryInterceptor(tokenAuth string) grpc.UnaryServerInterceptor
 	}
 }
 
-func CheckMetadataStreamInterceptor(tokenAuth string) grpc.StreamServerInterceptor {
+func CheckMetadataStreamInterceptor(tokenAuth, tokenAdmin string) grpc.StreamServerInterceptor {
 	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
 		if strings.Contains(info.FullMethod, "Health") {
 			return handler(srv, ss)
 		}
 
-		md, ok := metadata.FromIncomingContext(ss.Context())
-		if !ok {
-			return status.Error(codes.InvalidArgument, "missing metadata")
+		if err := checkMetadata(ss.Context(), info.FullMethod, tokenAuth, tokenAdmin); err != nil {
+			return err
 		}
 
-		token, ok := md[authKey]
-		if !ok {
-			return status.Error(codes.Unauthenticated, "missing token")
-		}
+		return handler(srv, ss)
+	}
+}
 
-		if token[0] != tokenAuth {
-			return status.Error(codes.Unauthenticated, "invalid token")
-		}
+// checkMetadata checks the token of the client, the methods of the admin scope require the admin token.
+func checkMetadata(ctx context.Context, method, tokenAuth, tokenAdmin string) error {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return status.Error(codes.InvalidArgument, "missing metadata")
+	}
 
-		return handler(srv, ss)
+	token, ok := md[authKey]
+	if !ok {
+		return status.Error(codes.Unauthenticated, "missing token")
+	}
+
+	if token[0] != tokenAuth {
+		return status.Error(codes.Unauthenticated, "invalid token")
+	}
+
+	if !strings.Contains(method, adminMethodPrefix) {
+		return nil
+	}
+
+	token, ok = md[adminKey]
+	if !ok || tokenAdmin == "" || token[0] != tokenAdmin {
+		return status.Error(codes.PermissionDenied, "admin scope is required")
 	}
+	return nil
 }
 
 func GetID(ctx context.Context) int64 {
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestCheckMetadata(t *testing.T) {
+	testCases := []struct {
+		testCaseName string
+		method       string
+		tokenAdmin   string
+		md           metadata.MD
+		expectedCode codes.Code
+	}{
+		{
+			testCaseName: "client method",
+			method:       "/server.Server/SaveFilter",
+			tokenAdmin:   "admin",
+			md:           outgoingMetadata("auth", "", 1),
+			expectedCode: codes.OK,
+		},
+		{
+			testCaseName: "invalid token",
+			method:       "/server.Server/SaveFilter",
+			md:           outgoingMetadata("wrong", "", 1),
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			testCaseName: "admin method",
+			method:       "/server.Server/AdminStats",
+			tokenAdmin:   "admin",
+			md:           outgoingMetadata("auth", "admin", 1),
+			expectedCode: codes.OK,
+		},
+		{
+			testCaseName: "admin method without admin token",
+			method:       "/server.Server/AdminStats",
+			tokenAdmin:   "admin",
+			md:           outgoingMetadata("auth", "", 1),
+			expectedCode: codes.PermissionDenied,
+		},
+		{
+			testCaseName: "admin plan without admin token",
+			method:       "/server.Server/AdminSetPlan",
+			tokenAdmin:   "admin",
+			md:           outgoingMetadata("auth", "", 1),
+			expectedCode: codes.PermissionDenied,
+		},
+		{
+			testCaseName: "admin method with invalid admin token",
+			method:       "/server.Server/AdminBan",
+			tokenAdmin:   "admin",
+			md:           outgoingMetadata("auth", "wrong", 1),
+			expectedCode: codes.PermissionDenied,
+		},
+		{
+			testCaseName: "admin scope is disabled",
+			method:       "/server.Server/AdminRefresh",
+			md:           outgoingMetadata("auth", "", 1),
+			expectedCode: codes.PermissionDenied,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCaseName, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), tc.md)
+			err := checkMetadata(ctx, tc.method, "auth", tc.tokenAdmin)
+			require.Equal(t, tc.expectedCode, status.Code(err))
+		})
+	}
+}
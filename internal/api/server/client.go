@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"strings"
 
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/status"
@@ -22,14 +23,15 @@ type client struct {
 }
 
 func NewClient(
-	addr, authToken string,
+	addr, authToken, adminToken string,
 	id int64,
 ) (*client, error) {
 	conn, err := grpc.NewClient(
 		addr,
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithUnaryInterceptor(middleware.AddMetadataUnaryInterceptor(authToken, id)),
-		grpc.WithStreamInterceptor(middleware.AddMetadataStreamInterceptor(authToken, id)),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		grpc.WithUnaryInterceptor(middleware.AddMetadataUnaryInterceptor(authToken, adminToken, id)),
+		grpc.WithStreamInterceptor(middleware.AddMetadataStreamInterceptor(authToken, adminToken, id)),
 	)
 
 	if err != nil {
@@ -144,6 +146,258 @@ func (s *client) Cities(ctx context.Context) (map[string][]string, error) {
 	return result, nil
 }
 
+func (s *client) Stats(ctx context.Context, f server.StatsFilter) (*server.StatsReport, error) {
+	res, err := s.cli.Stats(ctx, statsFilterToAPI(f))
+	if err != nil {
+		err = fmt.Errorf(status.Convert(err).Message())
+		return nil, err
+	}
+
+	return statsReportFromAPI(res), nil
+}
+
+func (s *client) MarkApartment(ctx context.Context, c server.ApartmentMarkChange) (*server.ApartmentMark, error) {
+	res, err := s.cli.MarkApartment(ctx, apartmentMarkChangeToAPI(c))
+	if err != nil {
+		err = fmt.Errorf(status.Convert(err).Message())
+		return nil, err
+	}
+
+	mark := apartmentMarkFromAPI(res)
+	return &mark, nil
+}
+
+func (s *client) SearchApartments(ctx context.Context, q server.ApartmentSearch) (*server.ApartmentPage, error) {
+	res, err := s.cli.SearchApartments(ctx, apartmentSearchToAPI(q))
+	if err != nil {
+		err = fmt.Errorf(status.Convert(err).Message())
+		return nil, err
+	}
+
+	return apartmentPageFromAPI(res), nil
+}
+
+func (s *client) BrowseApartments(ctx context.Context, b server.ApartmentBrowse) (*server.ApartmentBrowsePage, error) {
+	res, err := s.cli.BrowseApartments(ctx, apartmentBrowseToAPI(b))
+	if err != nil {
+		err = fmt.Errorf(status.Convert(err).Message())
+		return nil, err
+	}
+
+	return apartmentBrowsePageFromAPI(res), nil
+}
+
+func (s *client) SavedApartments(ctx context.Context, u server.User) ([]server.ApartmentMark, error) {
+	res, err := s.cli.SavedApartments(ctx, &api.User{Id: u.ID})
+	if err != nil {
+		err = fmt.Errorf(status.Convert(err).Message())
+		return nil, err
+	}
+
+	return apartmentMarkListFromAPI(res), nil
+}
+
+func (s *client) Viewings(ctx context.Context, u server.User) ([]server.ApartmentMark, error) {
+	res, err := s.cli.Viewings(ctx, &api.User{Id: u.ID})
+	if err != nil {
+		err = fmt.Errorf(status.Convert(err).Message())
+		return nil, err
+	}
+
+	return apartmentMarkListFromAPI(res), nil
+}
+
+func (s *client) ShareFilter(ctx context.Context, f server.Filter) (string, error) {
+	res, err := s.cli.ShareFilter(ctx, filterToAPI(f))
+	if err != nil {
+		err = fmt.Errorf(status.Convert(err).Message())
+		return "", err
+	}
+
+	return res.Token, nil
+}
+
+func (s *client) JoinFilter(ctx context.Context, j server.FilterJoin) (*server.Filter, error) {
+	res, err := s.cli.JoinFilter(ctx, filterJoinToAPI(j))
+	if err != nil {
+		err = fmt.Errorf(status.Convert(err).Message())
+		return nil, err
+	}
+
+	f := filterFromAPI(res)
+	return &f, nil
+}
+
+func (s *client) Plans(ctx context.Context) ([]server.Plan, error) {
+	res, err := s.cli.Plans(ctx, &emptypb.Empty{})
+	if err != nil {
+		err = fmt.Errorf(status.Convert(err).Message())
+		return nil, err
+	}
+
+	return planListFromAPI(res), nil
+}
+
+func (s *client) UserInfo(ctx context.Context, u server.User) (*server.User, error) {
+	res, err := s.cli.UserInfo(ctx, userToAPI(u))
+	if err != nil {
+		err = fmt.Errorf(status.Convert(err).Message())
+		return nil, err
+	}
+
+	user := userFromAPI(res)
+	return &user, nil
+}
+
+func (s *client) SetUserPlan(ctx context.Context, p server.UserPlan) (*server.User, error) {
+	res, err := s.cli.SetUserPlan(ctx, userPlanToAPI(p))
+	if err != nil {
+		err = fmt.Errorf(status.Convert(err).Message())
+		return nil, err
+	}
+
+	user := userFromAPI(res)
+	return &user, nil
+}
+
+func (s *client) SetDigest(ctx context.Context, u server.User) (*server.User, error) {
+	res, err := s.cli.SetDigest(ctx, userToAPI(u))
+	if err != nil {
+		err = fmt.Errorf(status.Convert(err).Message())
+		return nil, err
+	}
+
+	user := userFromAPI(res)
+	return &user, nil
+}
+
+func (s *client) SetCurrency(ctx context.Context, u server.User) (*server.User, error) {
+	res, err := s.cli.SetCurrency(ctx, userToAPI(u))
+	if err != nil {
+		err = fmt.Errorf(status.Convert(err).Message())
+		return nil, err
+	}
+
+	user := userFromAPI(res)
+	return &user, nil
+}
+
+func (s *client) Rates(ctx context.Context) (server.Rates, error) {
+	res, err := s.cli.Rates(ctx, &emptypb.Empty{})
+	if err != nil {
+		err = fmt.Errorf(status.Convert(err).Message())
+		return nil, err
+	}
+
+	return ratesFromAPI(res), nil
+}
+
+func (s *client) AdminStats(ctx context.Context) (*server.AdminStats, error) {
+	res, err := s.cli.AdminStats(ctx, &emptypb.Empty{})
+	if err != nil {
+		err = fmt.Errorf(status.Convert(err).Message())
+		return nil, err
+	}
+
+	stats := adminStatsFromAPI(res)
+	return &stats, nil
+}
+
+func (s *client) AdminUser(ctx context.Context, u server.User) (*server.AdminUser, error) {
+	res, err := s.cli.AdminUser(ctx, userToAPI(u))
+	if err != nil {
+		err = fmt.Errorf(status.Convert(err).Message())
+		return nil, err
+	}
+
+	user := adminUserFromAPI(res)
+	return &user, nil
+}
+
+func (s *client) AdminSetPlan(ctx context.Context, p server.UserPlan) (*server.User, error) {
+	res, err := s.cli.AdminSetPlan(ctx, userPlanToAPI(p))
+	if err != nil {
+		err = fmt.Errorf(status.Convert(err).Message())
+		return nil, err
+	}
+
+	user := userFromAPI(res)
+	return &user, nil
+}
+
+func (s *client) AdminSetSuperuser(ctx context.Context, u server.User) (*server.User, error) {
+	res, err := s.cli.AdminSetSuperuser(ctx, userToAPI(u))
+	if err != nil {
+		err = fmt.Errorf(status.Convert(err).Message())
+		return nil, err
+	}
+
+	user := userFromAPI(res)
+	return &user, nil
+}
+
+func (s *client) AdminBan(ctx context.Context, u server.User) (*server.User, error) {
+	res, err := s.cli.AdminBan(ctx, userToAPI(u))
+	if err != nil {
+		err = fmt.Errorf(status.Convert(err).Message())
+		return nil, err
+	}
+
+	user := userFromAPI(res)
+	return &user, nil
+}
+
+func (s *client) AdminRefresh(ctx context.Context) error {
+	_, err := s.cli.AdminRefresh(ctx, &emptypb.Empty{})
+	if err != nil {
+		err = fmt.Errorf(status.Convert(err).Message())
+		return err
+	}
+	return nil
+}
+
+func (s *client) SaveDelivery(ctx context.Context, d server.Delivery) error {
+	_, err := s.cli.SaveDelivery(ctx, deliveryToAPI(d))
+	if err != nil {
+		err = fmt.Errorf(status.Convert(err).Message())
+		return err
+	}
+	return nil
+}
+
+func (s *client) Notifications(ctx context.Context) (<-chan server.Notification, <-chan error, error) {
+	stream, err := s.cli.Notifications(ctx, &emptypb.Empty{})
+	if err != nil {
+		err = fmt.Errorf(status.Convert(err).Message())
+		return nil, nil, err
+	}
+
+	notificationCh := make(chan server.Notification)
+	errCh := make(chan error)
+
+	go func() {
+		for {
+			resp, err := stream.Recv()
+			if err != nil {
+				if ctx.Err() != nil || err == io.EOF {
+					return
+				}
+
+				errCh <- fmt.Errorf(status.Convert(err).Message())
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case notificationCh <- notificationFromAPI(resp):
+			}
+		}
+	}()
+
+	return notificationCh, errCh, nil
+}
+
 func (s *client) Apartments(ctx context.Context, f server.Filter) (<-chan server.Apartment, <-chan error, error) {
 	stream, err := s.cli.Apartments(ctx, filterToAPI(f))
 	if err != nil {
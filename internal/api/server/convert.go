@@ -24,6 +24,10 @@ func filterToAPI(in server.Filter) *api.Filter {
 		MaxArea:        in.MaxArea,
 		MaxDistance:    in.MaxDistance,
 		IsOwner:        in.IsOwner,
+		MinScore:       in.MinScore,
+		ShareToken:     in.ShareToken,
+		Subscribers:    in.Subscribers,
+		Currency:       string(in.Currency),
 
 		PauseTimestamp: in.PauseTimestamp,
 	}
@@ -58,6 +62,10 @@ func filterFromAPI(in *api.Filter) server.Filter {
 		MaxArea:        in.MaxArea,
 		MaxDistance:    in.MaxDistance,
 		IsOwner:        in.IsOwner,
+		MinScore:       in.MinScore,
+		ShareToken:     in.ShareToken,
+		Subscribers:    in.Subscribers,
+		Currency:       server.Currency(in.Currency),
 
 		PauseTimestamp: in.PauseTimestamp,
 	}
@@ -76,6 +84,22 @@ func filterFromAPI(in *api.Filter) server.Filter {
 	return out
 }
 
+func filterJoinToAPI(in server.FilterJoin) *api.JoinFilterReq {
+	return &api.JoinFilterReq{
+		UserId: in.User.ID,
+		Token:  in.Token,
+		Clone:  in.Clone,
+	}
+}
+
+func filterJoinFromAPI(in *api.JoinFilterReq) server.FilterJoin {
+	return server.FilterJoin{
+		User:  server.User{ID: in.UserId},
+		Token: in.Token,
+		Clone: in.Clone,
+	}
+}
+
 func apartmentToAPI(in server.Apartment) *api.Apartment {
 	out := &api.Apartment{
 		Id:             in.ID,
@@ -88,14 +112,19 @@ func apartmentToAPI(in server.Apartment) *api.Apartment {
 		Area:           in.Area,
 		Phone:          in.Phone,
 		District:       in.District,
+		Subdistrict:    in.Subdistrict,
 		City:           in.City,
 		Comment:        in.Comment,
 		OrderDate:      in.OrderDate.Format("2006-01-02"),
 		Url:            in.URL,
 		PhotoUrls:      in.PhotoURLs,
 		IsOwner:        in.IsOwner,
+		OriginalPrice:  in.OriginalPrice,
+		Currency:       string(in.Currency),
 
-		Filters: make([]*api.ApartmentFilter, 0, len(in.Filter)),
+		Filters:      make([]*api.ApartmentFilter, 0, len(in.Filter)),
+		Scores:       make([]*api.ApartmentScore, 0, len(in.Score)),
+		TraceContext: in.TraceContext,
 	}
 
 	if in.Coordinates != nil {
@@ -112,6 +141,18 @@ func apartmentToAPI(in server.Apartment) *api.Apartment {
 		})
 	}
 
+	if in.Estimate != nil {
+		out.Estimate = priceEstimateToAPI(*in.Estimate)
+	}
+
+	for uid, score := range in.Score {
+		out.Scores = append(out.Scores, &api.ApartmentScore{
+			UserId:  uid,
+			Value:   score.Value,
+			Reasons: score.Reasons,
+		})
+	}
+
 	return out
 }
 
@@ -127,13 +168,18 @@ func apartmentFromAPI(in *api.Apartment) server.Apartment {
 		Area:           in.Area,
 		Phone:          in.Phone,
 		District:       in.District,
+		Subdistrict:    in.Subdistrict,
 		City:           in.City,
 		Comment:        in.Comment,
 		URL:            in.Url,
 		PhotoURLs:      in.PhotoUrls,
 		IsOwner:        in.IsOwner,
+		OriginalPrice:  in.OriginalPrice,
+		Currency:       server.Currency(in.Currency),
 
-		Filter: make(map[int64][]string),
+		Filter:       make(map[int64][]string),
+		Score:        make(map[int64]server.Score),
+		TraceContext: in.TraceContext,
 	}
 
 	if in.Coordinates != nil {
@@ -148,5 +194,584 @@ func apartmentFromAPI(in *api.Apartment) server.Apartment {
 	for _, f := range in.Filters {
 		out.Filter[f.UserId] = f.FilterNames
 	}
+
+	if in.Estimate != nil {
+		estimate := priceEstimateFromAPI(in.Estimate)
+		out.Estimate = &estimate
+	}
+
+	for _, score := range in.Scores {
+		out.Score[score.UserId] = server.Score{
+			Value:   score.Value,
+			Reasons: score.Reasons,
+		}
+	}
+	return out
+}
+
+func statsFilterToAPI(in server.StatsFilter) *api.StatsReq {
+	return &api.StatsReq{
+		AdType:   in.AdType,
+		City:     in.City,
+		District: in.District,
+		Rooms:    in.Rooms,
+	}
+}
+
+func statsFilterFromAPI(in *api.StatsReq) server.StatsFilter {
+	return server.StatsFilter{
+		AdType:   in.AdType,
+		City:     in.City,
+		District: in.District,
+		Rooms:    in.Rooms,
+	}
+}
+
+func statsReportToAPI(in *server.StatsReport) *api.StatsRes {
+	out := &api.StatsRes{
+		Current: make([]*api.Stats, 0, len(in.Current)),
+		WeekAgo: make([]*api.Stats, 0, len(in.WeekAgo)),
+	}
+
+	for _, st := range in.Current {
+		out.Current = append(out.Current, statsToAPI(st))
+	}
+
+	for _, st := range in.WeekAgo {
+		out.WeekAgo = append(out.WeekAgo, statsToAPI(st))
+	}
+	return out
+}
+
+func statsReportFromAPI(in *api.StatsRes) *server.StatsReport {
+	out := &server.StatsReport{
+		Current: make([]server.Stats, 0, len(in.Current)),
+		WeekAgo: make([]server.Stats, 0, len(in.WeekAgo)),
+	}
+
+	for _, st := range in.Current {
+		out.Current = append(out.Current, statsFromAPI(st))
+	}
+
+	for _, st := range in.WeekAgo {
+		out.WeekAgo = append(out.WeekAgo, statsFromAPI(st))
+	}
+	return out
+}
+
+func statsToAPI(in server.Stats) *api.Stats {
+	return &api.Stats{
+		Date:                in.Date.Format("2006-01-02"),
+		AdType:              in.AdType,
+		City:                in.City,
+		District:            in.District,
+		Rooms:               in.Rooms,
+		Count:               in.Count,
+		P25Price:            in.P25Price,
+		MedianPrice:         in.MedianPrice,
+		P75Price:            in.P75Price,
+		MedianPricePerMeter: in.MedianPricePerMeter,
+		MedianDaysOnMarket:  in.MedianDaysOnMarket,
+	}
+}
+
+func statsFromAPI(in *api.Stats) server.Stats {
+	out := server.Stats{
+		AdType:              in.AdType,
+		City:                in.City,
+		District:            in.District,
+		Rooms:               in.Rooms,
+		Count:               in.Count,
+		P25Price:            in.P25Price,
+		MedianPrice:         in.MedianPrice,
+		P75Price:            in.P75Price,
+		MedianPricePerMeter: in.MedianPricePerMeter,
+		MedianDaysOnMarket:  in.MedianDaysOnMarket,
+	}
+
+	out.Date, _ = time.Parse("2006-01-02", in.Date)
+	return out
+}
+
+func priceEstimateToAPI(in server.PriceEstimate) *api.PriceEstimate {
+	return &api.PriceEstimate{
+		Price: in.Price,
+		Label: api.PriceLabel(in.Label),
+	}
+}
+
+func priceEstimateFromAPI(in *api.PriceEstimate) server.PriceEstimate {
+	return server.PriceEstimate{
+		Price: in.Price,
+		Label: server.PriceLabel(in.Label),
+	}
+}
+
+func apartmentMarkChangeToAPI(in server.ApartmentMarkChange) *api.ApartmentMarkChange {
+	out := &api.ApartmentMarkChange{
+		UserId:      in.UserID,
+		ApartmentId: in.ApartmentID,
+		IsSaved:     in.IsSaved,
+		IsHidden:    in.IsHidden,
+		Note:        in.Note,
+	}
+
+	if in.Status != nil {
+		st := int64(*in.Status)
+		out.Status = &st
+	}
+
+	if in.ViewingAt != nil {
+		var viewingAt int64
+		if !in.ViewingAt.IsZero() {
+			viewingAt = in.ViewingAt.Unix()
+		}
+		out.ViewingAt = &viewingAt
+	}
+
+	return out
+}
+
+func apartmentMarkChangeFromAPI(in *api.ApartmentMarkChange) server.ApartmentMarkChange {
+	out := server.ApartmentMarkChange{
+		UserID:      in.UserId,
+		ApartmentID: in.ApartmentId,
+		IsSaved:     in.IsSaved,
+		IsHidden:    in.IsHidden,
+		Note:        in.Note,
+	}
+
+	if in.Status != nil {
+		st := server.PipelineStatus(*in.Status)
+		out.Status = &st
+	}
+
+	if in.ViewingAt != nil {
+		var viewingAt time.Time
+		if *in.ViewingAt != 0 {
+			viewingAt = time.Unix(*in.ViewingAt, 0)
+		}
+		out.ViewingAt = &viewingAt
+	}
+
+	return out
+}
+
+func apartmentMarkToAPI(in server.ApartmentMark) *api.ApartmentMark {
+	out := &api.ApartmentMark{
+		UserId:      in.UserID,
+		ApartmentId: in.ApartmentID,
+		IsSaved:     in.IsSaved,
+		IsHidden:    in.IsHidden,
+		Note:        in.Note,
+		UpdatedAt:   in.UpdatedAt.Unix(),
+		IsAvailable: in.IsAvailable,
+		Status:      int64(in.Status),
+		IsReminded:  in.IsReminded,
+	}
+
+	if in.ViewingAt != nil {
+		out.ViewingAt = in.ViewingAt.Unix()
+	}
+
+	if in.Apartment != nil {
+		out.Apartment = apartmentToAPI(*in.Apartment)
+	}
+
+	return out
+}
+
+func apartmentMarkFromAPI(in *api.ApartmentMark) server.ApartmentMark {
+	out := server.ApartmentMark{
+		UserID:      in.UserId,
+		ApartmentID: in.ApartmentId,
+		IsSaved:     in.IsSaved,
+		IsHidden:    in.IsHidden,
+		Note:        in.Note,
+		UpdatedAt:   time.Unix(in.UpdatedAt, 0),
+		IsAvailable: in.IsAvailable,
+		Status:      server.PipelineStatus(in.Status),
+		IsReminded:  in.IsReminded,
+	}
+
+	if in.ViewingAt != 0 {
+		viewingAt := time.Unix(in.ViewingAt, 0)
+		out.ViewingAt = &viewingAt
+	}
+
+	if in.Apartment != nil {
+		a := apartmentFromAPI(in.Apartment)
+		out.Apartment = &a
+	}
+
+	return out
+}
+
+func apartmentSearchToAPI(in server.ApartmentSearch) *api.SearchReq {
+	return &api.SearchReq{
+		Filter: filterToAPI(in.Filter),
+		Offset: in.Offset,
+		Limit:  in.Limit,
+		Sort:   int64(in.Sort),
+	}
+}
+
+func apartmentSearchFromAPI(in *api.SearchReq) server.ApartmentSearch {
+	return server.ApartmentSearch{
+		Filter: filterFromAPI(in.Filter),
+		Offset: in.Offset,
+		Limit:  in.Limit,
+		Sort:   server.ApartmentSort(in.Sort),
+	}
+}
+
+func apartmentPageToAPI(in *server.ApartmentPage) *api.ApartmentPage {
+	out := &api.ApartmentPage{
+		Apartments: make([]*api.Apartment, 0, len(in.Apartments)),
+		NextOffset: in.NextOffset,
+	}
+
+	for _, a := range in.Apartments {
+		out.Apartments = append(out.Apartments, apartmentToAPI(a))
+	}
+	return out
+}
+
+func apartmentPageFromAPI(in *api.ApartmentPage) *server.ApartmentPage {
+	out := &server.ApartmentPage{
+		Apartments: make([]server.Apartment, 0, len(in.Apartments)),
+		NextOffset: in.NextOffset,
+	}
+
+	for _, a := range in.Apartments {
+		out.Apartments = append(out.Apartments, apartmentFromAPI(a))
+	}
+	return out
+}
+
+func apartmentBrowseToAPI(in server.ApartmentBrowse) *api.BrowseReq {
+	return &api.BrowseReq{
+		Filter: filterToAPI(in.Filter),
+		Sort:   int64(in.Sort),
+		Cursor: in.Cursor,
+	}
+}
+
+func apartmentBrowseFromAPI(in *api.BrowseReq) server.ApartmentBrowse {
+	return server.ApartmentBrowse{
+		Filter: filterFromAPI(in.Filter),
+		Sort:   server.ApartmentSort(in.Sort),
+		Cursor: in.Cursor,
+	}
+}
+
+func apartmentBrowsePageToAPI(in *server.ApartmentBrowsePage) *api.BrowsePage {
+	out := &api.BrowsePage{
+		Sort:       int64(in.Sort),
+		Position:   in.Position,
+		Total:      in.Total,
+		Cursor:     in.Cursor,
+		PrevCursor: in.PrevCursor,
+		NextCursor: in.NextCursor,
+	}
+
+	if in.Apartment != nil {
+		out.Apartment = apartmentToAPI(*in.Apartment)
+	}
+	return out
+}
+
+func apartmentBrowsePageFromAPI(in *api.BrowsePage) *server.ApartmentBrowsePage {
+	out := &server.ApartmentBrowsePage{
+		Sort:       server.ApartmentSort(in.Sort),
+		Position:   in.Position,
+		Total:      in.Total,
+		Cursor:     in.Cursor,
+		PrevCursor: in.PrevCursor,
+		NextCursor: in.NextCursor,
+	}
+
+	if in.Apartment != nil {
+		a := apartmentFromAPI(in.Apartment)
+		out.Apartment = &a
+	}
+	return out
+}
+
+func apartmentMarkListToAPI(in []server.ApartmentMark) *api.ApartmentMarkList {
+	out := &api.ApartmentMarkList{
+		Marks: make([]*api.ApartmentMark, 0, len(in)),
+	}
+
+	for _, m := range in {
+		out.Marks = append(out.Marks, apartmentMarkToAPI(m))
+	}
+	return out
+}
+
+func apartmentMarkListFromAPI(in *api.ApartmentMarkList) []server.ApartmentMark {
+	out := make([]server.ApartmentMark, 0, len(in.Marks))
+	for _, m := range in.Marks {
+		out = append(out, apartmentMarkFromAPI(m))
+	}
+	return out
+}
+
+func notificationToAPI(in server.Notification) *api.Notification {
+	out := &api.Notification{
+		Type: int64(in.Type),
+		Mark: apartmentMarkToAPI(in.Mark),
+	}
+
+	if in.Delivery != nil {
+		out.Delivery = deliveryToAPI(*in.Delivery)
+	}
+
+	if in.Provider != nil {
+		out.Provider = providerStateToAPI(*in.Provider)
+	}
+
+	for _, a := range in.Apartments {
+		out.Apartments = append(out.Apartments, apartmentToAPI(a))
+	}
+	return out
+}
+
+func notificationFromAPI(in *api.Notification) server.Notification {
+	out := server.Notification{
+		Type: server.NotificationType(in.Type),
+	}
+
+	if in.Mark != nil {
+		out.Mark = apartmentMarkFromAPI(in.Mark)
+	}
+
+	if in.Delivery != nil {
+		d := deliveryFromAPI(in.Delivery)
+		out.Delivery = &d
+	}
+
+	if in.Provider != nil {
+		p := providerStateFromAPI(in.Provider)
+		out.Provider = &p
+	}
+
+	for _, a := range in.Apartments {
+		out.Apartments = append(out.Apartments, apartmentFromAPI(a))
+	}
+	return out
+}
+
+func providerStateToAPI(in server.ProviderState) *api.ProviderState {
+	return &api.ProviderState{
+		Provider: in.Provider,
+		State:    int64(in.State),
+		Since:    in.Since.Unix(),
+		Err:      in.Err,
+	}
+}
+
+func providerStateFromAPI(in *api.ProviderState) server.ProviderState {
+	return server.ProviderState{
+		Provider: in.Provider,
+		State:    server.BreakerState(in.State),
+		Since:    time.Unix(in.Since, 0),
+		Err:      in.Err,
+	}
+}
+
+func deliveryToAPI(in server.Delivery) *api.Delivery {
+	return &api.Delivery{
+		UserId:      in.UserID,
+		ApartmentId: in.ApartmentID,
+		MessageId:   in.MessageID,
+		DeliveredAt: in.DeliveredAt.Unix(),
+	}
+}
+
+func deliveryFromAPI(in *api.Delivery) server.Delivery {
+	out := server.Delivery{
+		UserID:      in.UserId,
+		ApartmentID: in.ApartmentId,
+		MessageID:   in.MessageId,
+	}
+
+	if in.DeliveredAt != 0 {
+		out.DeliveredAt = time.Unix(in.DeliveredAt, 0)
+	}
+	return out
+}
+
+func userToAPI(in server.User) *api.User {
+	out := &api.User{
+		Id:             in.ID,
+		Plan:           in.Plan,
+		DigestInterval: int64(in.DigestInterval.Seconds()),
+		IsSuperuser:    in.IsSuperuser,
+		HistoryReplays: in.HistoryReplays,
+		IsBanned:       in.IsBanned,
+		IsBlocked:      in.IsBlocked,
+		Currency:       string(in.Currency),
+	}
+
+	if in.PlanExpiresAt != nil {
+		out.PlanExpiresAt = in.PlanExpiresAt.Unix()
+	}
+
+	if in.LastActivityAt != nil {
+		out.LastActivityAt = in.LastActivityAt.Unix()
+	}
+	return out
+}
+
+func userFromAPI(in *api.User) server.User {
+	out := server.User{
+		ID:             in.Id,
+		Plan:           in.Plan,
+		DigestInterval: time.Duration(in.DigestInterval) * time.Second,
+		IsSuperuser:    in.IsSuperuser,
+		HistoryReplays: in.HistoryReplays,
+		IsBanned:       in.IsBanned,
+		IsBlocked:      in.IsBlocked,
+		Currency:       server.Currency(in.Currency),
+	}
+
+	if in.PlanExpiresAt != 0 {
+		expiresAt := time.Unix(in.PlanExpiresAt, 0)
+		out.PlanExpiresAt = &expiresAt
+	}
+
+	if in.LastActivityAt != 0 {
+		lastActivityAt := time.Unix(in.LastActivityAt, 0)
+		out.LastActivityAt = &lastActivityAt
+	}
+	return out
+}
+
+func ratesToAPI(in server.Rates) *api.RatesRes {
+	out := &api.RatesRes{
+		Rates: make(map[string]float64, len(in)),
+	}
+
+	for c, rate := range in {
+		out.Rates[string(c)] = rate
+	}
+	return out
+}
+
+func ratesFromAPI(in *api.RatesRes) server.Rates {
+	out := make(server.Rates, len(in.Rates))
+	for c, rate := range in.Rates {
+		out[server.Currency(c)] = rate
+	}
+	return out
+}
+
+func planToAPI(in server.Plan) *api.Plan {
+	out := &api.Plan{
+		Name:              in.Name,
+		Price:             in.Price,
+		Period:            int64(in.Period.Seconds()),
+		MaxFilters:        in.MaxFilters,
+		MaxHistoryReplays: in.MaxHistoryReplays,
+		DeliveryDelay:     int64(in.DeliveryDelay.Seconds()),
+	}
+
+	for _, interval := range in.DigestIntervals {
+		out.DigestIntervals = append(out.DigestIntervals, int64(interval.Seconds()))
+	}
+	return out
+}
+
+func planFromAPI(in *api.Plan) server.Plan {
+	out := server.Plan{
+		Name:              in.Name,
+		Price:             in.Price,
+		Period:            time.Duration(in.Period) * time.Second,
+		MaxFilters:        in.MaxFilters,
+		MaxHistoryReplays: in.MaxHistoryReplays,
+		DeliveryDelay:     time.Duration(in.DeliveryDelay) * time.Second,
+	}
+
+	for _, interval := range in.DigestIntervals {
+		out.DigestIntervals = append(out.DigestIntervals, time.Duration(interval)*time.Second)
+	}
+	return out
+}
+
+func planListToAPI(in []server.Plan) *api.PlanList {
+	out := &api.PlanList{
+		Plans: make([]*api.Plan, 0, len(in)),
+	}
+
+	for _, p := range in {
+		out.Plans = append(out.Plans, planToAPI(p))
+	}
+	return out
+}
+
+func planListFromAPI(in *api.PlanList) []server.Plan {
+	out := make([]server.Plan, 0, len(in.Plans))
+	for _, p := range in.Plans {
+		out = append(out, planFromAPI(p))
+	}
+	return out
+}
+
+func userPlanToAPI(in server.UserPlan) *api.UserPlanReq {
+	return &api.UserPlanReq{
+		UserId: in.UserID,
+		Plan:   in.Plan,
+		Period: int64(in.Period.Seconds()),
+	}
+}
+
+func userPlanFromAPI(in *api.UserPlanReq) server.UserPlan {
+	return server.UserPlan{
+		UserID: in.UserId,
+		Plan:   in.Plan,
+		Period: time.Duration(in.Period) * time.Second,
+	}
+}
+
+func adminStatsToAPI(in server.AdminStats) *api.AdminStatsRes {
+	return &api.AdminStatsRes{
+		Users:             in.Users,
+		Filters:           in.Filters,
+		ActiveSubscribers: in.ActiveSubscribers,
+		Apartments:        in.Apartments,
+	}
+}
+
+func adminStatsFromAPI(in *api.AdminStatsRes) server.AdminStats {
+	return server.AdminStats{
+		Users:             in.Users,
+		Filters:           in.Filters,
+		ActiveSubscribers: in.ActiveSubscribers,
+		Apartments:        in.Apartments,
+	}
+}
+
+func adminUserToAPI(in server.AdminUser) *api.AdminUserRes {
+	out := &api.AdminUserRes{
+		User:    userToAPI(in.User),
+		Filters: make([]*api.Filter, 0, len(in.Filters)),
+	}
+
+	for _, f := range in.Filters {
+		out.Filters = append(out.Filters, filterToAPI(f))
+	}
+	return out
+}
+
+func adminUserFromAPI(in *api.AdminUserRes) server.AdminUser {
+	out := server.AdminUser{
+		User:    userFromAPI(in.User),
+		Filters: make([]server.Filter, 0, len(in.Filters)),
+	}
+
+	for _, f := range in.Filters {
+		out.Filters = append(out.Filters, filterFromAPI(f))
+	}
 	return out
 }
@@ -4,6 +4,8 @@ import (
 	"context"
 	"net"
 
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/attribute"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 	"google.golang.org/protobuf/types/known/emptypb"
@@ -11,6 +13,7 @@ import (
 	"github.com/irbgeo/apartment-bot/internal/api/middleware"
 	api "github.com/irbgeo/apartment-bot/internal/api/server/proto"
 	"github.com/irbgeo/apartment-bot/internal/server"
+	"github.com/irbgeo/apartment-bot/internal/tracing"
 	"github.com/irbgeo/apartment-bot/internal/utils"
 )
 
@@ -29,11 +32,34 @@ type serverSvc interface {
 	Apartments(ctx context.Context, f server.Filter) (<-chan server.Apartment, error)
 	Subscribe(ctx context.Context) <-chan server.Apartment
 	Unsubscribe(ctx context.Context)
+	Stats(ctx context.Context, f server.StatsFilter) (*server.StatsReport, error)
+	MarkApartment(ctx context.Context, c server.ApartmentMarkChange) (*server.ApartmentMark, error)
+	SearchApartments(ctx context.Context, q server.ApartmentSearch) (*server.ApartmentPage, error)
+	BrowseApartments(ctx context.Context, b server.ApartmentBrowse) (*server.ApartmentBrowsePage, error)
+	SavedApartments(ctx context.Context, u server.User) ([]server.ApartmentMark, error)
+	Viewings(ctx context.Context, u server.User) ([]server.ApartmentMark, error)
+	SubscribeNotifications(ctx context.Context) <-chan server.Notification
+	UnsubscribeNotifications(ctx context.Context)
+	SaveDelivery(ctx context.Context, d server.Delivery) error
+	ShareFilter(ctx context.Context, f server.Filter) (string, error)
+	JoinFilter(ctx context.Context, j server.FilterJoin) (*server.Filter, error)
+	Plans(ctx context.Context) []server.Plan
+	UserInfo(ctx context.Context, u server.User) (*server.User, error)
+	SetUserPlan(ctx context.Context, p server.UserPlan) (*server.User, error)
+	SetDigest(ctx context.Context, u server.User) (*server.User, error)
+	SetCurrency(ctx context.Context, u server.User) (*server.User, error)
+	Rates(ctx context.Context) server.Rates
+	AdminStats(ctx context.Context) (*server.AdminStats, error)
+	AdminUser(ctx context.Context, u server.User) (*server.AdminUser, error)
+	AdminSetSuperuser(ctx context.Context, u server.User) (*server.User, error)
+	AdminBan(ctx context.Context, u server.User) (*server.User, error)
+	AdminRefresh(ctx context.Context) error
 }
 
 func ListenAndServe(
 	addr string,
 	authToken string,
+	adminToken string,
 	svc serverSvc,
 ) error {
 	l, err := net.Listen("tcp", addr)
@@ -46,8 +72,9 @@ func ListenAndServe(
 	}
 
 	s := grpc.NewServer(
-		grpc.UnaryInterceptor(middleware.CheckMetadataUnaryInterceptor(authToken)),
-		grpc.StreamInterceptor(middleware.CheckMetadataStreamInterceptor(authToken)),
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.UnaryInterceptor(middleware.CheckMetadataUnaryInterceptor(authToken, adminToken)),
+		grpc.StreamInterceptor(middleware.CheckMetadataStreamInterceptor(authToken, adminToken)),
 	)
 
 	api.RegisterServerServer(s, srv)
@@ -100,6 +127,119 @@ func (s *srv) DeleteFilter(ctx context.Context, in *api.Filter) (*emptypb.Empty,
 	return &emptypb.Empty{}, err
 }
 
+func (s *srv) ShareFilter(ctx context.Context, in *api.Filter) (*api.ShareFilterRes, error) {
+	token, err := s.svc.ShareFilter(ctx, filterFromAPI(in))
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.ShareFilterRes{Token: token}, nil
+}
+
+func (s *srv) JoinFilter(ctx context.Context, in *api.JoinFilterReq) (*api.Filter, error) {
+	f, err := s.svc.JoinFilter(ctx, filterJoinFromAPI(in))
+	if err != nil {
+		return nil, err
+	}
+
+	return filterToAPI(*f), nil
+}
+
+func (s *srv) Plans(ctx context.Context, _ *emptypb.Empty) (*api.PlanList, error) {
+	return planListToAPI(s.svc.Plans(ctx)), nil
+}
+
+func (s *srv) UserInfo(ctx context.Context, in *api.User) (*api.User, error) {
+	u, err := s.svc.UserInfo(ctx, userFromAPI(in))
+	if err != nil {
+		return nil, err
+	}
+
+	return userToAPI(*u), nil
+}
+
+func (s *srv) SetUserPlan(ctx context.Context, in *api.UserPlanReq) (*api.User, error) {
+	u, err := s.svc.SetUserPlan(ctx, userPlanFromAPI(in))
+	if err != nil {
+		return nil, err
+	}
+
+	return userToAPI(*u), nil
+}
+
+func (s *srv) SetDigest(ctx context.Context, in *api.User) (*api.User, error) {
+	u, err := s.svc.SetDigest(ctx, userFromAPI(in))
+	if err != nil {
+		return nil, err
+	}
+
+	return userToAPI(*u), nil
+}
+
+func (s *srv) SetCurrency(ctx context.Context, in *api.User) (*api.User, error) {
+	u, err := s.svc.SetCurrency(ctx, userFromAPI(in))
+	if err != nil {
+		return nil, err
+	}
+
+	return userToAPI(*u), nil
+}
+
+func (s *srv) Rates(ctx context.Context, _ *emptypb.Empty) (*api.RatesRes, error) {
+	return ratesToAPI(s.svc.Rates(ctx)), nil
+}
+
+func (s *srv) AdminStats(ctx context.Context, _ *emptypb.Empty) (*api.AdminStatsRes, error) {
+	stats, err := s.svc.AdminStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return adminStatsToAPI(*stats), nil
+}
+
+func (s *srv) AdminUser(ctx context.Context, in *api.User) (*api.AdminUserRes, error) {
+	u, err := s.svc.AdminUser(ctx, userFromAPI(in))
+	if err != nil {
+		return nil, err
+	}
+
+	return adminUserToAPI(*u), nil
+}
+
+// AdminSetPlan grants the plan by the admin, the payments activate the plans by SetUserPlan.
+func (s *srv) AdminSetPlan(ctx context.Context, in *api.UserPlanReq) (*api.User, error) {
+	u, err := s.svc.SetUserPlan(ctx, userPlanFromAPI(in))
+	if err != nil {
+		return nil, err
+	}
+
+	return userToAPI(*u), nil
+}
+
+func (s *srv) AdminSetSuperuser(ctx context.Context, in *api.User) (*api.User, error) {
+	u, err := s.svc.AdminSetSuperuser(ctx, userFromAPI(in))
+	if err != nil {
+		return nil, err
+	}
+
+	return userToAPI(*u), nil
+}
+
+func (s *srv) AdminBan(ctx context.Context, in *api.User) (*api.User, error) {
+	u, err := s.svc.AdminBan(ctx, userFromAPI(in))
+	if err != nil {
+		return nil, err
+	}
+
+	return userToAPI(*u), nil
+}
+
+func (s *srv) AdminRefresh(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	err := s.svc.AdminRefresh(ctx)
+	return &emptypb.Empty{}, err
+}
+
 func (s *srv) ConnectUser(ctx context.Context, in *api.User) (*emptypb.Empty, error) {
 	err := s.svc.ConnectUser(ctx, server.User{ID: in.Id})
 	return &emptypb.Empty{}, err
@@ -140,6 +280,86 @@ func (s *srv) Cities(ctx context.Context, _ *emptypb.Empty) (*api.City, error) {
 	return r, nil
 }
 
+func (s *srv) Stats(ctx context.Context, in *api.StatsReq) (*api.StatsRes, error) {
+	report, err := s.svc.Stats(ctx, statsFilterFromAPI(in))
+	if err != nil {
+		return nil, err
+	}
+
+	return statsReportToAPI(report), nil
+}
+
+func (s *srv) MarkApartment(ctx context.Context, in *api.ApartmentMarkChange) (*api.ApartmentMark, error) {
+	mark, err := s.svc.MarkApartment(ctx, apartmentMarkChangeFromAPI(in))
+	if err != nil {
+		return nil, err
+	}
+
+	return apartmentMarkToAPI(*mark), nil
+}
+
+func (s *srv) SearchApartments(ctx context.Context, in *api.SearchReq) (*api.ApartmentPage, error) {
+	page, err := s.svc.SearchApartments(ctx, apartmentSearchFromAPI(in))
+	if err != nil {
+		return nil, err
+	}
+
+	return apartmentPageToAPI(page), nil
+}
+
+func (s *srv) BrowseApartments(ctx context.Context, in *api.BrowseReq) (*api.BrowsePage, error) {
+	page, err := s.svc.BrowseApartments(ctx, apartmentBrowseFromAPI(in))
+	if err != nil {
+		return nil, err
+	}
+
+	return apartmentBrowsePageToAPI(page), nil
+}
+
+func (s *srv) SavedApartments(ctx context.Context, in *api.User) (*api.ApartmentMarkList, error) {
+	marks, err := s.svc.SavedApartments(ctx, server.User{ID: in.Id})
+	if err != nil {
+		return nil, err
+	}
+
+	return apartmentMarkListToAPI(marks), nil
+}
+
+func (s *srv) Viewings(ctx context.Context, in *api.User) (*api.ApartmentMarkList, error) {
+	marks, err := s.svc.Viewings(ctx, server.User{ID: in.Id})
+	if err != nil {
+		return nil, err
+	}
+
+	return apartmentMarkListToAPI(marks), nil
+}
+
+func (s *srv) SaveDelivery(ctx context.Context, in *api.Delivery) (*emptypb.Empty, error) {
+	err := s.svc.SaveDelivery(ctx, deliveryFromAPI(in))
+	return &emptypb.Empty{}, err
+}
+
+func (s *srv) Notifications(req *emptypb.Empty, srv api.Server_NotificationsServer) error {
+	ctx := utils.PackVar(srv.Context(), utils.IDKey, middleware.GetID(srv.Context()))
+
+	notificationCh := s.svc.SubscribeNotifications(ctx)
+	defer s.svc.UnsubscribeNotifications(ctx)
+	for {
+		select {
+		case <-srv.Context().Done():
+			return nil
+		case n, ok := <-notificationCh:
+			if !ok {
+				return nil
+			}
+
+			if err := srv.Send(notificationToAPI(n)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
 func (s *srv) Apartments(req *api.Filter, srv api.Server_ApartmentsServer) error {
 	apartmentCh, err := s.svc.Apartments(srv.Context(), filterFromAPI(req))
 	if err != nil {
@@ -176,9 +396,22 @@ func (s *srv) Connect(req *emptypb.Empty, srv api.Server_ConnectServer) error {
 				return nil
 			}
 
-			if err := srv.Send(apartmentToAPI(a)); err != nil {
+			if err := sendApartment(ctx, srv, a); err != nil {
 				return err
 			}
 		}
 	}
 }
+
+func sendApartment(ctx context.Context, srv api.Server_ConnectServer, a server.Apartment) error {
+	ctx, span := tracing.Start(tracing.Extract(ctx, a.TraceContext), "api.Connect", attribute.Int64("apartment.id", a.ID))
+	defer span.End()
+
+	a.TraceContext = tracing.Inject(ctx)
+
+	if err := srv.Send(apartmentToAPI(a)); err != nil {
+		tracing.Error(span, err)
+		return err
+	}
+	return nil
+}
@@ -78,6 +78,8 @@ type ChangeFilterPriceInfo struct {
 	IsMinChange  bool
 	NewMinPrice  *float64
 	NewMaxPrice  *float64
+	// Currency is the currency of the new price, the other price is converted to it
+	Currency server.Currency
 }
 
 func (s *ChangeFilterPriceInfo) SetActiveFilter(f *server.Filter) {
@@ -153,6 +155,20 @@ func (s *ChangeFilterMaxDistanceInfo) GetUserID() int64 {
 	return s.User.ID
 }
 
+type ChangeFilterMinScoreInfo struct {
+	User         *server.User
+	ActiveFilter *server.Filter
+	NewMinScore  *float64
+}
+
+func (s *ChangeFilterMinScoreInfo) SetActiveFilter(f *server.Filter) {
+	s.ActiveFilter = f
+}
+
+func (s *ChangeFilterMinScoreInfo) GetUserID() int64 {
+	return s.User.ID
+}
+
 type ChangeStateFilterInfo struct {
 	User         *server.User
 	ActiveFilter *server.Filter
@@ -77,6 +77,14 @@ func (s *service) ChangeFilterDistrict(ctx context.Context, i *ChangeFilterDistr
 func (s *service) ChangeFilterPrice(ctx context.Context, i *ChangeFilterPriceInfo) (*server.Filter, error) {
 	i.ActiveFilter.IsUpdate = true
 
+	if i.Currency != "" {
+		f, ok := i.ActiveFilter.InCurrency(s.Rates(), i.Currency)
+		if !ok {
+			return nil, errUnknownRate
+		}
+		*i.ActiveFilter = f
+	}
+
 	if i.IsMinChange {
 		i.ActiveFilter.MinPrice = i.NewMinPrice
 	} else {
@@ -143,6 +151,14 @@ func (s *service) ChangeFilterMaxDistance(ctx context.Context, i *ChangeFilterMa
 	return i.ActiveFilter, nil
 }
 
+func (s *service) ChangeFilterMinScore(ctx context.Context, i *ChangeFilterMinScoreInfo) (*server.Filter, error) {
+	i.ActiveFilter.IsUpdate = true
+
+	i.ActiveFilter.MinScore = i.NewMinScore
+
+	return i.ActiveFilter, nil
+}
+
 func (s *service) ChangeStateFilter(ctx context.Context, i *ChangeStateFilterInfo) (*server.Filter, error) {
 	i.ActiveFilter.IsUpdate = true
 
@@ -19,6 +19,9 @@ var (
 	errMinPriceMoreThanMaxPrice = errors.New("min price more than max price")
 	errMinRoomsMoreThanMaxRooms = errors.New("min rooms more than max rooms")
 	errMinAreaMoreThanMaxArea   = errors.New("min area more than max area")
+	errInvalidMinScore          = errors.New("min score must be between 0 and 100")
+	errFilterNotParsed          = errors.New("no filter parameters found in the text")
+	errUnknownRate              = errors.New("the currency rates aren't loaded yet, try later")
 )
 
 func (s *service) FloodErrorHandler(ctx context.Context, u *server.User, retryAt time.Duration) {
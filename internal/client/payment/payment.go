@@ -0,0 +1,35 @@
+package payment
+
+import (
+	"errors"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+const (
+	TelegramProvider = "telegram"
+	FakeProvider     = "fake"
+)
+
+var (
+	errUnknownProvider = errors.New("unknown payment provider")
+)
+
+// Provider takes payments for the plans.
+type Provider interface {
+	SetBot(bot *tele.Bot)
+	SendInvoice(i Invoice) error
+	Receipts() <-chan Receipt
+	Currency() string
+}
+
+// New returns the payment provider by name.
+func New(provider, token, currency string) (Provider, error) {
+	switch provider {
+	case TelegramProvider:
+		return NewTelegram(token, currency), nil
+	case FakeProvider:
+		return NewFake(), nil
+	}
+	return nil, errUnknownProvider
+}
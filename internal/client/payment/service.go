@@ -0,0 +1,112 @@
+package payment
+
+import (
+	"log/slog"
+	"strconv"
+	"strings"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+const (
+	// StarsCurrency is Telegram Stars, the invoices don't need a provider token
+	StarsCurrency = "XTR"
+
+	payloadSeparator = ":"
+)
+
+// telegram takes payments with Telegram Payments.
+type telegram struct {
+	bot       *tele.Bot
+	token     string
+	currency  string
+	receiptCh chan Receipt
+}
+
+// NewTelegram returns the provider of Telegram Payments, the empty currency means Telegram Stars.
+func NewTelegram(token, currency string) *telegram {
+	if currency == "" {
+		currency = StarsCurrency
+	}
+
+	return &telegram{
+		token:     token,
+		currency:  currency,
+		receiptCh: make(chan Receipt, 1),
+	}
+}
+
+func (s *telegram) SetBot(bot *tele.Bot) {
+	s.bot = bot
+	s.bot.Handle(tele.OnCheckout, s.checkoutHandler)
+	s.bot.Handle(tele.OnPayment, s.paymentHandler)
+}
+
+func (s *telegram) SendInvoice(i Invoice) error {
+	invoice := &tele.Invoice{
+		Title:       i.Title,
+		Description: i.Description,
+		Payload:     invoicePayload(i.Plan, i.ChatID),
+		Currency:    s.currency,
+		Token:       s.token,
+		Prices: []tele.Price{
+			{Label: i.Title, Amount: int(i.Amount)},
+		},
+	}
+
+	_, err := s.bot.Send(tele.ChatID(i.ChatID), invoice)
+	return err
+}
+
+func (s *telegram) Receipts() <-chan Receipt {
+	return s.receiptCh
+}
+
+func (s *telegram) Currency() string {
+	return s.currency
+}
+
+// checkoutHandler confirms the invoices of the bot.
+func (s *telegram) checkoutHandler(c tele.Context) error {
+	if _, _, ok := parseInvoicePayload(c.PreCheckoutQuery().Payload); !ok {
+		return c.Accept("unknown invoice")
+	}
+	return c.Accept()
+}
+
+func (s *telegram) paymentHandler(c tele.Context) error {
+	p := c.Message().Payment
+
+	plan, chatID, ok := parseInvoicePayload(p.Payload)
+	if !ok {
+		slog.Error("unknown payment", "payload", p.Payload, "charge_id", p.TelegramChargeID)
+		return nil
+	}
+
+	s.receiptCh <- Receipt{
+		ChatID:   chatID,
+		Plan:     plan,
+		Amount:   int64(p.Total),
+		Currency: p.Currency,
+		ChargeID: p.TelegramChargeID,
+	}
+	return nil
+}
+
+// invoicePayload keeps the chat that receives the plan, the invoice can be paid in another chat.
+func invoicePayload(plan string, chatID int64) string {
+	return plan + payloadSeparator + strconv.FormatInt(chatID, 10)
+}
+
+func parseInvoicePayload(payload string) (string, int64, bool) {
+	plan, id, ok := strings.Cut(payload, payloadSeparator)
+	if !ok || plan == "" {
+		return "", 0, false
+	}
+
+	chatID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return plan, chatID, true
+}
@@ -0,0 +1,68 @@
+package payment
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvoicePayload(t *testing.T) {
+	testCases := []struct {
+		testCaseName   string
+		payload        string
+		expectedPlan   string
+		expectedChatID int64
+		expectedOk     bool
+	}{
+		{
+			testCaseName:   "user",
+			payload:        invoicePayload("plus", 42),
+			expectedPlan:   "plus",
+			expectedChatID: 42,
+			expectedOk:     true,
+		},
+		{
+			testCaseName:   "group chat",
+			payload:        invoicePayload("pro", -100123),
+			expectedPlan:   "pro",
+			expectedChatID: -100123,
+			expectedOk:     true,
+		},
+		{
+			testCaseName: "without chat",
+			payload:      "plus",
+		},
+		{
+			testCaseName: "invalid chat",
+			payload:      "plus:abc",
+		},
+		{
+			testCaseName: "without plan",
+			payload:      ":42",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCaseName, func(t *testing.T) {
+			plan, chatID, ok := parseInvoicePayload(tc.payload)
+			require.Equal(t, tc.expectedOk, ok)
+			require.Equal(t, tc.expectedPlan, plan)
+			require.Equal(t, tc.expectedChatID, chatID)
+		})
+	}
+}
+
+func TestFake(t *testing.T) {
+	s := NewFake()
+
+	err := s.SendInvoice(Invoice{ChatID: 42, Plan: "plus", Amount: 100})
+	require.NoError(t, err)
+
+	require.Equal(t, Receipt{
+		ChatID:   42,
+		Plan:     "plus",
+		Amount:   100,
+		Currency: StarsCurrency,
+		ChargeID: "fake-1",
+	}, <-s.Receipts())
+}
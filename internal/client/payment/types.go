@@ -0,0 +1,19 @@
+package payment
+
+// Invoice requests the payment of the plan in the chat.
+type Invoice struct {
+	ChatID      int64
+	Plan        string
+	Title       string
+	Description string
+	Amount      int64
+}
+
+// Receipt confirms the payment of the plan.
+type Receipt struct {
+	ChatID   int64
+	Plan     string
+	Amount   int64
+	Currency string
+	ChargeID string
+}
@@ -6,17 +6,26 @@ import (
 	"sync/atomic"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/irbgeo/apartment-bot/internal/client/textfilter"
 	"github.com/irbgeo/apartment-bot/internal/server"
+	"github.com/irbgeo/apartment-bot/internal/tracing"
 )
 
 const (
 	updateCityInterval           = time.Hour
+	updateRatesInterval          = time.Hour
 	checkTurnedOffFilterInterval = time.Hour
 	turnedOffFilterTime          = 30 * time.Minute
 )
 
 var (
 	onceClientFlag int64
+
+	// reconnectBaseDelay doubles after every failed reconnection up to maxReconnectDelay
+	reconnectBaseDelay = time.Second
+	maxReconnectDelay  = time.Minute
 )
 
 type service struct {
@@ -35,8 +44,29 @@ type srv interface {
 	ConnectUser(context.Context, server.User) error
 	DisconnectUser(context.Context, server.User) error
 	Cities(ctx context.Context) (map[string][]string, error)
-	Apartments(ctx context.Context, f server.Filter) (<-chan server.Apartment, <-chan error, error)
 	Connect(context.Context) (<-chan server.Apartment, <-chan error, error)
+	Stats(ctx context.Context, f server.StatsFilter) (*server.StatsReport, error)
+	MarkApartment(ctx context.Context, c server.ApartmentMarkChange) (*server.ApartmentMark, error)
+	SearchApartments(ctx context.Context, q server.ApartmentSearch) (*server.ApartmentPage, error)
+	BrowseApartments(ctx context.Context, b server.ApartmentBrowse) (*server.ApartmentBrowsePage, error)
+	SavedApartments(ctx context.Context, u server.User) ([]server.ApartmentMark, error)
+	Viewings(ctx context.Context, u server.User) ([]server.ApartmentMark, error)
+	Notifications(ctx context.Context) (<-chan server.Notification, <-chan error, error)
+	SaveDelivery(ctx context.Context, d server.Delivery) error
+	ShareFilter(ctx context.Context, f server.Filter) (string, error)
+	JoinFilter(ctx context.Context, j server.FilterJoin) (*server.Filter, error)
+	Plans(ctx context.Context) ([]server.Plan, error)
+	UserInfo(ctx context.Context, u server.User) (*server.User, error)
+	SetUserPlan(ctx context.Context, p server.UserPlan) (*server.User, error)
+	SetDigest(ctx context.Context, u server.User) (*server.User, error)
+	SetCurrency(ctx context.Context, u server.User) (*server.User, error)
+	Rates(ctx context.Context) (server.Rates, error)
+	AdminStats(ctx context.Context) (*server.AdminStats, error)
+	AdminUser(ctx context.Context, u server.User) (*server.AdminUser, error)
+	AdminSetPlan(ctx context.Context, p server.UserPlan) (*server.User, error)
+	AdminSetSuperuser(ctx context.Context, u server.User) (*server.User, error)
+	AdminBan(ctx context.Context, u server.User) (*server.User, error)
+	AdminRefresh(ctx context.Context) error
 }
 
 func NewService(srv srv, firstCities []string) (*service, error) {
@@ -50,7 +80,8 @@ func NewService(srv srv, firstCities []string) (*service, error) {
 		cancel: cancel,
 		srv:    srv,
 		channels: channels{
-			apartment: make(chan server.Apartment),
+			apartment:    make(chan server.Apartment),
+			notification: make(chan server.Notification),
 		},
 		storage: storage{
 			turnedOff: turnedOffStorage{
@@ -74,10 +105,19 @@ func (s *service) Start() error {
 
 	go s.handleApartments(apartmentCh, errCh) // nolint: errcheck
 
+	notificationCh, notificationErrCh, err := s.srv.Notifications(s.ctx)
+	if err != nil {
+		return err
+	}
+
+	go s.receiveNotifications(notificationCh, notificationErrCh)
+
 	if err := s.startUpdatingCity(); err != nil {
 		return err
 	}
 
+	s.startUpdatingRates()
+
 	if err := s.startCheckTurnedOffFilters(); err != nil {
 		return err
 	}
@@ -88,6 +128,7 @@ func (s *service) Start() error {
 func (s *service) Stop() {
 	s.cancel()
 	close(s.channels.apartment)
+	close(s.channels.notification)
 	atomic.StoreInt64(&onceClientFlag, 0)
 }
 
@@ -95,6 +136,10 @@ func (s *service) Watcher() <-chan server.Apartment {
 	return s.channels.apartment
 }
 
+func (s *service) NotificationWatcher() <-chan server.Notification {
+	return s.channels.notification
+}
+
 func (s *service) StartChat(ctx context.Context, u *server.User) error {
 	s.storage.disconnectedUsers.Delete(u.ID)
 	return s.srv.ConnectUser(ctx, *u)
@@ -136,6 +181,34 @@ func (s *service) StartCreatingFilter(ctx context.Context, u *server.User) *serv
 	return filter
 }
 
+// ParseFilter starts creating the filter parsed from the text,
+// it returns the words which weren't parsed.
+func (s *service) ParseFilter(ctx context.Context, u *server.User, text string) (*server.Filter, []string, error) {
+	result := textfilter.Parse(text, s.availableDistricts())
+	if result.IsEmpty() {
+		return nil, result.Unparsed, errFilterNotParsed
+	}
+
+	filter := &result.Filter
+	filter.IsUpdate = true
+	filter.User = &server.User{
+		ID: u.ID,
+	}
+	if filter.AdType == nil {
+		defaultAdType := server.RentAdType
+		filter.AdType = &defaultAdType
+	}
+	if filter.Coordinates != nil && filter.MaxDistance == nil {
+		filter.MaxDistance = &defaultDistanceToLocation
+	}
+	if filter.Currency == "" && (filter.MinPrice != nil || filter.MaxPrice != nil) {
+		filter.Currency = s.Currency(ctx, u.ID)
+	}
+
+	s.storage.active.Store(u.ID, filter)
+	return filter, result.Unparsed, nil
+}
+
 func (s *service) CancelCreatingFilter(_ context.Context, u *server.User) {
 	s.storage.active.Delete(u.ID)
 }
@@ -171,21 +244,6 @@ func (s *service) handleFilterStatus(f *server.Filter) {
 	}
 }
 
-func (s *service) Apartments(ctx context.Context, f *server.Filter) error {
-	s.StopReceiveHistoryFilter(f.ID)
-
-	aCtx, cancel := context.WithCancel(ctx)
-	s.storage.historyReceiving.Store(f.ID, cancel)
-
-	apartmentCh, errCh, err := s.srv.Apartments(aCtx, *f)
-	if err != nil {
-		s.StopReceiveHistoryFilter(f.ID)
-		return err
-	}
-
-	return s.handleApartments(apartmentCh, errCh)
-}
-
 func (s *service) DeleteFilter(ctx context.Context, f *server.Filter) error {
 	if len(f.ID) == 0 {
 		s.storage.active.Delete(f.User.ID)
@@ -203,6 +261,141 @@ func (s *service) DeleteFilter(ctx context.Context, f *server.Filter) error {
 	return nil
 }
 
+func (s *service) ShareFilter(ctx context.Context, f *server.Filter) (string, error) {
+	return s.srv.ShareFilter(ctx, *f)
+}
+
+func (s *service) JoinFilter(ctx context.Context, j server.FilterJoin) (*server.Filter, error) {
+	f, err := s.srv.JoinFilter(ctx, j)
+	if err != nil {
+		return nil, err
+	}
+
+	s.storage.disconnectedUsers.Delete(j.User.ID)
+	return f, nil
+}
+
+func (s *service) Plans(ctx context.Context) ([]server.Plan, error) {
+	return s.srv.Plans(ctx)
+}
+
+func (s *service) UserInfo(ctx context.Context, u *server.User) (*server.User, error) {
+	return s.srv.UserInfo(ctx, *u)
+}
+
+func (s *service) SetUserPlan(ctx context.Context, p server.UserPlan) (*server.User, error) {
+	return s.srv.SetUserPlan(ctx, p)
+}
+
+func (s *service) SetDigest(ctx context.Context, u *server.User) (*server.User, error) {
+	return s.srv.SetDigest(ctx, *u)
+}
+
+func (s *service) SetCurrency(ctx context.Context, u *server.User) (*server.User, error) {
+	user, err := s.srv.SetCurrency(ctx, *u)
+	if err != nil {
+		return nil, err
+	}
+
+	s.storage.currencies.Store(user.ID, user.Currency.OrUSD())
+	return user, nil
+}
+
+// Currency returns the currency of the user, it's USD when the user isn't loaded.
+func (s *service) Currency(ctx context.Context, userID int64) server.Currency {
+	if c, ok := s.storage.currencies.Load(userID); ok {
+		return c.(server.Currency) // nolint: errcheck
+	}
+
+	user, err := s.srv.UserInfo(ctx, server.User{ID: userID})
+	if err != nil {
+		slog.Error("get user currency", "user_id", userID, "err", err)
+		return server.USD
+	}
+
+	s.storage.currencies.Store(userID, user.Currency.OrUSD())
+	return user.Currency.OrUSD()
+}
+
+// Rates returns the last currency rates for 1 USD.
+func (s *service) Rates() server.Rates {
+	s.storage.rates.RLock()
+	defer s.storage.rates.RUnlock()
+
+	return s.storage.rates.rates
+}
+
+func (s *service) AdminStats(ctx context.Context) (*server.AdminStats, error) {
+	return s.srv.AdminStats(ctx)
+}
+
+func (s *service) AdminUser(ctx context.Context, u *server.User) (*server.AdminUser, error) {
+	return s.srv.AdminUser(ctx, *u)
+}
+
+func (s *service) AdminSetPlan(ctx context.Context, p server.UserPlan) (*server.User, error) {
+	return s.srv.AdminSetPlan(ctx, p)
+}
+
+func (s *service) AdminSetSuperuser(ctx context.Context, u *server.User) (*server.User, error) {
+	return s.srv.AdminSetSuperuser(ctx, *u)
+}
+
+// AdminBan bans the user, the history of the user is stopped.
+func (s *service) AdminBan(ctx context.Context, u *server.User) (*server.User, error) {
+	if u.IsBanned {
+		filters, _ := s.srv.Filters(ctx, *u)
+		for _, f := range filters {
+			s.StopReceiveHistoryFilter(f.ID)
+		}
+	}
+	return s.srv.AdminBan(ctx, *u)
+}
+
+func (s *service) AdminRefresh(ctx context.Context) error {
+	return s.srv.AdminRefresh(ctx)
+}
+
+func (s *service) Stats(ctx context.Context, f server.StatsFilter) (*server.StatsReport, error) {
+	return s.srv.Stats(ctx, f)
+}
+
+func (s *service) MarkApartment(ctx context.Context, c server.ApartmentMarkChange) (*server.ApartmentMark, error) {
+	return s.srv.MarkApartment(ctx, c)
+}
+
+// SearchApartments returns the page of the apartments matching the text query.
+func (s *service) SearchApartments(ctx context.Context, u *server.User, query string, offset int64) (*server.ApartmentPage, error) {
+	f := textfilter.Parse(query, s.availableDistricts()).Filter
+	f.User = &server.User{
+		ID: u.ID,
+	}
+	if f.Currency == "" && (f.MinPrice != nil || f.MaxPrice != nil) {
+		f.Currency = s.Currency(ctx, u.ID)
+	}
+
+	return s.srv.SearchApartments(ctx, server.ApartmentSearch{
+		Filter: f,
+		Offset: offset,
+	})
+}
+
+func (s *service) BrowseApartments(ctx context.Context, b server.ApartmentBrowse) (*server.ApartmentBrowsePage, error) {
+	return s.srv.BrowseApartments(ctx, b)
+}
+
+func (s *service) SavedApartments(ctx context.Context, u *server.User) ([]server.ApartmentMark, error) {
+	return s.srv.SavedApartments(ctx, *u)
+}
+
+func (s *service) SaveDelivery(ctx context.Context, d server.Delivery) error {
+	return s.srv.SaveDelivery(ctx, d)
+}
+
+func (s *service) Viewings(ctx context.Context, u *server.User) ([]server.ApartmentMark, error) {
+	return s.srv.Viewings(ctx, *u)
+}
+
 func (s *service) IsAllow(userID int64) bool {
 	_, isDisconnected := s.storage.disconnectedUsers.Load(userID)
 	return !isDisconnected
@@ -247,12 +440,21 @@ func (s *service) AvailableCities() []string {
 
 func (s *service) AvailableDistrictsForCity(city string) []string {
 	districts, ok := s.storage.cities.Load(city)
-	if ok {
+	if !ok {
 		return nil
 	}
 	return districts.([]string) // nolint: errcheck
 }
 
+// availableDistricts returns the districts by city.
+func (s *service) availableDistricts() map[string][]string {
+	cities := make(map[string][]string)
+	for _, city := range s.AvailableCities() {
+		cities[city] = s.AvailableDistrictsForCity(city)
+	}
+	return cities
+}
+
 func (s *service) startUpdatingCity() error {
 	if err := s.updateCity(); err != nil {
 		return err
@@ -290,6 +492,44 @@ func (s *service) updateCity() error {
 	return nil
 }
 
+// startUpdatingRates doesn't fail the start, the prices are shown in USD until the rates are known.
+func (s *service) startUpdatingRates() {
+	if err := s.updateRates(); err != nil {
+		slog.Error("update rates failed", "error", err)
+	}
+
+	go s.ratesUpdateLoop()
+}
+
+func (s *service) ratesUpdateLoop() {
+	ticker := time.NewTicker(updateRatesInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.updateRates(); err != nil {
+				slog.Error("update rates failed", "error", err)
+			}
+		}
+	}
+}
+
+func (s *service) updateRates() error {
+	rates, err := s.srv.Rates(s.ctx)
+	if err != nil {
+		return err
+	}
+
+	s.storage.rates.Lock()
+	defer s.storage.rates.Unlock()
+
+	s.storage.rates.rates = rates
+	return nil
+}
+
 func (s *service) startCheckTurnedOffFilters() error {
 	go s.checkTurnedOffFiltersLoop()
 	return nil
@@ -378,11 +618,70 @@ func (s *service) handleApartments(apartmentCh <-chan server.Apartment, errCh <-
 			if !ok {
 				return nil
 			}
-			slog.Info("received apartment",
-				"id", apt.ID,
-				"filter", apt.Filter,
+			s.handleApartment(apt)
+		}
+	}
+}
+
+func (s *service) handleApartment(apt server.Apartment) {
+	ctx, span := tracing.Start(tracing.Extract(s.ctx, apt.TraceContext), "client.handleApartments", attribute.Int64("apartment.id", apt.ID))
+	defer span.End()
+
+	slog.Info("received apartment",
+		"id", apt.ID,
+		"filter", apt.Filter,
+	)
+
+	apt.TraceContext = tracing.Inject(ctx)
+	s.channels.apartment <- apt
+}
+
+// receiveNotifications handles the stream and reconnects with backoff when the stream breaks.
+func (s *service) receiveNotifications(notificationCh <-chan server.Notification, errCh <-chan error) {
+	for {
+		err := s.handleNotifications(notificationCh, errCh)
+
+		for delay := reconnectBaseDelay; ; delay = min(2*delay, maxReconnectDelay) {
+			if s.ctx.Err() != nil {
+				return
+			}
+			slog.Error("notification stream is broken", "reconnect_in", delay, "error", err)
+
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+
+			notificationCh, errCh, err = s.srv.Notifications(s.ctx)
+			if err == nil {
+				break
+			}
+		}
+	}
+}
+
+func (s *service) handleNotifications(notificationCh <-chan server.Notification, errCh <-chan error) error {
+	for {
+		select {
+		case <-s.ctx.Done():
+			return nil
+		case err, ok := <-errCh:
+			if !ok {
+				return nil
+			}
+			return err
+		case n, ok := <-notificationCh:
+			if !ok {
+				return nil
+			}
+
+			slog.Info("received notification",
+				"type", n.Type,
+				"user_id", n.Mark.UserID,
+				"apartment_id", n.Mark.ApartmentID,
 			)
-			s.channels.apartment <- apt
+			s.channels.notification <- n
 		}
 	}
 }
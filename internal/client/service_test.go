@@ -1,9 +1,14 @@
 package client
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
 )
 
 func TestNew(t *testing.T) {
@@ -39,3 +44,85 @@ func TestAvailableCities(t *testing.T) {
 		require.Contains(t, availableCities, city)
 	}
 }
+
+func TestAvailableDistrictsForCity(t *testing.T) {
+	s, err := NewService(nil, nil)
+	require.NoError(t, err)
+	defer s.Stop()
+
+	s.storage.cities.Store("Tbilisi", []string{"Vake", "Saburtalo"})
+
+	require.Equal(t, []string{"Vake", "Saburtalo"}, s.AvailableDistrictsForCity("Tbilisi"))
+	// the unknown city has no districts and doesn't panic
+	require.Nil(t, s.AvailableDistrictsForCity("Kutaisi"))
+}
+
+func TestParseFilter(t *testing.T) {
+	s, err := NewService(nil, nil)
+	require.NoError(t, err)
+	defer s.Stop()
+
+	s.storage.cities.Store("Tbilisi", []string{"Vake", "Saburtalo"})
+	u := &server.User{ID: 1}
+
+	_, unparsed, err := s.ParseFilter(context.Background(), u, "hello")
+	require.ErrorIs(t, err, errFilterNotParsed)
+	require.Equal(t, []string{"hello"}, unparsed)
+
+	f, unparsed, err := s.ParseFilter(context.Background(), u, "2 rooms in Vake near 41.71,44.75")
+	require.NoError(t, err)
+	require.Empty(t, unparsed)
+	require.True(t, f.IsUpdate)
+	require.Equal(t, server.RentAdType, *f.AdType)
+	require.Equal(t, "Tbilisi", *f.City)
+	require.Equal(t, map[string]struct{}{"Vake": {}}, f.District)
+	require.Equal(t, defaultDistanceToLocation, *f.MaxDistance)
+
+	active, ok := s.storage.active.Load(u.ID)
+	require.True(t, ok)
+	require.Equal(t, f, active)
+}
+
+type fakeNotificationSrv struct {
+	srv
+	streams []chan server.Notification
+	calls   int
+}
+
+func (s *fakeNotificationSrv) Notifications(context.Context) (<-chan server.Notification, <-chan error, error) {
+	s.calls++
+	if s.calls == 1 {
+		return nil, nil, errors.New("connection refused")
+	}
+	return s.streams[s.calls-2], make(chan error), nil
+}
+
+func TestReceiveNotifications(t *testing.T) {
+	reconnectBaseDelay, maxReconnectDelay = time.Millisecond, time.Millisecond
+
+	stream := make(chan server.Notification, 1)
+	stream <- server.Notification{Type: server.ApartmentRemovedNotification}
+	cli := &fakeNotificationSrv{streams: []chan server.Notification{stream}}
+
+	s, err := NewService(cli, nil)
+	require.NoError(t, err)
+
+	errCh := make(chan error, 1)
+	errCh <- errors.New("stream is broken")
+	done := make(chan struct{})
+	go func() {
+		s.receiveNotifications(nil, errCh)
+		close(done)
+	}()
+
+	select {
+	case n := <-s.NotificationWatcher():
+		require.Equal(t, server.ApartmentRemovedNotification, n.Type)
+	case <-time.After(time.Second):
+		t.Fatal("the notification isn't received after the reconnection")
+	}
+	require.Equal(t, 2, cli.calls)
+
+	s.Stop()
+	<-done
+}
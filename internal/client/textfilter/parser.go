@@ -0,0 +1,363 @@
+package textfilter
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+// Result is the filter draft parsed from the text.
+type Result struct {
+	Filter server.Filter
+	// Unparsed are the words the parser didn't understand
+	Unparsed []string
+}
+
+// IsEmpty reports whether no filter parameter was parsed.
+func (r Result) IsEmpty() bool {
+	f := r.Filter
+	return f.AdType == nil && f.BuildingStatus == nil && f.Name == nil && len(f.District) == 0 &&
+		f.City == nil && f.MinPrice == nil && f.MaxPrice == nil && f.MinRooms == nil && f.MaxRooms == nil &&
+		f.MinArea == nil && f.MaxArea == nil && f.IsOwner == nil && f.Coordinates == nil && f.MaxDistance == nil
+}
+
+const (
+	number = `(\d+(?:[.,]\d+)?)\s*(k|тыс\.?)?`
+	count  = `(\d+)`
+
+	minPrefix = `from|over|min|at least|от|не менее|больше|>=?`
+	maxPrefix = `up to|under|max|below|till|до|не более|не дороже|меньше|<=?`
+	currency  = usd + `|` + gel + `|` + eur
+
+	usd = `\$|usd|dollars?|долл\p{L}*|баксов`
+	gel = `₾|gel|lari|лари`
+	eur = `€|euros?|eur|евро`
+
+	roomsUnit = `rooms?|room|bedrooms?|br|bdr|r|комнат\p{L}*|комн\.?|к`
+	areaUnit  = `m2|m²|sqm|sq\.?\s*m|meters|кв\.?\s*м|м2|м²|метров|квадратов`
+	kmUnit    = `km|км`
+	metreUnit = `m|м|meters|метров`
+)
+
+// rule parses the filter parameter from the matched text.
+type rule struct {
+	re    *regexp.Regexp
+	apply func(f *server.Filter, m []string)
+}
+
+// word matches the pattern as separate words, \b doesn't support Cyrillic.
+func word(pattern string) *regexp.Regexp {
+	return regexp.MustCompile(`(?:^|[^\p{L}\p{N}])(?:` + pattern + `)(?:$|[^\p{L}\p{N}])`)
+}
+
+// rules are applied in order, the matched text is removed before the next rule,
+// so the units of rooms and area are parsed before the prices.
+var rules = []rule{
+	{
+		re: word(`(-?\d{1,2}\.\d+)\s*,\s*(-?\d{1,3}\.\d+)`),
+		apply: func(f *server.Filter, m []string) {
+			f.Coordinates = &server.Coordinates{Lat: parseNumber(m[1], ""), Lng: parseNumber(m[2], "")}
+		},
+	},
+	{
+		re: word(`(?:within|radius|в радиусе|в пределах)?\s*` + number + `\s*(` + kmUnit + `)`),
+		apply: func(f *server.Filter, m []string) {
+			distance := parseNumber(m[1], m[2]) * 1000
+			f.MaxDistance = &distance
+		},
+	},
+	{
+		re: word(`(?:within|radius|в радиусе|в пределах)\s*` + number + `\s*(?:` + metreUnit + `)`),
+		apply: func(f *server.Filter, m []string) {
+			distance := parseNumber(m[1], m[2])
+			f.MaxDistance = &distance
+		},
+	},
+	{
+		re: word(`studio|студия|студию`),
+		apply: func(f *server.Filter, _ []string) {
+			rooms := 1.0
+			f.MinRooms, f.MaxRooms = &rooms, &rooms
+		},
+	},
+	{
+		re: word(count + `\s*(?:-|–|to|до)\s*` + count + `\s*(?:-х)?\s*(?:` + roomsUnit + `)`),
+		apply: func(f *server.Filter, m []string) {
+			minRooms, maxRooms := parseNumber(m[1], ""), parseNumber(m[2], "")
+			f.MinRooms, f.MaxRooms = &minRooms, &maxRooms
+		},
+	},
+	{
+		re: word(`(` + minPrefix + `)?\s*(` + maxPrefix + `)?\s*` + count + `\s*(\+)?\s*(?:-х|-)?\s*(?:` + roomsUnit + `)`),
+		apply: func(f *server.Filter, m []string) {
+			rooms := parseNumber(m[3], "")
+			switch {
+			case m[1] != "" || m[4] != "":
+				f.MinRooms = &rooms
+			case m[2] != "":
+				f.MaxRooms = &rooms
+			default:
+				f.MinRooms, f.MaxRooms = &rooms, &rooms
+			}
+		},
+	},
+	{
+		re: word(count + `\s*(?:-|–|to|до)\s*` + count + `\s*(?:` + areaUnit + `)`),
+		apply: func(f *server.Filter, m []string) {
+			minArea, maxArea := parseNumber(m[1], ""), parseNumber(m[2], "")
+			f.MinArea, f.MaxArea = &minArea, &maxArea
+		},
+	},
+	{
+		re: word(`(` + maxPrefix + `)?\s*(?:` + minPrefix + `)?\s*` + count + `\s*(?:` + areaUnit + `)`),
+		apply: func(f *server.Filter, m []string) {
+			area := parseNumber(m[2], "")
+			if m[1] != "" {
+				f.MaxArea = &area
+				return
+			}
+			f.MinArea = &area
+		},
+	},
+	{
+		re:    word(`(?:` + currency + `)\s*` + number + `\s*(?:-|–|to)\s*(?:` + currency + `)?\s*` + number + `\s*(?:` + currency + `)?`),
+		apply: withCurrency(priceRange),
+	},
+	{
+		re:    word(number + `\s*(?:-|–|to)\s*` + number + `\s*(?:` + currency + `)`),
+		apply: withCurrency(priceRange),
+	},
+	{
+		re: word(`(` + minPrefix + `)\s*(?:` + currency + `)?\s*` + number + `\s*(?:` + currency + `)?`),
+		apply: withCurrency(func(f *server.Filter, m []string) {
+			price := parseNumber(m[2], m[3])
+			f.MinPrice = &price
+		}),
+	},
+	{
+		re: word(`(?:(?:` + maxPrefix + `)\s*(?:` + currency + `)?\s*` + number + `\s*(?:` + currency + `)?)|(?:(?:` + currency + `)\s*` + number + `)|(?:` + number + `\s*(?:` + currency + `))`),
+		apply: withCurrency(func(f *server.Filter, m []string) {
+			for i := 1; i < len(m); i += 2 {
+				if m[i] != "" {
+					price := parseNumber(m[i], m[i+1])
+					f.MaxPrice = &price
+					return
+				}
+			}
+		}),
+	},
+	{
+		// the bare number is the max price: "2br vake 800"
+		re: word(`(\d{3,}(?:[.,]\d+)?)|(\d+(?:[.,]\d+)?)\s*(k|тыс\.?)`),
+		apply: func(f *server.Filter, m []string) {
+			if f.MaxPrice != nil {
+				return
+			}
+			price := parseNumber(m[1], "")
+			if m[1] == "" {
+				price = parseNumber(m[2], m[3])
+			}
+			f.MaxPrice = &price
+		},
+	},
+	{
+		re: word(`owner|owners|no agents|without agents|собственник\p{L}*|хозя\p{L}+|без посредников|без агентов`),
+		apply: func(f *server.Filter, _ []string) {
+			isOwner := true
+			f.IsOwner = &isOwner
+		},
+	},
+	{
+		re: word(`agency|agencies|agent|агентств\p{L}*|агент\p{L}*`),
+		apply: func(f *server.Filter, _ []string) {
+			isOwner := false
+			f.IsOwner = &isOwner
+		},
+	},
+	{
+		re: word(`(?:for )?rent|renting|аренд\p{L}*|снять|сниму`),
+		apply: func(f *server.Filter, _ []string) {
+			adType := server.RentAdType
+			f.AdType = &adType
+		},
+	},
+	{
+		re: word(`(?:for )?sale|buy|купить|куплю|продаж\p{L}*`),
+		apply: func(f *server.Filter, _ []string) {
+			adType := server.SaleAdType
+			f.AdType = &adType
+		},
+	},
+	{
+		re: word(`under construction|строящ\p{L}*|на стадии строительства`),
+		apply: func(f *server.Filter, _ []string) {
+			status := int64(server.UnderConstructionBuildingStatus)
+			f.BuildingStatus = &status
+		},
+	},
+	{
+		re: word(`new buildings?|новостро\p{L}*`),
+		apply: func(f *server.Filter, _ []string) {
+			status := int64(server.NewBuildingStatus)
+			f.BuildingStatus = &status
+		},
+	},
+	{
+		re: word(`old buildings?|старый фонд|вторичк\p{L}*`),
+		apply: func(f *server.Filter, _ []string) {
+			status := int64(server.OldBuildingStatus)
+			f.BuildingStatus = &status
+		},
+	},
+}
+
+func priceRange(f *server.Filter, m []string) {
+	minPrice, maxPrice := parseNumber(m[1], m[2]), parseNumber(m[3], m[4])
+	f.MinPrice, f.MaxPrice = &minPrice, &maxPrice
+}
+
+// currencies detect the currency of the matched price, the price without it is in the currency of the user.
+var currencies = []struct {
+	re       *regexp.Regexp
+	currency server.Currency
+}{
+	{re: regexp.MustCompile(usd), currency: server.USD},
+	{re: regexp.MustCompile(gel), currency: server.GEL},
+	{re: regexp.MustCompile(eur), currency: server.EUR},
+}
+
+func withCurrency(apply func(f *server.Filter, m []string)) func(f *server.Filter, m []string) {
+	return func(f *server.Filter, m []string) {
+		apply(f, m)
+		for _, c := range currencies {
+			if c.re.MatchString(m[0]) {
+				f.Currency = c.currency
+				return
+			}
+		}
+	}
+}
+
+var nameRe = regexp.MustCompile(`#([\p{L}\p{N}_]+)`)
+
+// Parse parses the filter draft from the English or Russian text,
+// cities are the available districts by city.
+func Parse(text string, cities map[string][]string) Result {
+	f := server.Filter{
+		District: make(map[string]struct{}),
+	}
+
+	// the name keeps the case of the text
+	if m := nameRe.FindStringSubmatch(text); m != nil {
+		f.Name = &m[1]
+		text = nameRe.ReplaceAllString(text, " ")
+	}
+
+	rest := " " + strings.ToLower(text) + " "
+	for _, r := range rules {
+		rest = r.re.ReplaceAllStringFunc(rest, func(match string) string {
+			r.apply(&f, r.re.FindStringSubmatch(match))
+			return " "
+		})
+	}
+
+	rest = parsePlaces(&f, rest, cities)
+
+	var unparsed []string
+	for _, w := range strings.FieldsFunc(rest, isSeparator) {
+		if _, ok := stopWords[w]; !ok {
+			unparsed = append(unparsed, w)
+		}
+	}
+
+	return Result{
+		Filter:   f,
+		Unparsed: unparsed,
+	}
+}
+
+// parsePlaces finds the districts and the city, the city of the first district
+// is used when the city isn't mentioned.
+func parsePlaces(f *server.Filter, text string, cities map[string][]string) string {
+	districtCities := make(map[string][]string)
+	names := make(map[string]string)
+	for city, districts := range cities {
+		for _, district := range districts {
+			districtCities[district] = append(districtCities[district], city)
+			names[strings.ToLower(district)] = district
+		}
+	}
+	for alias, district := range districtAliases {
+		if _, ok := districtCities[district]; ok {
+			names[alias] = district
+		}
+	}
+
+	// the districts are found before the cities: "Old Tbilisi" is a district
+	var districts []string
+	text = replaceNames(text, names, func(district string) {
+		districts = append(districts, district)
+	})
+
+	names = make(map[string]string)
+	for alias, city := range cityAliases {
+		if _, ok := cities[city]; ok {
+			names[alias] = city
+		}
+	}
+	for city := range cities {
+		names[strings.ToLower(city)] = city
+	}
+
+	text = replaceNames(text, names, func(city string) {
+		f.City = &city
+	})
+
+	for _, district := range districts {
+		if f.City == nil {
+			city := slices.Min(districtCities[district])
+			f.City = &city
+		}
+		if slices.Contains(districtCities[district], *f.City) {
+			f.District[district] = struct{}{}
+		}
+	}
+	return text
+}
+
+// replaceNames removes the names from the text, the longest names are matched first.
+func replaceNames(text string, names map[string]string, found func(value string)) string {
+	keys := make([]string, 0, len(names))
+	for name := range names {
+		keys = append(keys, name)
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		return cmp.Or(cmp.Compare(len(b), len(a)), cmp.Compare(a, b))
+	})
+
+	for _, name := range keys {
+		re := word(regexp.QuoteMeta(name))
+		if !re.MatchString(text) {
+			continue
+		}
+		text = re.ReplaceAllString(text, " ")
+		found(names[name])
+	}
+	return text
+}
+
+func parseNumber(value, multiplier string) float64 {
+	n, _ := strconv.ParseFloat(strings.ReplaceAll(value, ",", "."), 64)
+	if multiplier != "" {
+		n *= 1000
+	}
+	return n
+}
+
+func isSeparator(r rune) bool {
+	return !(r == '_' || ('0' <= r && r <= '9') || ('a' <= r && r <= 'z') || r >= 0x0400 && r <= 0x04FF || r == '$')
+}
@@ -0,0 +1,148 @@
+package textfilter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+var testCities = map[string][]string{
+	"Tbilisi": {"Vake", "Saburtalo", "Old Tbilisi", "Gldani"},
+	"Batumi":  {"Old Batumi", "New Boulevard"},
+}
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		testCaseName     string
+		text             string
+		expected         server.Filter
+		expectedUnparsed []string
+	}{
+		{
+			testCaseName: "english",
+			text:         "2 rooms in Vake or Saburtalo up to 900$, owner only, near 41.71,44.75 within 2km",
+			expected: server.Filter{
+				City:        ptr("Tbilisi"),
+				District:    map[string]struct{}{"Vake": {}, "Saburtalo": {}},
+				MinRooms:    ptr(2.0),
+				MaxRooms:    ptr(2.0),
+				MaxPrice:    ptr(900.0),
+				Currency:    server.USD,
+				IsOwner:     ptr(true),
+				Coordinates: &server.Coordinates{Lat: 41.71, Lng: 44.75},
+				MaxDistance: ptr(2000.0),
+			},
+		},
+		{
+			testCaseName: "russian",
+			text:         "Снять 2-3 комнаты в Ваке или Старом Тбилиси от 50 м2 до 1.2k$ без посредников",
+			expected: server.Filter{
+				AdType:   ptr(server.RentAdType),
+				City:     ptr("Tbilisi"),
+				District: map[string]struct{}{"Vake": {}},
+				MinRooms: ptr(2.0),
+				MaxRooms: ptr(3.0),
+				MinArea:  ptr(50.0),
+				MaxPrice: ptr(1200.0),
+				Currency: server.USD,
+				IsOwner:  ptr(true),
+			},
+			expectedUnparsed: []string{"старом"},
+		},
+		{
+			testCaseName: "russian district",
+			text:         "2к квартира, старый тбилиси, новостройка, $500-$800",
+			expected: server.Filter{
+				City:           ptr("Tbilisi"),
+				District:       map[string]struct{}{"Old Tbilisi": {}},
+				BuildingStatus: ptr(int64(server.NewBuildingStatus)),
+				MinRooms:       ptr(2.0),
+				MaxRooms:       ptr(2.0),
+				MinPrice:       ptr(500.0),
+				MaxPrice:       ptr(800.0),
+				Currency:       server.USD,
+			},
+		},
+		{
+			testCaseName: "city and ranges",
+			text:         "#SeaView buy in Batumi from 3 rooms 40-70 sqm 50000-90000 usd agency",
+			expected: server.Filter{
+				Name:     ptr("SeaView"),
+				AdType:   ptr(server.SaleAdType),
+				City:     ptr("Batumi"),
+				District: map[string]struct{}{},
+				MinRooms: ptr(3.0),
+				MinArea:  ptr(40.0),
+				MaxArea:  ptr(70.0),
+				MinPrice: ptr(50000.0),
+				MaxPrice: ptr(90000.0),
+				Currency: server.USD,
+				IsOwner:  ptr(false),
+			},
+		},
+		{
+			testCaseName: "lari",
+			text:         "2 rooms in Vake 1500-2500 gel",
+			expected: server.Filter{
+				City:     ptr("Tbilisi"),
+				District: map[string]struct{}{"Vake": {}},
+				MinRooms: ptr(2.0),
+				MaxRooms: ptr(2.0),
+				MinPrice: ptr(1500.0),
+				MaxPrice: ptr(2500.0),
+				Currency: server.GEL,
+			},
+		},
+		{
+			testCaseName: "euro",
+			text:         "Квартира в Батуми до 700 евро",
+			expected: server.Filter{
+				City:     ptr("Batumi"),
+				District: map[string]struct{}{},
+				MaxPrice: ptr(700.0),
+				Currency: server.EUR,
+			},
+		},
+		{
+			testCaseName: "inline query",
+			text:         "2br vake 800",
+			expected: server.Filter{
+				City:     ptr("Tbilisi"),
+				District: map[string]struct{}{"Vake": {}},
+				MinRooms: ptr(2.0),
+				MaxRooms: ptr(2.0),
+				MaxPrice: ptr(800.0),
+			},
+		},
+		{
+			testCaseName: "unparsed",
+			text:         "cozy studio with a balcony",
+			expected: server.Filter{
+				District: map[string]struct{}{},
+				MinRooms: ptr(1.0),
+				MaxRooms: ptr(1.0),
+			},
+			expectedUnparsed: []string{"cozy", "balcony"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCaseName, func(t *testing.T) {
+			result := Parse(tc.text, testCities)
+			require.Equal(t, tc.expected, result.Filter)
+			require.Equal(t, tc.expectedUnparsed, result.Unparsed)
+		})
+	}
+}
+
+func TestParseEmpty(t *testing.T) {
+	result := Parse("hello there", testCities)
+	require.True(t, result.IsEmpty())
+	require.Equal(t, []string{"hello", "there"}, result.Unparsed)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
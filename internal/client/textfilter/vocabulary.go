@@ -0,0 +1,37 @@
+package textfilter
+
+var (
+	// cityAliases are the Russian names of the cities
+	cityAliases = map[string]string{
+		"тбилиси": "Tbilisi",
+		"батуми":  "Batumi",
+		"кутаиси": "Kutaisi",
+		"рустави": "Rustavi",
+	}
+
+	// districtAliases are the Russian names of the districts
+	districtAliases = map[string]string{
+		"ваке":           "Vake",
+		"сабуртало":      "Saburtalo",
+		"мтацминда":      "Mtatsminda",
+		"вера":           "Vera",
+		"дидубе":         "Didube",
+		"глдани":         "Gldani",
+		"исани":          "Isani",
+		"надзаладеви":    "Nadzaladevi",
+		"чугурети":       "Chugureti",
+		"самгори":        "Samgori",
+		"крцаниси":       "Krtsanisi",
+		"дидгори":        "Didgori",
+		"старый тбилиси": "Old Tbilisi",
+		"старый город":   "Old Tbilisi",
+	}
+
+	// stopWords connect the filter parameters and are skipped
+	stopWords = map[string]struct{}{
+		"in": {}, "at": {}, "or": {}, "and": {}, "near": {}, "only": {}, "with": {}, "for": {}, "a": {}, "an": {}, "the": {},
+		"apartment": {}, "apartments": {}, "flat": {}, "flats": {}, "district": {}, "districts": {}, "price": {}, "area": {},
+		"в": {}, "во": {}, "на": {}, "или": {}, "и": {}, "у": {}, "около": {}, "рядом": {}, "возле": {}, "с": {}, "только": {},
+		"квартира": {}, "квартиру": {}, "квартиры": {}, "район": {}, "районе": {}, "цена": {}, "площадь": {}, "за": {},
+	}
+)
@@ -0,0 +1,186 @@
+package tg
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tele "gopkg.in/telebot.v3"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+const (
+	adminOffValue = "off"
+)
+
+// adminMiddleware allows the admin commands only to the admin of the bot, the admin is checked
+// by the user ID, the usernames can be changed or taken by other users.
+func (s *service) adminMiddleware(h tele.HandlerFunc) tele.HandlerFunc {
+	return func(c tele.Context) error {
+		if s.adminChatID == 0 || c.Sender() == nil || c.Sender().ID != s.adminChatID {
+			return errPermissionDenied
+		}
+		return h(c)
+	}
+}
+
+// adminStatsHandler shows the users, filters and listings: /admin_stats
+func (s *service) adminStatsHandler(c tele.Context) error {
+	stats, err := s.service.AdminStats(s.ctx)
+	if err != nil {
+		return err
+	}
+
+	return s.sendAdminMessage(c, adminStatsString(*stats))
+}
+
+// adminUserHandler shows the user: /admin_user <chat_id>
+func (s *service) adminUserHandler(c tele.Context) error {
+	args := c.Args()
+	if len(args) != 1 {
+		return errInvalidAdminUser
+	}
+
+	userID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return errInvalidAdminUser
+	}
+
+	user, err := s.service.AdminUser(s.ctx, &server.User{ID: userID})
+	if err != nil {
+		return err
+	}
+
+	return s.sendAdminMessage(c, adminUserString(*user))
+}
+
+// adminGrantSuperuserHandler lifts the limits of the user: /admin_grant_superuser <chat_id> [off]
+func (s *service) adminGrantSuperuserHandler(c tele.Context) error {
+	userID, isOn, err := parseAdminSwitch(c.Args())
+	if err != nil {
+		return err
+	}
+
+	user, err := s.service.AdminSetSuperuser(s.ctx, &server.User{ID: userID, IsSuperuser: isOn})
+	if err != nil {
+		return err
+	}
+
+	return s.sendAdminMessage(c, fmt.Sprintf("User %d, superuser: %s", user.ID, yesNo(user.IsSuperuser)))
+}
+
+// adminBanHandler bans the user: /admin_ban <chat_id> [off]
+func (s *service) adminBanHandler(c tele.Context) error {
+	userID, isOn, err := parseAdminSwitch(c.Args())
+	if err != nil {
+		return err
+	}
+
+	user, err := s.service.AdminBan(s.ctx, &server.User{ID: userID, IsBanned: isOn})
+	if err != nil {
+		return err
+	}
+
+	return s.sendAdminMessage(c, fmt.Sprintf("User %d, banned: %s", user.ID, yesNo(user.IsBanned)))
+}
+
+// adminRefreshHandler reloads the apartments from the providers: /admin_refresh
+func (s *service) adminRefreshHandler(c tele.Context) error {
+	if err := s.service.AdminRefresh(s.ctx); err != nil {
+		return err
+	}
+
+	return s.sendAdminMessage(c, adminRefreshMessage)
+}
+
+func (s *service) sendAdminMessage(c tele.Context, msg string) error {
+	m, err := s.sendMessageToBot(chatID(c), msg, tele.NoPreview)
+	if err != nil {
+		return err
+	}
+
+	s.messages.StoreMessage(chatID(c), m, botMessage)
+	return nil
+}
+
+// parseAdminSwitch parses <chat_id> [off] of the admin commands.
+func parseAdminSwitch(args []string) (int64, bool, error) {
+	if len(args) == 0 || len(args) > 2 {
+		return 0, false, errInvalidAdminSwitch
+	}
+
+	userID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return 0, false, errInvalidAdminSwitch
+	}
+
+	if len(args) == 1 {
+		return userID, true, nil
+	}
+
+	if args[1] != adminOffValue {
+		return 0, false, errInvalidAdminSwitch
+	}
+	return userID, false, nil
+}
+
+func adminStatsString(stats server.AdminStats) string {
+	providers := make([]string, 0, len(stats.Apartments))
+	for provider := range stats.Apartments {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+
+	var apartments strings.Builder
+	for _, provider := range providers {
+		apartments.WriteString(fmt.Sprintf("%s: %d\n", provider, stats.Apartments[provider]))
+	}
+
+	return fmt.Sprintf(adminStatsLayout, stats.Users, stats.Filters, stats.ActiveSubscribers, apartments.String())
+}
+
+func adminUserString(u server.AdminUser) string {
+	lastActivity := "unknown"
+	if u.User.LastActivityAt != nil {
+		lastActivity = u.User.LastActivityAt.In(viewingLocation).Format(time.DateTime)
+	}
+
+	var filters strings.Builder
+	for _, f := range u.Filters {
+		state := "active"
+		if f.PauseTimestamp != nil {
+			state = "paused"
+		}
+		if f.User != nil && !f.OwnedBy(u.User.ID) {
+			state += ", shared"
+		}
+
+		name := f.ID
+		if f.Name != nil {
+			name = *f.Name
+		}
+		filters.WriteString(fmt.Sprintf("#%s (%s)\n", name, state))
+	}
+
+	return fmt.Sprintf(
+		adminUserLayout,
+		u.User.ID,
+		planTitle(u.User.ActivePlan(time.Now())),
+		planExpiresString(u.User),
+		yesNo(u.User.IsSuperuser),
+		yesNo(u.User.IsBanned),
+		yesNo(u.User.IsBlocked),
+		lastActivity,
+		filters.String(),
+	)
+}
+
+func yesNo(v bool) string {
+	if v {
+		return "yes"
+	}
+	return "no"
+}
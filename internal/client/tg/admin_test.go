@@ -0,0 +1,89 @@
+package tg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	tele "gopkg.in/telebot.v3"
+)
+
+func TestParseAdminSwitch(t *testing.T) {
+	testCases := []struct {
+		testCaseName   string
+		args           []string
+		expectedUserID int64
+		expectedIsOn   bool
+		expectedError  error
+	}{
+		{
+			testCaseName:   "on",
+			args:           []string{"42"},
+			expectedUserID: 42,
+			expectedIsOn:   true,
+		},
+		{
+			testCaseName:   "off",
+			args:           []string{"-100123", "off"},
+			expectedUserID: -100123,
+		},
+		{
+			testCaseName:  "without user",
+			expectedError: errInvalidAdminSwitch,
+		},
+		{
+			testCaseName:  "invalid user",
+			args:          []string{"john"},
+			expectedError: errInvalidAdminSwitch,
+		},
+		{
+			testCaseName:  "invalid switch",
+			args:          []string{"42", "on"},
+			expectedError: errInvalidAdminSwitch,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCaseName, func(t *testing.T) {
+			userID, isOn, err := parseAdminSwitch(tc.args)
+			require.Equal(t, tc.expectedError, err)
+			require.Equal(t, tc.expectedUserID, userID)
+			require.Equal(t, tc.expectedIsOn, isOn)
+		})
+	}
+}
+
+func TestAdminMiddleware(t *testing.T) {
+	testCases := []struct {
+		testCaseName  string
+		adminChatID   int64
+		sender        *tele.User
+		expectedError error
+	}{
+		{
+			testCaseName: "admin",
+			adminChatID:  1,
+			sender:       &tele.User{ID: 1, Username: "admin"},
+		},
+		{
+			testCaseName:  "admin username of other user",
+			adminChatID:   1,
+			sender:        &tele.User{ID: 2, Username: "admin"},
+			expectedError: errPermissionDenied,
+		},
+		{
+			testCaseName:  "admin isn't set",
+			sender:        &tele.User{},
+			expectedError: errPermissionDenied,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCaseName, func(t *testing.T) {
+			s := &service{adminChatID: tc.adminChatID}
+			c := (&tele.Bot{}).NewContext(tele.Update{Message: &tele.Message{Sender: tc.sender}})
+
+			err := s.adminMiddleware(func(tele.Context) error { return nil })(c)
+			require.Equal(t, tc.expectedError, err)
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package tg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tele "gopkg.in/telebot.v3"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+const (
+	btnSaveApartment = "btn_save_apartment"
+	btnHideApartment = "btn_hide_apartment"
+	noteApartment    = "note_apartment"
+
+	// maxSavedMessageLength keeps the saved list below the telegram message limit
+	maxSavedMessageLength = 3500
+)
+
+func (s *service) saveApartmentBtn(c tele.Context) error {
+	values := getValue(c)
+	if len(values) < 2 {
+		return errNotFoundHandler
+	}
+
+	apartmentID, err := strconv.ParseInt(values[0], 10, 64)
+	if err != nil {
+		return err
+	}
+	isSaved := values[1] == "1"
+
+	_, err = s.service.MarkApartment(s.ctx, server.ApartmentMarkChange{
+		UserID:      chatID(c),
+		ApartmentID: apartmentID,
+		IsSaved:     &isSaved,
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.b.EditReplyMarkup(c.Message(), s.apartmentMarkup(apartmentID, isSaved)); err != nil {
+		return err
+	}
+
+	text := "⭐ Saved, see /saved"
+	if !isSaved {
+		text = "Removed from saved"
+	}
+	return c.Respond(&tele.CallbackResponse{Text: text})
+}
+
+func (s *service) hideApartmentBtn(c tele.Context) error {
+	values := getValue(c)
+	if len(values) == 0 {
+		return errNotFoundHandler
+	}
+
+	apartmentID, err := strconv.ParseInt(values[0], 10, 64)
+	if err != nil {
+		return err
+	}
+
+	isHidden := true
+	_, err = s.service.MarkApartment(s.ctx, server.ApartmentMarkChange{
+		UserID:      chatID(c),
+		ApartmentID: apartmentID,
+		IsHidden:    &isHidden,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := c.Delete(); err != nil {
+		return err
+	}
+
+	return c.Respond(&tele.CallbackResponse{Text: "🙈 You will not receive this apartment again"})
+}
+
+func (s *service) noteApartmentInit(c tele.Context) error {
+	userID := chatID(c)
+
+	if values := getValue(c); len(values) != 0 && values[0] != anyValue {
+		apartmentID, err := strconv.ParseInt(values[0], 10, 64)
+		if err != nil {
+			return err
+		}
+		s.pendingApartment.Store(userID, apartmentID)
+	}
+
+	s.userAction.Store(userID, noteApartment)
+
+	m, err := s.sendMessageToBot(userID, "Enter a note for the apartment", cancelOrResetMarkup(noteApartment))
+	if err != nil {
+		return err
+	}
+
+	s.messages.StoreMessage(userID, m, actionMessage)
+	return nil
+}
+
+func (s *service) noteApartment(c tele.Context) error {
+	userID := chatID(c)
+
+	values := getValue(c)
+	if c.Callback() != nil && (len(values) == 0 || values[0] != anyValue) {
+		return s.noteApartmentInit(c)
+	}
+
+	apartmentID, ok := s.pendingApartment.Load(userID)
+	if !ok {
+		s.userAction.Delete(userID)
+		return errNotFoundHandler
+	}
+
+	var note string
+	if c.Callback() == nil {
+		note = c.Text()
+	}
+
+	_, err := s.service.MarkApartment(s.ctx, server.ApartmentMarkChange{
+		UserID:      userID,
+		ApartmentID: apartmentID.(int64), // nolint: errcheck
+		Note:        &note,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.userAction.Delete(userID)
+	s.pendingApartment.Delete(userID)
+
+	text := "📝 Note saved"
+	if note == "" {
+		text = "📝 Note removed"
+	}
+
+	m, err := s.sendMessageToBot(userID, text)
+	if err != nil {
+		return err
+	}
+
+	s.messages.StoreMessage(userID, m, botMessage)
+	return nil
+}
+
+func (s *service) savedHandler(c tele.Context) error {
+	userID := chatID(c)
+
+	marks, err := s.service.SavedApartments(s.ctx, userFromContext(c))
+	if err != nil {
+		return err
+	}
+
+	if len(marks) == 0 {
+		m, err := s.sendMessageToBot(userID, savedListIsEmptyMessage)
+		if err != nil {
+			return err
+		}
+		s.messages.StoreMessage(userID, m, botMessage)
+		return nil
+	}
+
+	m := s.money(userID)
+	var msg strings.Builder
+	for i, mark := range marks {
+		item := savedApartmentString(i+1, mark, m)
+		if msg.Len()+len(item) > maxSavedMessageLength {
+			sent, err := s.sendMessageToBot(userID, msg.String(), tele.NoPreview)
+			if err != nil {
+				return err
+			}
+			s.messages.StoreMessage(userID, sent, botMessage)
+			msg.Reset()
+		}
+		msg.WriteString(item)
+	}
+
+	sent, err := s.sendMessageToBot(userID, msg.String(), tele.NoPreview)
+	if err != nil {
+		return err
+	}
+
+	s.messages.StoreMessage(userID, sent, botMessage)
+	return nil
+}
+
+func savedApartmentString(idx int, mark server.ApartmentMark, m money) string {
+	if mark.Apartment == nil {
+		return ""
+	}
+	a := mark.Apartment
+
+	availability := "✅ available"
+	if !mark.IsAvailable {
+		availability = "❌ no longer available"
+	}
+
+	var note string
+	if mark.Note != "" {
+		note = "📝 " + mark.Note + "\n"
+	}
+
+	return fmt.Sprintf(
+		savedApartmentTemplate,
+		idx,
+		typeMap[a.AdType],
+		a.Rooms,
+		a.Area,
+		m.price(*a),
+		a.District,
+		a.City,
+		a.URL,
+		availability,
+		note,
+	)
+}
+
+func (s *service) apartmentMarkup(apartmentID int64, isSaved bool) *tele.ReplyMarkup {
+	id := strconv.FormatInt(apartmentID, 10)
+
+	saveBtn := tele.Btn{Text: "⭐ Save", Data: actionData(btnSaveApartment, id, "1")}
+	statusBtn := tele.Btn{Text: "📋 Status", Data: actionData(btnApartmentStatus, id, "0")}
+	if isSaved {
+		saveBtn = tele.Btn{Text: "★ Saved", Data: actionData(btnSaveApartment, id, "0")}
+		statusBtn.Data = actionData(btnApartmentStatus, id, "1")
+	}
+
+	row := tele.Row{
+		saveBtn,
+		{Text: "🙈 Hide", Data: actionData(btnHideApartment, id)},
+	}
+
+	if _, ok := s.params[noteApartment]; ok {
+		row = append(row, tele.Btn{Text: "📝 Note", Data: actionData(noteApartment, id)})
+	}
+
+	m := &tele.ReplyMarkup{}
+	m.Inline(row, tele.Row{statusBtn})
+	return m
+}
@@ -11,7 +11,7 @@ const (
 )
 
 func (s *service) cancelBtn(c tele.Context) error {
-	userID := c.Sender().ID
+	userID := chatID(c)
 	_, isExist := s.userAction.Load(userID)
 	if isExist {
 		if err := s.cleanUserActions(userID); err != nil {
@@ -12,7 +12,7 @@ const (
 
 func (s *service) deleteBtn(c tele.Context) error {
 	value := getValue(c)[0]
-	userID := c.Sender().ID
+	userID := chatID(c)
 	f := &server.Filter{
 		ID: value,
 		User: &server.User{
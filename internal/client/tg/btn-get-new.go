@@ -9,7 +9,7 @@ const (
 )
 
 func (s *service) getNewApartmentsBtn(c tele.Context) error {
-	userID := c.Sender().ID
+	userID := chatID(c)
 
 	if err := s.cleanUserMessages(userID); err != nil {
 		return err
@@ -1,7 +1,9 @@
 package tg
 
 import (
+	"errors"
 	"fmt"
+	"strconv"
 
 	tele "gopkg.in/telebot.v3"
 
@@ -10,27 +12,101 @@ import (
 
 var (
 	btnGetOldApartments = "btn_get_old"
+
+	sortNames = []struct {
+		sort server.ApartmentSort
+		name string
+	}{
+		{server.NewestSort, "🆕 Newest"},
+		{server.CheapestSort, "💲 Cheapest"},
+		{server.ClosestSort, "📍 Closest"},
+	}
 )
 
+// getOldApartmentsBtn browses the previous apartments of the filter in one message,
+// the first press sends the message, the navigation and sort buttons edit it in place.
 func (s *service) getOldApartmentsBtn(c tele.Context) error {
-	userID := c.Sender().ID
-	f := &server.Filter{
-		ID: getValue(c)[0],
-		User: &server.User{
-			ID: userID,
+	userID := chatID(c)
+	values := getValue(c)
+	if len(values) == 0 {
+		return errNotFoundHandler
+	}
+
+	b := server.ApartmentBrowse{
+		Filter: server.Filter{
+			ID: values[0],
+			User: &server.User{
+				ID: userID,
+			},
 		},
+		Sort: server.NewestSort,
+	}
+	if len(values) == 3 {
+		sort, err := strconv.ParseInt(values[1], 10, 64)
+		if err != nil {
+			return err
+		}
+		b.Sort = server.ApartmentSort(sort)
+		b.Cursor = values[2]
 	}
 
-	err := s.service.Apartments(s.ctx, f)
+	page, err := s.service.BrowseApartments(s.ctx, b)
 	if err != nil {
 		return err
 	}
 
-	err = s.messages.CleanUserMessages(userID)
-	if err != nil {
+	text, markup := browseApartmentsMessage(page, b.Filter.ID, userID, s.money(userID))
+	if b.Cursor != "" {
+		err := c.Edit(text, markup)
+		if err != nil && !errors.Is(err, tele.ErrMessageNotModified) && !errors.Is(err, tele.ErrSameMessageContent) {
+			return err
+		}
+		return c.Respond()
+	}
+
+	if err := s.messages.CleanUserMessages(userID); err != nil {
 		return err
 	}
-	return s.filtersListHandler(c)
+
+	_, err = s.sendMessageToBot(userID, text, markup)
+	return err
+}
+
+func browseApartmentsMessage(page *server.ApartmentBrowsePage, filterID string, userID int64, money money) (string, *tele.ReplyMarkup) {
+	m := &tele.ReplyMarkup{}
+	if page.Apartment == nil {
+		return browseListIsEmptyMessage, m
+	}
+
+	navigationRow := make(tele.Row, 0, 3)
+	if page.PrevCursor != "" {
+		navigationRow = append(navigationRow, browseInlineBtn("◀️", filterID, page.Sort, page.PrevCursor))
+	}
+	navigationRow = append(navigationRow, browseInlineBtn(fmt.Sprintf("%d / %d", page.Position, page.Total), filterID, page.Sort, page.Cursor))
+	if page.NextCursor != "" {
+		navigationRow = append(navigationRow, browseInlineBtn("▶️", filterID, page.Sort, page.NextCursor))
+	}
+
+	sortRow := make(tele.Row, 0, len(sortNames))
+	for _, sort := range sortNames {
+		text := sort.name
+		if sort.sort == page.Sort {
+			text = "✅ " + text
+		}
+		sortRow = append(sortRow, browseInlineBtn(text, filterID, sort.sort, page.Cursor))
+	}
+
+	m.Inline(navigationRow, sortRow)
+
+	a := *page.Apartment
+	return apartmentString(a, userID, a.Filter[userID], money), m
+}
+
+func browseInlineBtn(text, filterID string, sort server.ApartmentSort, cursor string) tele.Btn {
+	return tele.Btn{
+		Text: text,
+		Data: actionData(btnGetOldApartments, filterID, strconv.FormatInt(int64(sort), 10), cursor),
+	}
 }
 
 func getOldApartmentsInlineBtn(filterID string, count int64) tele.Btn {
@@ -0,0 +1,76 @@
+package tg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+func TestBrowseApartmentsMessage(t *testing.T) {
+	testCases := []struct {
+		testCaseName       string
+		page               *server.ApartmentBrowsePage
+		expectedNavigation []string
+	}{
+		{
+			testCaseName: "first",
+			page: &server.ApartmentBrowsePage{
+				Apartment:  &server.Apartment{ID: 1},
+				Position:   1,
+				Total:      340,
+				Cursor:     "0.0",
+				NextCursor: "0.1",
+			},
+			expectedNavigation: []string{"1 / 340", "▶️"},
+		},
+		{
+			testCaseName: "middle",
+			page: &server.ApartmentBrowsePage{
+				Apartment:  &server.Apartment{ID: 1},
+				Sort:       server.CheapestSort,
+				Position:   12,
+				Total:      340,
+				Cursor:     "1.11",
+				PrevCursor: "1.10",
+				NextCursor: "1.12",
+			},
+			expectedNavigation: []string{"◀️", "12 / 340", "▶️"},
+		},
+		{
+			testCaseName: "last",
+			page: &server.ApartmentBrowsePage{
+				Apartment:  &server.Apartment{ID: 1},
+				Position:   2,
+				Total:      2,
+				Cursor:     "0.1",
+				PrevCursor: "0.0",
+			},
+			expectedNavigation: []string{"◀️", "2 / 2"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCaseName, func(t *testing.T) {
+			_, markup := browseApartmentsMessage(tc.page, "filter", 1, money{})
+			require.Len(t, markup.InlineKeyboard, 2)
+
+			navigation := make([]string, 0, len(markup.InlineKeyboard[0]))
+			for _, btn := range markup.InlineKeyboard[0] {
+				navigation = append(navigation, btn.Text)
+			}
+			require.Equal(t, tc.expectedNavigation, navigation)
+
+			sortRow := markup.InlineKeyboard[1]
+			require.Equal(t, "✅ "+sortNames[tc.page.Sort].name, sortRow[tc.page.Sort].Text)
+			require.Equal(t, actionData(btnGetOldApartments, "filter", "2", tc.page.Cursor), sortRow[server.ClosestSort].Data)
+		})
+	}
+}
+
+func TestBrowseApartmentsMessageEmpty(t *testing.T) {
+	text, markup := browseApartmentsMessage(&server.ApartmentBrowsePage{}, "filter", 1, money{})
+	require.Equal(t, browseListIsEmptyMessage, text)
+	require.Empty(t, markup.InlineKeyboard)
+}
@@ -19,12 +19,12 @@ var (
 )
 
 func (s *service) changeTypeInit(c tele.Context) error {
-	userID := c.Sender().ID
+	userID := chatID(c)
 
 	s.userAction.Store(userID, changeAdType)
 
 	msg := &tele.Message{
-		Sender:      c.Sender(),
+		Chat:        c.Chat(),
 		Text:        "Choose what advertisement are you looking for ",
 		ReplyMarkup: typeMarkup(),
 	}
@@ -74,7 +74,7 @@ func (s *service) changeAdType(c tele.Context) error {
 		return err
 	}
 
-	s.userAction.Delete(c.Sender().ID)
+	s.userAction.Delete(chatID(c))
 
 	return s.sendSettingFilter(c, filter)
 }
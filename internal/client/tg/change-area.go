@@ -23,7 +23,7 @@ func (s *service) changeAreaInit(isMinArea bool) initFunc {
 			actionType = changeMaxArea
 		}
 
-		userID := c.Sender().ID
+		userID := chatID(c)
 		s.userAction.Store(userID, actionType)
 
 		messageText := "Enter new min area of your feature apartment (m2)"
@@ -32,7 +32,7 @@ func (s *service) changeAreaInit(isMinArea bool) initFunc {
 		}
 
 		msg := &tele.Message{
-			Sender:      c.Sender(),
+			Chat:        c.Chat(),
 			Text:        messageText,
 			ReplyMarkup: cancelOrResetMarkup(actionType),
 		}
@@ -67,7 +67,7 @@ func (s *service) changeArea(isMinArea bool) changeFunc {
 			return err
 		}
 
-		s.userAction.Delete(c.Sender().ID)
+		s.userAction.Delete(chatID(c))
 
 		return s.sendSettingFilter(c, filter)
 	}
@@ -20,12 +20,12 @@ var (
 )
 
 func (s *service) changeBuildingStatusInit(c tele.Context) error {
-	userID := c.Sender().ID
+	userID := chatID(c)
 
 	s.userAction.Store(userID, changeBuildingStatus)
 
 	msg := &tele.Message{
-		Sender:      c.Sender(),
+		Chat:        c.Chat(),
 		Text:        "Choose what building you are looking for",
 		ReplyMarkup: statusMarkup(),
 	}
@@ -79,7 +79,7 @@ func (s *service) changeBuildingStatus(c tele.Context) error {
 		return err
 	}
 
-	s.userAction.Delete(c.Sender().ID)
+	s.userAction.Delete(chatID(c))
 
 	return s.sendSettingFilter(c, filter)
 }
@@ -15,7 +15,7 @@ var (
 )
 
 func (s *service) changeCityInit(c tele.Context) error {
-	userID := c.Sender().ID
+	userID := chatID(c)
 
 	s.userAction.Store(userID, changeCity)
 
@@ -28,7 +28,7 @@ func (s *service) changeCityInit(c tele.Context) error {
 	}
 
 	msg := &tele.Message{
-		Sender:      c.Sender(),
+		Chat:        c.Chat(),
 		Text:        "Choose city you would like to live ",
 		ReplyMarkup: cityMarkup(cities, idx),
 	}
@@ -88,7 +88,7 @@ func (s *service) changeCity(c tele.Context) error {
 		return err
 	}
 
-	s.userAction.Delete(c.Sender().ID)
+	s.userAction.Delete(chatID(c))
 
 	return s.sendSettingFilter(c, filter)
 }
@@ -16,7 +16,7 @@ var (
 )
 
 func (s *service) changeDistrictInit(c tele.Context) error {
-	userID := c.Sender().ID
+	userID := chatID(c)
 
 	s.userAction.Store(userID, changeDistrict)
 
@@ -32,7 +32,7 @@ func (s *service) changeDistrictInit(c tele.Context) error {
 	}
 
 	msg := &tele.Message{
-		Sender:      c.Sender(),
+		Chat:        c.Chat(),
 		Text:        "Choose district you would like to live ",
 		ReplyMarkup: s.districtMarkup(f, idx),
 	}
@@ -95,7 +95,7 @@ func (s *service) changeDistrict(c tele.Context) error {
 		return err
 	}
 
-	s.userAction.Delete(c.Sender().ID)
+	s.userAction.Delete(chatID(c))
 
 	return s.sendSettingFilter(c, filter)
 }
@@ -17,12 +17,12 @@ var (
 )
 
 func (s *service) changeLocationInit(c tele.Context) error {
-	userID := c.Sender().ID
+	userID := chatID(c)
 
 	s.userAction.Store(userID, changeLocation)
 
 	msg := &tele.Message{
-		Sender:      c.Sender(),
+		Chat:        c.Chat(),
 		Text:        "Send the location of place you would like to live nearby\n⚠️ Send location from Telegram",
 		ReplyMarkup: cancelOrResetMarkup(changeLocation),
 	}
@@ -53,7 +53,7 @@ func (s *service) changeLocation(c tele.Context) error {
 		return err
 	}
 
-	s.userAction.Delete(c.Sender().ID)
+	s.userAction.Delete(chatID(c))
 
 	return s.sendSettingFilter(c, filter)
 }
@@ -81,12 +81,12 @@ func (s *service) locationParamToString(f *server.Filter) string {
 }
 
 func (s *service) changeMaxDistanceInit(c tele.Context) error {
-	userID := c.Sender().ID
+	userID := chatID(c)
 
 	s.userAction.Store(userID, changeMaxDistance)
 
 	msg := &tele.Message{
-		Sender:      c.Sender(),
+		Chat:        c.Chat(),
 		Text:        "Enter the maximum distance to the location you would like to live nearby (m)",
 		ReplyMarkup: cancelOrResetMarkup(changeMaxDistance),
 	}
@@ -114,7 +114,7 @@ func (s *service) changeMaxDistance(c tele.Context) error {
 		return err
 	}
 
-	s.userAction.Delete(c.Sender().ID)
+	s.userAction.Delete(chatID(c))
 
 	return s.sendSettingFilter(c, filter)
 }
@@ -0,0 +1,74 @@
+package tg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tele "gopkg.in/telebot.v3"
+
+	"github.com/irbgeo/apartment-bot/internal/client"
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+const (
+	changeMinScore = "change_min_score"
+)
+
+func (s *service) changeMinScoreInit(c tele.Context) error {
+	userID := chatID(c)
+	s.userAction.Store(userID, changeMinScore)
+
+	msg := &tele.Message{
+		Chat:        c.Chat(),
+		Text:        "Enter min score from 0 to 100",
+		ReplyMarkup: cancelOrResetMarkup(changeMinScore),
+	}
+
+	return s.sendMessage(msg, actionMessage)
+}
+
+func (s *service) changeMinScore(c tele.Context) error {
+	r := &client.ChangeFilterMinScoreInfo{
+		User: userFromContext(c),
+	}
+
+	values := getValue(c)
+	if len(values) == 0 || values[0] != anyValue {
+		minScore, err := strconv.ParseFloat(c.Text(), 64)
+		if err != nil {
+			return fmt.Errorf("invalid value: %s", c.Text())
+		}
+
+		r.NewMinScore = &minScore
+	}
+
+	filter, err := client.WithActiveFilter(s.ctx, r, s.service.ChangeFilterMinScore)
+	if err != nil {
+		return err
+	}
+
+	s.userAction.Delete(chatID(c))
+
+	return s.sendSettingFilter(c, filter)
+}
+
+func changeMinScoreBtn(_ *server.Filter) tele.Btn {
+	return tele.Btn{
+		Text: "⭐ Min score",
+		Data: changeMinScore,
+	}
+}
+
+func (s *service) minScoreParamToString(f *server.Filter) string {
+	param := make([]string, 0, 3)
+	param = append(param, "Min score: ")
+
+	if f.MinScore == nil {
+		param = append(param, anyValue)
+	} else {
+		param = append(param, strconv.FormatFloat(*f.MinScore, 'f', -1, 64), "/100")
+	}
+
+	return strings.Join(param, "")
+}
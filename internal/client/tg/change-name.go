@@ -14,11 +14,11 @@ var (
 )
 
 func (s *service) changeNameInit(c tele.Context) error {
-	userID := c.Sender().ID
+	userID := chatID(c)
 	s.userAction.Store(userID, changeName)
 
 	msg := &tele.Message{
-		Sender:      c.Sender(),
+		Chat:        c.Chat(),
 		Text:        "Enter new name for your filter",
 		ReplyMarkup: cancelOrResetMarkup(changeName),
 	}
@@ -40,7 +40,7 @@ func (s *service) changeName(c tele.Context) error {
 		return err
 	}
 
-	s.userAction.Delete(c.Sender().ID)
+	s.userAction.Delete(chatID(c))
 
 	return s.sendSettingFilter(c, filter)
 }
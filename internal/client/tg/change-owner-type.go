@@ -14,12 +14,12 @@ var (
 )
 
 func (s *service) changeOwnerTypeInit(c tele.Context) error {
-	userID := c.Sender().ID
+	userID := chatID(c)
 
 	s.userAction.Store(userID, changeOwnerType)
 
 	msg := &tele.Message{
-		Sender:      c.Sender(),
+		Chat:        c.Chat(),
 		Text:        "Choose who you would like to receive ads from",
 		ReplyMarkup: ownerTypeMarkup(),
 	}
@@ -70,7 +70,7 @@ func (s *service) changeOwnerType(c tele.Context) error {
 		return err
 	}
 
-	s.userAction.Delete(c.Sender().ID)
+	s.userAction.Delete(chatID(c))
 
 	return s.sendSettingFilter(c, filter)
 }
@@ -23,16 +23,17 @@ func (s *service) changePriceInit(isMinPrice bool) initFunc {
 			actionType = changeMaxPrice
 		}
 
-		userID := c.Sender().ID
+		userID := chatID(c)
 		s.userAction.Store(userID, actionType)
 
 		messageText := "Enter new min price"
 		if !isMinPrice {
 			messageText = "Enter new max price"
 		}
+		messageText += ", " + s.service.Currency(s.ctx, userID).OrUSD().Symbol()
 
 		msg := &tele.Message{
-			Sender:      c.Sender(),
+			Chat:        c.Chat(),
 			Text:        messageText,
 			ReplyMarkup: cancelOrResetMarkup(actionType),
 		}
@@ -46,6 +47,7 @@ func (s *service) changePrice(isMinPrice bool) changeFunc {
 		r := &client.ChangeFilterPriceInfo{
 			User:        userFromContext(c),
 			IsMinChange: isMinPrice,
+			Currency:    s.service.Currency(s.ctx, chatID(c)),
 		}
 
 		values := getValue(c)
@@ -67,7 +69,7 @@ func (s *service) changePrice(isMinPrice bool) changeFunc {
 			return err
 		}
 
-		s.userAction.Delete(c.Sender().ID)
+		s.userAction.Delete(chatID(c))
 
 		return s.sendSettingFilter(c, filter)
 	}
@@ -90,6 +92,6 @@ func (s *service) changePriceBtn(isMinPrice bool) func(f *server.Filter) tele.Bt
 }
 
 func (s *service) priceParamToString(f *server.Filter) string {
-	param := []string{"Price: ", rangeStr(f.MinPrice, f.MaxPrice), " $"}
+	param := []string{"Price: ", rangeStr(f.MinPrice, f.MaxPrice), " ", f.Currency.Symbol()}
 	return strings.Join(param, "")
 }
@@ -23,7 +23,7 @@ func (s *service) changeRoomsInit(isMinRooms bool) initFunc {
 			actionType = changeMaxRooms
 		}
 
-		userID := c.Sender().ID
+		userID := chatID(c)
 		s.userAction.Store(userID, actionType)
 
 		messageText := "Enter new min rooms"
@@ -32,7 +32,7 @@ func (s *service) changeRoomsInit(isMinRooms bool) initFunc {
 		}
 
 		msg := &tele.Message{
-			Sender:      c.Sender(),
+			Chat:        c.Chat(),
 			Text:        messageText,
 			ReplyMarkup: cancelOrResetMarkup(actionType),
 		}
@@ -67,7 +67,7 @@ func (s *service) changeRooms(isMinRooms bool) changeFunc {
 			return err
 		}
 
-		s.userAction.Delete(c.Sender().ID)
+		s.userAction.Delete(chatID(c))
 
 		return s.sendSettingFilter(c, filter)
 	}
@@ -0,0 +1,90 @@
+package tg
+
+import (
+	"strings"
+
+	tele "gopkg.in/telebot.v3"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+// currencyHandler changes the currency of the prices: /currency [usd|gel|eur]
+func (s *service) currencyHandler(c tele.Context) error {
+	userID := chatID(c)
+
+	args := c.Args()
+	if len(args) == 0 {
+		m, err := s.sendMessageToBot(userID, "Currency: "+currencyString(s.service.Currency(s.ctx, userID)))
+		if err != nil {
+			return err
+		}
+
+		s.messages.StoreMessage(userID, m, botMessage)
+		return nil
+	}
+
+	currency, ok := server.ParseCurrency(args[0])
+	if !ok {
+		return errInvalidCurrency
+	}
+
+	user, err := s.service.SetCurrency(s.ctx, &server.User{ID: userID, Currency: currency})
+	if err != nil {
+		return err
+	}
+
+	m, err := s.sendMessageToBot(userID, "Currency: "+currencyString(user.Currency))
+	if err != nil {
+		return err
+	}
+
+	s.messages.StoreMessage(userID, m, botMessage)
+	return nil
+}
+
+func currencyString(c server.Currency) string {
+	c = c.OrUSD()
+	return strings.ToLower(string(c)) + " " + c.Symbol()
+}
+
+// money formats the prices in USD in the currency of the user,
+// the prices are shown in USD while the rate is unknown.
+type money struct {
+	rates    server.Rates
+	currency server.Currency
+}
+
+func (s *service) money(userID int64) money {
+	return money{
+		rates:    s.service.Rates(),
+		currency: s.service.Currency(s.ctx, userID),
+	}
+}
+
+func (m money) String(usd float64) string {
+	amount, currency := m.convert(usd)
+	return currency.Format(amount)
+}
+
+// price formats the price of the apartment, the price set by the owner is shown as is
+// in the currency of the user and follows the converted price in the other currency: 1000$ (2700₾).
+func (m money) price(a server.Apartment) string {
+	if a.OriginalPrice > 0 && a.Currency.OrUSD() == m.currency.OrUSD() {
+		return a.Currency.OrUSD().Format(a.OriginalPrice)
+	}
+
+	amount, currency := m.convert(a.Price)
+	result := currency.Format(amount)
+
+	if a.OriginalPrice > 0 && a.Currency.OrUSD() != currency {
+		result += " (" + a.Currency.OrUSD().Format(a.OriginalPrice) + ")"
+	}
+	return result
+}
+
+func (m money) convert(usd float64) (float64, server.Currency) {
+	if amount, ok := m.rates.Convert(usd, server.USD, m.currency); ok {
+		return amount, m.currency.OrUSD()
+	}
+	return usd, server.USD
+}
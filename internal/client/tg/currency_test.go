@@ -0,0 +1,45 @@
+package tg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+func TestMoneyPrice(t *testing.T) {
+	rates := server.Rates{server.GEL: 2.7}
+
+	testCases := []struct {
+		testCaseName string
+		money        money
+		apartment    server.Apartment
+		expected     string
+	}{
+		{
+			testCaseName: "original price in the user currency",
+			money:        money{rates: rates, currency: server.GEL},
+			apartment:    server.Apartment{Price: 1018.87, OriginalPrice: 2750, Currency: server.GEL},
+			expected:     "2750₾",
+		},
+		{
+			testCaseName: "original price follows the converted price",
+			money:        money{rates: rates, currency: server.USD},
+			apartment:    server.Apartment{Price: 1000, OriginalPrice: 2700, Currency: server.GEL},
+			expected:     "1000$ (2700₾)",
+		},
+		{
+			testCaseName: "price in USD without the rate",
+			money:        money{currency: server.GEL},
+			apartment:    server.Apartment{Price: 1000},
+			expected:     "1000$",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCaseName, func(t *testing.T) {
+			require.Equal(t, tc.expected, tc.money.price(tc.apartment))
+		})
+	}
+}
@@ -17,6 +17,19 @@ import (
 var (
 	errNotFoundHandler  = errors.New("handler not found")
 	errNotFoundLocation = errors.New("location not found\nSend location from Telegram")
+
+	errInvalidViewingTime = errors.New("invalid viewing time\nUse the format: 2024-09-21 18:30")
+	errViewingInPast      = errors.New("the viewing time has already passed")
+
+	errPermissionDenied   = errors.New("permission denied")
+	errInvalidDigest      = errors.New("invalid digest interval\nUse: /digest off, /digest 1h or /digest 24h")
+	errInvalidCurrency    = errors.New("invalid currency\nUse: /currency usd, /currency gel or /currency eur")
+	errInvalidAdminPlan   = errors.New("invalid command\nUse: /admin_plan <chat_id> <plan> [days]")
+	errInvalidAdminUser   = errors.New("invalid command\nUse: /admin_user <chat_id>")
+	errInvalidPayment     = errors.New("the payment doesn't match the price of the plan\nContact the admin")
+	errWebhookSecretToken = errors.New("webhook requires the secret token")
+	errInvalidAdminSwitch = errors.New("invalid command\nUse: /admin_ban <chat_id> [off] or /admin_grant_superuser <chat_id> [off]")
+	errPlanIsNotAvailable = errors.New("the plan is not available")
 )
 
 func (s *service) errorMiddleware(h tele.HandlerFunc) tele.HandlerFunc {
@@ -33,7 +46,7 @@ func (s *service) errorMiddleware(h tele.HandlerFunc) tele.HandlerFunc {
 func (s *service) handleTelegramError(c tele.Context, err error) error {
 	s.service.ErrorHandler(s.ctx, userFromContext(c), err)
 	if err == client.ErrActiveFilterNotFound {
-		s.userAction.Delete(c.Sender().ID)
+		s.userAction.Delete(chatID(c))
 	}
 	if err := s.sendErrorMessage(c, err); err != nil {
 		return err
@@ -43,7 +56,7 @@ func (s *service) handleTelegramError(c tele.Context, err error) error {
 }
 
 func (s *service) sendErrorMessage(c tele.Context, err error) error {
-	userID := c.Sender().ID
+	userID := chatID(c)
 
 	msg := "ERROR: " + err.Error() + "\n/help"
 	if err.Error() == client.ErrActiveFilterNotFound.Error() {
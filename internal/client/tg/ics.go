@@ -0,0 +1,72 @@
+package tg
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+const (
+	icsTimeLayout   = "20060102T150405Z"
+	viewingDuration = time.Hour
+)
+
+var icsEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\n", `\n`,
+)
+
+// viewingsCalendar renders the booked viewings as an iCalendar file (RFC 5545).
+func viewingsCalendar(marks []server.ApartmentMark, now time.Time, m money) []byte {
+	var b strings.Builder
+
+	writeLine := func(format string, args ...any) {
+		b.WriteString(fmt.Sprintf(format, args...))
+		b.WriteString("\r\n")
+	}
+
+	writeLine("BEGIN:VCALENDAR")
+	writeLine("VERSION:2.0")
+	writeLine("PRODID:-//apartment-bot//viewings//EN")
+	writeLine("CALSCALE:GREGORIAN")
+
+	for _, mark := range marks {
+		if mark.ViewingAt == nil {
+			continue
+		}
+
+		summary := fmt.Sprintf("Viewing %d", mark.ApartmentID)
+		var location, url string
+		if a := mark.Apartment; a != nil {
+			summary = fmt.Sprintf("Viewing: %.0f rooms, %.1f m2, %s", a.Rooms, a.Area, m.String(a.Price))
+			location = strings.Trim(a.District+", "+a.City, ", ")
+			url = a.URL
+		}
+
+		description := strings.TrimSpace(url + "\n" + mark.Note)
+
+		writeLine("BEGIN:VEVENT")
+		writeLine("UID:%d-%d@apartment-bot", mark.UserID, mark.ApartmentID)
+		writeLine("DTSTAMP:%s", now.UTC().Format(icsTimeLayout))
+		writeLine("DTSTART:%s", mark.ViewingAt.UTC().Format(icsTimeLayout))
+		writeLine("DTEND:%s", mark.ViewingAt.Add(viewingDuration).UTC().Format(icsTimeLayout))
+		writeLine("SUMMARY:%s", icsEscaper.Replace(summary))
+		if location != "" {
+			writeLine("LOCATION:%s", icsEscaper.Replace(location))
+		}
+		if description != "" {
+			writeLine("DESCRIPTION:%s", icsEscaper.Replace(description))
+		}
+		if url != "" {
+			writeLine("URL:%s", url)
+		}
+		writeLine("END:VEVENT")
+	}
+
+	writeLine("END:VCALENDAR")
+	return []byte(b.String())
+}
@@ -0,0 +1,93 @@
+package tg
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+func TestViewingsCalendar(t *testing.T) {
+	now := time.Date(2024, 9, 20, 10, 0, 0, 0, time.UTC)
+	viewingAt := time.Date(2024, 9, 21, 18, 30, 0, 0, viewingLocation)
+
+	marks := []server.ApartmentMark{
+		{
+			UserID:      1,
+			ApartmentID: 100,
+			Note:        "ask about parking, pets",
+			ViewingAt:   &viewingAt,
+			Apartment: &server.Apartment{
+				Rooms:    2,
+				Area:     55,
+				Price:    700,
+				District: "Vake",
+				City:     "Tbilisi",
+				URL:      "https://example.com/100",
+			},
+		},
+		{
+			UserID:      1,
+			ApartmentID: 200,
+		},
+	}
+
+	calendar := string(viewingsCalendar(marks, now, money{}))
+	lines := strings.Split(strings.TrimSuffix(calendar, "\r\n"), "\r\n")
+
+	require.Equal(t, []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//apartment-bot//viewings//EN",
+		"CALSCALE:GREGORIAN",
+		"BEGIN:VEVENT",
+		"UID:1-100@apartment-bot",
+		"DTSTAMP:20240920T100000Z",
+		"DTSTART:20240921T143000Z",
+		"DTEND:20240921T153000Z",
+		"SUMMARY:Viewing: 2 rooms\\, 55.0 m2\\, 700$",
+		"LOCATION:Vake\\, Tbilisi",
+		"DESCRIPTION:https://example.com/100\\nask about parking\\, pets",
+		"URL:https://example.com/100",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, lines)
+}
+
+func TestParseViewingTime(t *testing.T) {
+	now := time.Date(2024, 9, 20, 10, 0, 0, 0, viewingLocation)
+
+	testCases := []struct {
+		testCaseName  string
+		text          string
+		expected      time.Time
+		expectedError error
+	}{
+		{
+			testCaseName: "valid",
+			text:         " 2024-09-21 18:30 ",
+			expected:     time.Date(2024, 9, 21, 18, 30, 0, 0, viewingLocation),
+		},
+		{
+			testCaseName:  "invalid format",
+			text:          "tomorrow",
+			expectedError: errInvalidViewingTime,
+		},
+		{
+			testCaseName:  "in the past",
+			text:          "2024-09-19 18:30",
+			expectedError: errViewingInPast,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCaseName, func(t *testing.T) {
+			viewingAt, err := parseViewingTime(tc.text, now)
+			require.Equal(t, tc.expectedError, err)
+			require.True(t, tc.expected.Equal(viewingAt))
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package tg
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	tele "gopkg.in/telebot.v3"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+const inlineCacheTime = 60
+
+// inlineQueryHandler searches the stored apartments by the text of the inline query,
+// the errors aren't sent to the chat because the user may not talk to the bot.
+func (s *service) inlineQueryHandler(c tele.Context) error {
+	q := c.Query()
+
+	offset, _ := strconv.ParseInt(q.Offset, 10, 64)
+	page, err := s.service.SearchApartments(s.ctx, userFromContext(c), q.Text, offset)
+	if err != nil {
+		slog.Error("search apartments", "user_id", q.Sender.ID, "err", err)
+		page = &server.ApartmentPage{}
+	}
+
+	m := s.money(q.Sender.ID)
+	results := make(tele.Results, 0, len(page.Apartments))
+	for _, a := range page.Apartments {
+		results = append(results, inlineResult(a, m))
+	}
+
+	response := &tele.QueryResponse{
+		Results:    results,
+		CacheTime:  inlineCacheTime,
+		IsPersonal: true,
+	}
+	if page.NextOffset != 0 {
+		response.NextOffset = strconv.FormatInt(page.NextOffset, 10)
+	}
+
+	return c.Answer(response)
+}
+
+func inlineResult(a server.Apartment, m money) tele.Result {
+	result := &tele.ArticleResult{
+		Title:       inlineTitle(a, m),
+		Description: fmt.Sprintf("%s, %s", a.District, a.City),
+		Text:        apartmentString(a, 0, nil, m),
+		URL:         a.URL,
+	}
+	result.SetResultID(strconv.FormatInt(a.ID, 10))
+
+	if len(a.PhotoURLs) != 0 {
+		result.ThumbURL = replaceURLSingleSlash(a.PhotoURLs[0])
+	}
+	return result
+}
+
+func inlineTitle(a server.Apartment, m money) string {
+	return fmt.Sprintf("%s · %.0f rooms · %.0f m2", m.String(a.Price), a.Rooms, a.Area)
+}
@@ -10,13 +10,26 @@ var (
 	filterSetting = "filter_setting"
 )
 
-func (s *service) filterSettingMarkup(f *server.Filter, settingPageIdx int) *tele.ReplyMarkup {
+func (s *service) filterSettingMarkup(f *server.Filter, settingPageIdx int, userID int64) *tele.ReplyMarkup {
 	filterMarkup := &tele.ReplyMarkup{
 		ForceReply: true,
 	}
+
+	// subscribers of a shared filter can only leave it
+	if f.User != nil && !f.OwnedBy(userID) {
+		filterMarkup.Inline(tele.Row{cancelInlineBtn(), leaveInlineBtn(f.ID)})
+		return filterMarkup
+	}
+
 	settingRows := s.settingsRows(f, settingPageIdx)
 	controlRow := s.controlRow(f, settingPageIdx)
-	filterMarkup.Inline(append(settingRows, controlRow)...)
+	rows := append(settingRows, controlRow)
+
+	if f.ID != "" && f.PauseTimestamp == nil {
+		rows = append(rows, tele.Row{shareInlineBtn(f.ID)})
+	}
+
+	filterMarkup.Inline(rows...)
 	return filterMarkup
 }
 
@@ -26,9 +39,13 @@ func (s *service) settingsRows(f *server.Filter, settingPageIdx int) []tele.Row
 	for _, rowBtn := range s.settingBtns[settingPageIdx] {
 		settingRow := make(tele.Row, 0, len(rowBtn))
 		for _, btn := range rowBtn {
-			settingRow = append(settingRow, btn(f))
+			if b := btn(f); b.Text != "" {
+				settingRow = append(settingRow, b)
+			}
+		}
+		if len(settingRow) != 0 {
+			settings = append(settings, settingRow)
 		}
-		settings = append(settings, settingRow)
 	}
 
 	return settings
@@ -43,8 +60,11 @@ func (s *service) controlRow(f *server.Filter, settingPageIdx int) tele.Row {
 	}
 
 	stateBtn := stateInlineBtn(f)
-	nextPageBtn := nextInlineBtn(settingPageIdx, len(s.settingBtns), filterSetting)
-	controlRow = append(controlRow, stateBtn, nextPageBtn)
+	controlRow = append(controlRow, stateBtn)
+
+	if nextPageBtn := nextInlineBtn(settingPageIdx, len(s.settingBtns), filterSetting); nextPageBtn.Text != "" {
+		controlRow = append(controlRow, nextPageBtn)
+	}
 
 	if f.IsUpdate {
 		okBtn := okInlineBtn()
@@ -0,0 +1,211 @@
+package tg
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	tele "gopkg.in/telebot.v3"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+const (
+	btnApartmentUnavailable = "btn_apartment_unavailable"
+)
+
+func (s *service) notificationRuntime(notificationCh <-chan server.Notification) {
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case n, ok := <-notificationCh:
+			if !ok {
+				return
+			}
+			s.sendNotification(n)
+		}
+	}
+}
+
+func (s *service) sendNotification(n server.Notification) {
+	switch n.Type {
+	case server.ProviderStateNotification:
+		s.sendProviderAlert(n)
+		return
+	case server.DigestNotification:
+		s.sendDigest(n)
+		return
+	}
+
+	userID := n.Mark.UserID
+	if !s.service.IsAllow(userID) {
+		return
+	}
+
+	var msg string
+	switch n.Type {
+	case server.ViewingReminderNotification:
+		msg = "⏰ Viewing in an hour\n" + viewingString(n.Mark, s.money(userID))
+	case server.ApartmentRemovedNotification:
+		s.markApartmentUnavailable(n)
+		msg = s.removedApartmentString(n.Mark)
+	}
+
+	if msg == "" {
+		return
+	}
+
+	if _, err := s.sendMessageToBot(userID, msg, tele.NoPreview); err != nil {
+		s.handleError(userID, err)
+	}
+}
+
+// sendDigest sends the apartments collected for the user as one message, the message is split
+// when it is too long. The deliveries are saved without the message, the digest isn't edited on removal.
+func (s *service) sendDigest(n server.Notification) {
+	userID := n.Mark.UserID
+	if !s.service.IsAllow(userID) {
+		return
+	}
+
+	m := s.money(userID)
+	items := make([]string, 0, len(n.Apartments))
+	apartmentIDs := make([]int64, 0, len(n.Apartments))
+	for _, a := range n.Apartments {
+		filters := s.service.WorkingFilters(userID, a.Filter[userID])
+		if len(filters) == 0 {
+			continue
+		}
+
+		items = append(items, digestApartmentString(len(items)+1, a, filters, m))
+		apartmentIDs = append(apartmentIDs, a.ID)
+	}
+
+	if len(items) == 0 {
+		return
+	}
+
+	var msg strings.Builder
+	msg.WriteString(fmt.Sprintf(digestHeaderLayout, len(items)))
+	for _, item := range items {
+		if msg.Len()+len(item) > maxSavedMessageLength {
+			if _, err := s.sendMessageToBot(userID, msg.String(), tele.NoPreview); err != nil {
+				s.handleError(userID, err)
+				return
+			}
+			msg.Reset()
+		}
+		msg.WriteString(item)
+	}
+
+	if _, err := s.sendMessageToBot(userID, msg.String(), tele.NoPreview); err != nil {
+		s.handleError(userID, err)
+		return
+	}
+
+	for _, apartmentID := range apartmentIDs {
+		s.saveDelivery(userID, apartmentID, nil)
+	}
+}
+
+func digestApartmentString(idx int, a server.Apartment, filters []string, m money) string {
+	return fmt.Sprintf(
+		digestApartmentTemplate,
+		idx,
+		typeMap[a.AdType],
+		a.Rooms,
+		a.Area,
+		m.price(a),
+		a.District,
+		a.City,
+		a.URL,
+		strings.Join(filters, ", "),
+	)
+}
+
+// markApartmentUnavailable replaces the text and the buttons of the delivered apartment message.
+func (s *service) markApartmentUnavailable(n server.Notification) {
+	if n.Delivery == nil || n.Delivery.MessageID == 0 {
+		return
+	}
+
+	m := tele.StoredMessage{
+		MessageID: strconv.FormatInt(n.Delivery.MessageID, 10),
+		ChatID:    n.Delivery.UserID,
+	}
+
+	markup := &tele.ReplyMarkup{}
+	markup.Inline(tele.Row{{Text: "❌ No longer available", Data: actionData(btnApartmentUnavailable)}})
+
+	text := unavailableApartmentString(n.Mark, s.money(n.Delivery.UserID))
+	if _, err := s.b.Edit(m, text, markup, tele.NoPreview); err != nil {
+		slog.Error("mark apartment unavailable", "user_id", n.Delivery.UserID, "apartment_id", n.Delivery.ApartmentID, "err", err)
+	}
+}
+
+// unavailableApartmentString replaces the listing of the delivered message,
+// the snapshot of the removed apartment is kept so the user still knows what it was.
+func unavailableApartmentString(mark server.ApartmentMark, m money) string {
+	return "❌ No longer available\n" + markApartmentString(mark, m)
+}
+
+// removedApartmentString is sent for saved and tracked apartments,
+// other users are notified only if it is enabled.
+func (s *service) removedApartmentString(mark server.ApartmentMark) string {
+	m := s.money(mark.UserID)
+	switch {
+	case mark.Status.IsActive():
+		return "❌ The apartment from your pipeline is no longer available\n" + markApartmentString(mark, m)
+	case mark.IsSaved:
+		return "❌ The apartment you saved is no longer available\n" + markApartmentString(mark, m)
+	case s.notifyRemovedApartments:
+		return "❌ The apartment you received is no longer available\n" + markApartmentString(mark, m)
+	}
+	return ""
+}
+
+// sendProviderAlert tells the admin that the provider is down or recovered.
+func (s *service) sendProviderAlert(n server.Notification) {
+	if n.Provider == nil {
+		return
+	}
+
+	msg := providerStateString(*n.Provider)
+	if s.adminChatID == 0 {
+		slog.Warn("provider alert", "msg", msg)
+		return
+	}
+
+	if _, err := s.sendMessageToBot(s.adminChatID, msg, tele.NoPreview); err != nil {
+		s.handleError(s.adminChatID, err)
+	}
+}
+
+func providerStateString(st server.ProviderState) string {
+	if st.State == server.BreakerClosed {
+		return fmt.Sprintf(providerRecoveredLayout, st.Provider)
+	}
+	return fmt.Sprintf(providerUnavailableLayout, st.Provider, st.Since.Format(time.DateTime), st.Err)
+}
+
+func (s *service) apartmentUnavailableBtn(c tele.Context) error {
+	return c.Respond(&tele.CallbackResponse{Text: "The apartment was removed by the owner"})
+}
+
+func (s *service) saveDelivery(userID, apartmentID int64, m *tele.Message) {
+	d := server.Delivery{
+		UserID:      userID,
+		ApartmentID: apartmentID,
+	}
+
+	if m != nil {
+		d.MessageID = int64(m.ID)
+	}
+
+	if err := s.service.SaveDelivery(s.ctx, d); err != nil {
+		slog.Error("save delivery", "user_id", userID, "apartment_id", apartmentID, "err", err)
+	}
+}
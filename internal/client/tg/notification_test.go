@@ -0,0 +1,24 @@
+package tg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+func TestUnavailableApartmentString(t *testing.T) {
+	m := money{currency: server.USD}
+
+	msg := unavailableApartmentString(server.ApartmentMark{
+		ApartmentID: 1,
+		Apartment:   &server.Apartment{ID: 1, City: "Tbilisi", Rooms: 2, Area: 60, Price: 700, URL: "https://ss.ge/1"},
+	}, m)
+	require.Contains(t, msg, "❌ No longer available\n")
+	require.Contains(t, msg, "https://ss.ge/1")
+	require.Contains(t, msg, "700$")
+
+	msg = unavailableApartmentString(server.ApartmentMark{ApartmentID: 1}, m)
+	require.Equal(t, "❌ No longer available\nApartment 1\n", msg)
+}
@@ -0,0 +1,54 @@
+package tg
+
+import (
+	"fmt"
+	"strings"
+
+	tele "gopkg.in/telebot.v3"
+
+	"github.com/irbgeo/apartment-bot/internal/client"
+)
+
+// parseFilter starts creating the filter from the text and shows it for confirmation,
+// the parameters the parser didn't understand are changed with the buttons.
+func (s *service) parseFilter(c tele.Context, text string) error {
+	userID := chatID(c)
+
+	f, unparsed, err := s.service.ParseFilter(s.ctx, userFromContext(c), text)
+	if err != nil {
+		return err
+	}
+
+	if err := s.messages.CleanUserMessages(userID); err != nil {
+		return err
+	}
+	s.userAction.Delete(userID)
+
+	msg := parsedFilterMessage
+	if len(unparsed) > 0 {
+		msg += fmt.Sprintf(unparsedWordsLayout, strings.Join(unparsed, ", "))
+	}
+	if f.Name == nil {
+		msg += parsedFilterNameMessage
+	}
+
+	m, err := s.sendMessageToBot(userID, msg)
+	if err != nil {
+		return err
+	}
+	s.messages.StoreMessage(userID, m, botMessage)
+
+	return s.sendSettingFilter(c, f)
+}
+
+// chooseFilterOrParse parses the text as a new filter when it isn't a filter name.
+func (s *service) chooseFilterOrParse(c tele.Context, err error) error {
+	if err.Error() != client.ErrFilterNotFound.Error() || isGroupChat(c) {
+		return err
+	}
+
+	if err := s.parseFilter(c, c.Text()); err != nil {
+		return client.ErrFilterNotFound
+	}
+	return nil
+}
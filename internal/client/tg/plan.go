@@ -0,0 +1,299 @@
+package tg
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	tele "gopkg.in/telebot.v3"
+
+	"github.com/irbgeo/apartment-bot/internal/client/payment"
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+const (
+	btnBuyPlan = "btn_buy_plan"
+
+	digestOffValue = "off"
+	planDateLayout = "2006-01-02"
+)
+
+// plansHandler shows the current plan of the user and the plans to buy.
+func (s *service) plansHandler(c tele.Context) error {
+	userID := chatID(c)
+
+	plans, err := s.service.Plans(s.ctx)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.service.UserInfo(s.ctx, userFromContext(c))
+	if err != nil {
+		return err
+	}
+
+	msg := []string{userPlanString(*user, plans)}
+	markup := &tele.ReplyMarkup{}
+	rows := make([]tele.Row, 0, len(plans))
+	for _, p := range plans {
+		msg = append(msg, s.planString(p))
+
+		if p.Price == 0 {
+			continue
+		}
+		rows = append(rows, tele.Row{{
+			Text: fmt.Sprintf("Buy %s · %d %s", planTitle(p.Name), p.Price, s.payments.Currency()),
+			Data: actionData(btnBuyPlan, p.Name),
+		}})
+	}
+	markup.Inline(rows...)
+
+	m, err := s.sendMessageToBot(userID, strings.Join(msg, "\n\n"), markup)
+	if err != nil {
+		return err
+	}
+
+	s.messages.StoreMessage(userID, m, botMessage)
+	return nil
+}
+
+func (s *service) buyPlanBtn(c tele.Context) error {
+	values := getValue(c)
+	if len(values) == 0 {
+		return errNotFoundHandler
+	}
+
+	p, err := s.plan(values[0])
+	if err != nil {
+		return err
+	}
+
+	if p.Price == 0 {
+		return errPlanIsNotAvailable
+	}
+
+	err = s.payments.SendInvoice(payment.Invoice{
+		ChatID:      chatID(c),
+		Plan:        p.Name,
+		Title:       planTitle(p.Name) + " plan",
+		Description: s.planString(p),
+		Amount:      p.Price,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.Respond()
+}
+
+func (s *service) receiptRuntime(receiptCh <-chan payment.Receipt) {
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case r, ok := <-receiptCh:
+			if !ok {
+				return
+			}
+			if err := s.activatePlan(r); err != nil {
+				slog.Error("activate plan", "chat_id", r.ChatID, "plan", r.Plan, "charge_id", r.ChargeID, "err", err)
+				s.handleError(r.ChatID, err)
+			}
+		}
+	}
+}
+
+// activatePlan activates the paid plan, the receipt must pay the price of the plan in the currency of the payments.
+func (s *service) activatePlan(r payment.Receipt) error {
+	p, err := s.plan(r.Plan)
+	if err != nil {
+		return err
+	}
+
+	if r.Amount != p.Price || r.Currency != s.payments.Currency() {
+		return errInvalidPayment
+	}
+
+	user, err := s.service.SetUserPlan(s.ctx, server.UserPlan{
+		UserID: r.ChatID,
+		Plan:   p.Name,
+		Period: p.Period,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.sendMessageToBot(r.ChatID, fmt.Sprintf(planActivatedLayout, planTitle(p.Name), planExpiresString(*user)))
+	return err
+}
+
+// digestHandler changes the digest interval: /digest [off|1h|24h]
+func (s *service) digestHandler(c tele.Context) error {
+	userID := chatID(c)
+
+	args := c.Args()
+	if len(args) == 0 {
+		return s.plansHandler(c)
+	}
+
+	var interval time.Duration
+	if args[0] != digestOffValue {
+		var err error
+		interval, err = time.ParseDuration(args[0])
+		if err != nil || interval < 0 {
+			return errInvalidDigest
+		}
+	}
+
+	user, err := s.service.SetDigest(s.ctx, &server.User{ID: userID, DigestInterval: interval})
+	if err != nil {
+		return err
+	}
+
+	m, err := s.sendMessageToBot(userID, "Digest: "+digestString(user.DigestInterval))
+	if err != nil {
+		return err
+	}
+
+	s.messages.StoreMessage(userID, m, botMessage)
+	return nil
+}
+
+// adminPlanHandler grants the plan to the chat: /admin_plan <chat_id> <plan> [days]
+func (s *service) adminPlanHandler(c tele.Context) error {
+	args := c.Args()
+	if len(args) < 2 {
+		return errInvalidAdminPlan
+	}
+
+	targetID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return errInvalidAdminPlan
+	}
+
+	// the plan without days never expires
+	var period time.Duration
+	if len(args) > 2 {
+		days, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil || days < 0 {
+			return errInvalidAdminPlan
+		}
+		period = time.Duration(days) * 24 * time.Hour
+	}
+
+	user, err := s.service.AdminSetPlan(s.ctx, server.UserPlan{
+		UserID: targetID,
+		Plan:   args[1],
+		Period: period,
+	})
+	if err != nil {
+		return err
+	}
+
+	msg := fmt.Sprintf(planActivatedLayout, planTitle(user.Plan), planExpiresString(*user))
+	if _, err := s.sendMessageToBot(targetID, msg); err != nil {
+		slog.Error("notify about the plan", "chat_id", targetID, "err", err)
+	}
+
+	_, err = s.sendMessageToBot(chatID(c), fmt.Sprintf("%d: %s", targetID, msg))
+	return err
+}
+
+func (s *service) plan(name string) (server.Plan, error) {
+	plans, err := s.service.Plans(s.ctx)
+	if err != nil {
+		return server.Plan{}, err
+	}
+
+	for _, p := range plans {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return server.Plan{}, errPlanIsNotAvailable
+}
+
+func userPlanString(u server.User, plans []server.Plan) string {
+	name := u.ActivePlan(time.Now())
+
+	var p server.Plan
+	for _, plan := range plans {
+		if plan.Name == name {
+			p = plan
+		}
+	}
+
+	expires := planExpiresString(u)
+	if name != u.Plan {
+		expires = ""
+	}
+
+	return fmt.Sprintf(userPlanLayout, planTitle(name), expires, u.HistoryReplays, historyLimitString(p), digestString(u.DigestInterval))
+}
+
+func (s *service) planString(p server.Plan) string {
+	price := "free"
+	if p.Price != 0 {
+		price = fmt.Sprintf("%d %s / %d days", p.Price, s.payments.Currency(), int64(p.Period.Hours()/24))
+	}
+
+	delivery := "instant"
+	if p.DeliveryDelay != 0 {
+		delivery = "in " + durationString(p.DeliveryDelay)
+	}
+
+	digests := "no"
+	if len(p.DigestIntervals) != 0 {
+		intervals := make([]string, 0, len(p.DigestIntervals))
+		for _, interval := range p.DigestIntervals {
+			intervals = append(intervals, durationString(interval))
+		}
+		digests = strings.Join(intervals, ", ")
+	}
+
+	return fmt.Sprintf(planLayout, planTitle(p.Name), price, p.MaxFilters, historyLimitString(p), delivery, digests)
+}
+
+func historyLimitString(p server.Plan) string {
+	if p.MaxHistoryReplays == 0 {
+		return "unlimited"
+	}
+	return strconv.FormatInt(p.MaxHistoryReplays, 10)
+}
+
+func planExpiresString(u server.User) string {
+	if u.PlanExpiresAt == nil {
+		return ""
+	}
+	return " till " + u.PlanExpiresAt.Format(planDateLayout)
+}
+
+func planTitle(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+func digestString(interval time.Duration) string {
+	if interval == 0 {
+		return digestOffValue
+	}
+	return "every " + durationString(interval)
+}
+
+// durationString formats the duration the way /digest accepts it: 15m, 1h, 24h.
+func durationString(d time.Duration) string {
+	hours, minutes := int64(d/time.Hour), int64(d%time.Hour/time.Minute)
+
+	result := ""
+	if hours > 0 {
+		result = strconv.FormatInt(hours, 10) + "h"
+	}
+	if minutes > 0 || hours == 0 {
+		result += strconv.FormatInt(minutes, 10) + "m"
+	}
+	return result
+}
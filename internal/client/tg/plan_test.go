@@ -0,0 +1,88 @@
+package tg
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/irbgeo/apartment-bot/internal/client/payment"
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+type fakePlanService struct {
+	apartmentSvc
+}
+
+func (fakePlanService) Plans(context.Context) ([]server.Plan, error) {
+	return []server.Plan{{Name: server.PlusPlan, Price: 100}}, nil
+}
+
+func TestDurationString(t *testing.T) {
+	testCases := []struct {
+		testCaseName string
+		duration     time.Duration
+		expected     string
+	}{
+		{
+			testCaseName: "10 minutes",
+			duration:     10 * time.Minute,
+			expected:     "10m",
+		},
+		{
+			testCaseName: "30 minutes",
+			duration:     30 * time.Minute,
+			expected:     "30m",
+		},
+		{
+			testCaseName: "hour",
+			duration:     time.Hour,
+			expected:     "1h",
+		},
+		{
+			testCaseName: "hour and half",
+			duration:     90 * time.Minute,
+			expected:     "1h30m",
+		},
+		{
+			testCaseName: "day",
+			duration:     24 * time.Hour,
+			expected:     "24h",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCaseName, func(t *testing.T) {
+			require.Equal(t, tc.expected, durationString(tc.duration))
+		})
+	}
+}
+
+func TestActivatePlanChecksReceipt(t *testing.T) {
+	s := &service{
+		ctx:      context.Background(),
+		service:  fakePlanService{},
+		payments: payment.NewFake(),
+	}
+
+	testCases := []struct {
+		testCaseName string
+		receipt      payment.Receipt
+	}{
+		{
+			testCaseName: "amount is less than price",
+			receipt:      payment.Receipt{ChatID: 1, Plan: server.PlusPlan, Amount: 1, Currency: payment.StarsCurrency},
+		},
+		{
+			testCaseName: "other currency",
+			receipt:      payment.Receipt{ChatID: 1, Plan: server.PlusPlan, Amount: 100, Currency: "USD"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCaseName, func(t *testing.T) {
+			require.ErrorIs(t, s.activatePlan(tc.receipt), errInvalidPayment)
+		})
+	}
+}
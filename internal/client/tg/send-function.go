@@ -5,19 +5,25 @@ import (
 	"strconv"
 	"strings"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	tele "gopkg.in/telebot.v3"
 
 	"github.com/irbgeo/apartment-bot/internal/server"
+	"github.com/irbgeo/apartment-bot/internal/tracing"
 )
 
 func (s *service) sendApartment(a server.Apartment) {
+	_, span := tracing.Start(tracing.Extract(s.ctx, a.TraceContext), "tg.sendApartment", attribute.Int64("apartment.id", a.ID))
+	defer span.End()
+
 	for userID, filters := range a.Filter {
 		filters = s.service.WorkingFilters(userID, filters)
 		if len(filters) == 0 {
 			continue
 		}
 
-		messageCount, apartmentAlbum := s.apartmentMessage(a, filters)
+		messageCount, apartmentAlbum := s.apartmentMessage(a, userID, filters)
 		select {
 		case <-s.ctx.Done():
 			return
@@ -29,25 +35,32 @@ func (s *service) sendApartment(a server.Apartment) {
 		}
 
 		var (
-			err     error
-			message any
+			m   *tele.Message
+			err error
 		)
 
 		switch messageCount {
 		case 0:
-			message = apartmentString(a, filters)
+			m, err = s.sendMessageToBot(userID, apartmentString(a, userID, filters, s.money(userID)), s.apartmentMarkup(a.ID, false))
 		default:
-			message = apartmentAlbum
+			// albums can't have inline buttons, so they are sent in a separate message
+			if _, err = s.sendMessageToBot(userID, apartmentAlbum); err == nil {
+				m, err = s.sendMessageToBot(userID, apartmentActionsString(a, s.money(userID)), s.apartmentMarkup(a.ID, false))
+			}
 		}
 
-		_, err = s.sendMessageToBot(userID, message)
 		if err != nil {
+			tracing.Error(span, err)
 			s.handleError(userID, err)
+			continue
 		}
+
+		s.saveDelivery(userID, a.ID, m)
+		span.AddEvent("sent", trace.WithAttributes(attribute.Int64("user.id", userID)))
 	}
 }
 
-func (s *service) apartmentMessage(a server.Apartment, filters []string) (int, tele.Album) {
+func (s *service) apartmentMessage(a server.Apartment, userID int64, filters []string) (int, tele.Album) {
 	var (
 		resultAlbum  tele.Album
 		messageCount int
@@ -71,7 +84,7 @@ func (s *service) apartmentMessage(a server.Apartment, filters []string) (int, t
 		}
 
 		if messageCount == 0 {
-			photo.Caption = apartmentString(a, filters)
+			photo.Caption = apartmentString(a, userID, filters, s.money(userID))
 		}
 		resultAlbum = append(resultAlbum, photo)
 		messageCount++
@@ -93,16 +106,16 @@ func (s *service) sendSettingFilter(c tele.Context, f *server.Filter) error {
 	}
 
 	msg := &tele.Message{
-		Sender:      c.Sender(),
+		Chat:        c.Chat(),
 		Text:        s.filterString(f) + "\n\n📝 You can change:",
-		ReplyMarkup: s.filterSettingMarkup(f, settingPageIdx),
+		ReplyMarkup: s.filterSettingMarkup(f, settingPageIdx, chatID(c)),
 	}
 
 	return s.sendMessage(msg, settingFilterMessage)
 }
 
 func (s *service) sendSavedFilter(c tele.Context, count int64, f *server.Filter) error {
-	userID := c.Sender().ID
+	userID := chatID(c)
 
 	err := s.messages.CleanUserMessages(userID)
 	if err != nil {
@@ -126,18 +139,18 @@ func (s *service) sendSavedFilter(c tele.Context, count int64, f *server.Filter)
 }
 
 func (s *service) sendMessage(msg *tele.Message, messageType MessageType) error {
-	m, isExist, err := s.messages.GetOrCleanTill(msg.Sender.ID, messageType, settingFilterMessage)
+	m, isExist, err := s.messages.GetOrCleanTill(msg.Chat.ID, messageType, settingFilterMessage)
 	if err != nil {
 		return err
 	}
 
 	if !isExist {
-		m, err := s.sendMessageToBot(msg.Sender.ID, msg.Text, msg.ReplyMarkup)
+		m, err := s.sendMessageToBot(msg.Chat.ID, msg.Text, msg.ReplyMarkup)
 		if err != nil {
 			return err
 		}
 
-		s.messages.StoreMessage(msg.Sender.ID, m, messageType)
+		s.messages.StoreMessage(msg.Chat.ID, m, messageType)
 		return nil
 	}
 
@@ -146,7 +159,7 @@ func (s *service) sendMessage(msg *tele.Message, messageType MessageType) error
 		return err
 	}
 
-	s.messages.StoreMessage(msg.Sender.ID, m, messageType)
+	s.messages.StoreMessage(msg.Chat.ID, m, messageType)
 	return nil
 }
 
@@ -163,6 +176,7 @@ var paramsOrder = []string{
 	changeMinArea,
 	changeMaxArea,
 	changeOwnerType,
+	changeMinScore,
 	changeLocation,
 	changeMaxDistance,
 }
@@ -4,29 +4,35 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 	"sync"
 	"time"
 
 	tele "gopkg.in/telebot.v3"
 
 	"github.com/irbgeo/apartment-bot/internal/client"
+	"github.com/irbgeo/apartment-bot/internal/client/payment"
 	"github.com/irbgeo/apartment-bot/internal/server"
 )
 
 type service struct {
-	ctx                 context.Context
-	cancel              context.CancelFunc
-	b                   *tele.Bot
-	service             apartmentSvc
-	adminUsername       string
-	maxPhotoCount       int
-	messageSendInterval time.Duration
-	userAction          sync.Map
-	messages            messageStack
-	params              map[string]param
-	btn                 map[string]changeFunc
-	settingBtns         [][][]func(f *server.Filter) tele.Btn
-	sendMessageCh       chan Message
+	ctx                     context.Context
+	cancel                  context.CancelFunc
+	b                       *tele.Bot
+	service                 apartmentSvc
+	adminUsername           string
+	adminChatID             int64
+	maxPhotoCount           int
+	messageSendInterval     time.Duration
+	notifyRemovedApartments bool
+	userAction              sync.Map
+	messages                messageStack
+	params                  map[string]param
+	btn                     map[string]changeFunc
+	settingBtns             [][][]func(f *server.Filter) tele.Btn
+	pendingApartment        sync.Map
+	sendMessageCh           chan Message
+	payments                paymentProvider
 }
 
 //go:generate mockery --name apartmentSvc --structname ApartmentSvc
@@ -37,6 +43,7 @@ type apartmentSvc interface {
 	ActiveFilter(ctx context.Context, u *server.User) (*server.Filter, error)
 	Filter(ctx context.Context, f *server.Filter) (*server.Filter, error)
 	StartCreatingFilter(ctx context.Context, u *server.User) *server.Filter
+	ParseFilter(ctx context.Context, u *server.User, text string) (*server.Filter, []string, error)
 	ChangeFilterName(ctx context.Context, i *client.ChangeFilterNameInfo) (*server.Filter, error)
 	ChangeBuildingStatusFilter(ctx context.Context, i *client.ChangeBuildingStatusFilterInfo) (*server.Filter, error)
 	ChangeTypeFilter(ctx context.Context, i *client.ChangeAdTypeFilterInfo) (*server.Filter, error)
@@ -49,13 +56,36 @@ type apartmentSvc interface {
 	ChangeFilterMaxDistance(ctx context.Context, i *client.ChangeFilterMaxDistanceInfo) (*server.Filter, error)
 	ChangeStateFilter(ctx context.Context, i *client.ChangeStateFilterInfo) (*server.Filter, error)
 	ChangeOwnerTypeFilter(ctx context.Context, i *client.ChangeOwnerTypeFilterInfo) (*server.Filter, error)
+	ChangeFilterMinScore(ctx context.Context, i *client.ChangeFilterMinScoreInfo) (*server.Filter, error)
 	CancelCreatingFilter(ctx context.Context, u *server.User)
 	SaveFilter(ctx context.Context, i *client.SaveFilterInfo) (*server.Filter, int64, error)
 	DeleteFilter(ctx context.Context, f *server.Filter) error
-	Apartments(ctx context.Context, f *server.Filter) error
+	BrowseApartments(ctx context.Context, b server.ApartmentBrowse) (*server.ApartmentBrowsePage, error)
 	AvailableCities() []string
 	AvailableDistrictsForCity(city string) []string
 	WorkingFilters(userID int64, f []string) []string
+	Stats(ctx context.Context, f server.StatsFilter) (*server.StatsReport, error)
+	MarkApartment(ctx context.Context, c server.ApartmentMarkChange) (*server.ApartmentMark, error)
+	SearchApartments(ctx context.Context, u *server.User, query string, offset int64) (*server.ApartmentPage, error)
+	SavedApartments(ctx context.Context, u *server.User) ([]server.ApartmentMark, error)
+	Viewings(ctx context.Context, u *server.User) ([]server.ApartmentMark, error)
+	NotificationWatcher() <-chan server.Notification
+	SaveDelivery(ctx context.Context, d server.Delivery) error
+	ShareFilter(ctx context.Context, f *server.Filter) (string, error)
+	JoinFilter(ctx context.Context, j server.FilterJoin) (*server.Filter, error)
+	Plans(ctx context.Context) ([]server.Plan, error)
+	UserInfo(ctx context.Context, u *server.User) (*server.User, error)
+	SetUserPlan(ctx context.Context, p server.UserPlan) (*server.User, error)
+	SetDigest(ctx context.Context, u *server.User) (*server.User, error)
+	SetCurrency(ctx context.Context, u *server.User) (*server.User, error)
+	Currency(ctx context.Context, userID int64) server.Currency
+	Rates() server.Rates
+	AdminStats(ctx context.Context) (*server.AdminStats, error)
+	AdminUser(ctx context.Context, u *server.User) (*server.AdminUser, error)
+	AdminSetPlan(ctx context.Context, p server.UserPlan) (*server.User, error)
+	AdminSetSuperuser(ctx context.Context, u *server.User) (*server.User, error)
+	AdminBan(ctx context.Context, u *server.User) (*server.User, error)
+	AdminRefresh(ctx context.Context) error
 
 	IsAllow(userID int64) bool
 
@@ -73,34 +103,56 @@ type messageStack interface {
 	CleanMessagesUntil(userID int64, t MessageType) error
 }
 
+//go:generate mockery --name paymentProvider --structname PaymentProvider
+type paymentProvider interface {
+	SetBot(b *tele.Bot)
+	SendInvoice(i payment.Invoice) error
+	Receipts() <-chan payment.Receipt
+	Currency() string
+}
+
 func NewService(
 	cfg StartConfig,
 	aSvc apartmentSvc,
 	mStack messageStack,
+	payments paymentProvider,
 ) (*service, error) {
+	var poller tele.Poller = &tele.LongPoller{
+		Timeout: 1 * time.Second,
+	}
+	if cfg.Webhook.PublicURL != "" {
+		if cfg.Webhook.SecretToken == "" {
+			return nil, errWebhookSecretToken
+		}
+		poller = newWebhook(cfg.Webhook)
+	}
+
 	b, err := tele.NewBot(tele.Settings{
-		Token: cfg.Token,
-		Poller: &tele.LongPoller{
-			Timeout: 1 * time.Second,
-		},
+		Token:  cfg.Token,
+		Poller: poller,
 	})
 	if err != nil {
 		return nil, err
 	}
 
 	mStack.SetBot(b)
+	payments.SetBot(b)
 
 	t := &service{
-		b:                   b,
-		messages:            mStack,
-		service:             aSvc,
-		adminUsername:       cfg.AdminUsername,
-		maxPhotoCount:       cfg.MaxPhotoCount,
-		messageSendInterval: cfg.MessageSendInterval,
-		sendMessageCh:       make(chan Message),
+		b:                       b,
+		messages:                mStack,
+		service:                 aSvc,
+		adminUsername:           cfg.AdminUsername,
+		adminChatID:             cfg.AdminChatID,
+		maxPhotoCount:           cfg.MaxPhotoCount,
+		messageSendInterval:     cfg.MessageSendInterval,
+		notifyRemovedApartments: cfg.NotifyRemovedApartments,
+		sendMessageCh:           make(chan Message),
+		payments:                payments,
 	}
 
 	t.initParams(cfg.DisabledParameters)
+	t.initSettingBtns()
 	t.initBtns()
 	t.initHandlers()
 
@@ -176,6 +228,19 @@ func (s *service) initParams(disabledParameters []string) {
 			change:   s.changeOwnerType,
 			toString: s.ownerTypeParamToString,
 		},
+		changeMinScore: {
+			init:     s.changeMinScoreInit,
+			change:   s.changeMinScore,
+			toString: s.minScoreParamToString,
+		},
+		noteApartment: {
+			init:   s.noteApartmentInit,
+			change: s.noteApartment,
+		},
+		setViewing: {
+			init:   s.setViewingInit,
+			change: s.setViewing,
+		},
 	}
 
 	for _, param := range disabledParameters {
@@ -183,15 +248,64 @@ func (s *service) initParams(disabledParameters []string) {
 	}
 }
 
+// initSettingBtns splits the buttons of enabled parameters into setting pages.
+func (s *service) initSettingBtns() {
+	type settingBtn struct {
+		param string
+		btn   func(f *server.Filter) tele.Btn
+	}
+
+	pages := [][][]settingBtn{
+		{
+			{{changeName, changeNameBtn}},
+			{{changeAdType, s.changeAdTypeBtn}, {changeBuildingStatus, s.changeBuildingStatusBtn}},
+			{{changeCity, changeCityBtn}, {changeDistrict, s.changeDistrictBtn}},
+			{{changeMinPrice, s.changePriceBtn(true)}, {changeMaxPrice, s.changePriceBtn(false)}},
+		},
+		{
+			{{changeMinRooms, s.changeRoomsBtn(true)}, {changeMaxRooms, s.changeRoomsBtn(false)}},
+			{{changeMinArea, s.changeAreaBtn(true)}, {changeMaxArea, s.changeAreaBtn(false)}},
+			{{changeOwnerType, s.changeOwnerTypeBtn}, {changeMinScore, changeMinScoreBtn}},
+			{{changeLocation, changeLocationBtn}, {changeMaxDistance, changeMaxDistanceBtn}},
+		},
+	}
+
+	s.settingBtns = make([][][]func(f *server.Filter) tele.Btn, 0, len(pages))
+	for _, page := range pages {
+		settingPage := make([][]func(f *server.Filter) tele.Btn, 0, len(page))
+		for _, row := range page {
+			settingRow := make([]func(f *server.Filter) tele.Btn, 0, len(row))
+			for _, b := range row {
+				if _, ok := s.params[b.param]; ok {
+					settingRow = append(settingRow, b.btn)
+				}
+			}
+			if len(settingRow) != 0 {
+				settingPage = append(settingPage, settingRow)
+			}
+		}
+		if len(settingPage) != 0 {
+			s.settingBtns = append(s.settingBtns, settingPage)
+		}
+	}
+}
+
 func (s *service) initBtns() {
 	s.btn = map[string]changeFunc{
-		filterSetting:       s.nextSettingPageBtn,
-		btnCancel:           s.cancelBtn,
-		btnOk:               s.okBtn,
-		btnDelete:           s.deleteBtn,
-		changeStateBtn:      s.changeStateBtn,
-		btnGetOldApartments: s.getOldApartmentsBtn,
-		btnGetNewApartments: s.getNewApartmentsBtn,
+		filterSetting:           s.nextSettingPageBtn,
+		btnCancel:               s.cancelBtn,
+		btnOk:                   s.okBtn,
+		btnDelete:               s.deleteBtn,
+		changeStateBtn:          s.changeStateBtn,
+		btnGetOldApartments:     s.getOldApartmentsBtn,
+		btnGetNewApartments:     s.getNewApartmentsBtn,
+		btnSaveApartment:        s.saveApartmentBtn,
+		btnHideApartment:        s.hideApartmentBtn,
+		btnApartmentStatus:      s.apartmentStatusBtn,
+		btnApartmentUnavailable: s.apartmentUnavailableBtn,
+		btnShareFilter:          s.shareFilterBtn,
+		btnJoinFilter:           s.joinFilterBtn,
+		btnBuyPlan:              s.buyPlanBtn,
 	}
 }
 
@@ -201,16 +315,46 @@ func (s *service) initHandlers() {
 	s.b.Handle(filterCommand, s.startCreatingFilterHandler)
 	s.b.Handle("/get_filters", s.filtersListHandler)
 	s.b.Handle("/help", s.helpHandler)
+	s.b.Handle("/stats", s.statsHandler)
+	s.b.Handle("/saved", s.savedHandler)
+	s.b.Handle("/viewings", s.viewingsHandler)
+	s.b.Handle("/plans", s.plansHandler)
+	s.b.Handle("/digest", s.digestHandler)
+	s.b.Handle("/currency", s.currencyHandler)
+	s.b.Handle(tele.OnQuery, s.inlineQueryHandler)
+
+	if s.adminChatID != 0 {
+		admin := s.b.Group()
+		admin.Use(s.adminMiddleware)
+		admin.Handle("/admin_stats", s.adminStatsHandler)
+		admin.Handle("/admin_user", s.adminUserHandler)
+		admin.Handle("/admin_grant_superuser", s.adminGrantSuperuserHandler)
+		admin.Handle("/admin_ban", s.adminBanHandler)
+		admin.Handle("/admin_refresh", s.adminRefreshHandler)
+		admin.Handle("/admin_plan", s.adminPlanHandler)
+	} else {
+		slog.Warn("admin commands are disabled, the admin chat id isn't set")
+	}
+
 	s.b.Handle(tele.OnCallback, s.callbackHandler)
 	s.b.Handle(tele.OnText, s.messageHandler)
 	s.b.Handle(tele.OnLocation, s.locationHandler)
 }
 
 func (s *service) Start() error {
+	// Telegram doesn't return the updates by long polling while the webhook is set
+	if _, ok := s.b.Poller.(*webhook); !ok {
+		if err := s.b.RemoveWebhook(); err != nil {
+			return err
+		}
+	}
+
 	s.ctx, s.cancel = context.WithCancel(context.Background())
 
 	go s.sendingMessage(s.ctx)
 	go s.apartmentRuntime(s.service.Watcher())
+	go s.notificationRuntime(s.service.NotificationWatcher())
+	go s.receiptRuntime(s.payments.Receipts())
 	go s.b.Start()
 
 	return nil
@@ -257,12 +401,16 @@ func (s *service) sendingMessage(ctx context.Context) {
 }
 
 func (s *service) startChatHandler(c tele.Context) error {
+	if token, ok := strings.CutPrefix(c.Message().Payload, sharePayloadPrefix); ok {
+		return s.sendJoinFilter(c, token)
+	}
+
 	err := s.service.StartChat(s.ctx, userFromContext(c))
 	if err != nil {
 		return err
 	}
 
-	_, err = s.sendMessageToBot(c.Sender().ID, startChatMessage)
+	_, err = s.sendMessageToBot(chatID(c), startChatMessage)
 	if err != nil {
 		return err
 	}
@@ -271,7 +419,11 @@ func (s *service) startChatHandler(c tele.Context) error {
 }
 
 func (s *service) startCreatingFilterHandler(c tele.Context) error {
-	userID := c.Sender().ID
+	if text := c.Message().Payload; text != "" {
+		return s.parseFilter(c, text)
+	}
+
+	userID := chatID(c)
 
 	err := s.messages.CleanUserMessages(userID)
 	if err != nil {
@@ -297,18 +449,18 @@ func (s *service) filtersListHandler(c tele.Context) error {
 		return nil
 	}
 
-	err = s.messages.CleanUserMessages(c.Sender().ID)
+	err = s.messages.CleanUserMessages(chatID(c))
 	if err != nil {
 		return err
 	}
-	s.userAction.Delete(c.Sender().ID)
+	s.userAction.Delete(chatID(c))
 
 	msg := filterListIsEmptyMessage
 	if len(filters) > 0 {
-		msg = "A list of your filters is available\n" + filtersStr(filters)
+		msg = "A list of your filters is available\n" + filtersStr(filters, chatID(c))
 	}
 
-	m, err := s.sendMessageToBot(c.Sender().ID, msg, s.filterMenu(filters))
+	m, err := s.sendMessageToBot(chatID(c), msg, s.filterMenu(filters))
 	if err != nil {
 		return err
 	}
@@ -318,7 +470,7 @@ func (s *service) filtersListHandler(c tele.Context) error {
 }
 
 func (s *service) helpHandler(c tele.Context) error {
-	m, err := s.sendMessageToBot(c.Sender().ID, fmt.Sprintf(helpMessage, s.adminUsername))
+	m, err := s.sendMessageToBot(chatID(c), fmt.Sprintf(helpMessage, s.adminUsername, s.b.Me.Username))
 	if err != nil {
 		return err
 	}
@@ -328,7 +480,7 @@ func (s *service) helpHandler(c tele.Context) error {
 }
 
 func (s *service) callbackHandler(c tele.Context) error {
-	userID := c.Sender().ID
+	userID := chatID(c)
 	actionType := getType(c)
 	actionValue := getValue(c)
 
@@ -353,12 +505,16 @@ func (s *service) callbackHandler(c tele.Context) error {
 }
 
 func (s *service) messageHandler(c tele.Context) error {
-	userID := c.Sender().ID
+	userID := chatID(c)
 
 	s.messages.StoreMessage(userID, c.Message(), userMassage)
 
 	action, isExist := s.userAction.Load(userID)
 	if !isExist {
+		// group members talk to each other, only the filter menu is handled
+		if isGroupChat(c) && !s.isFilterName(c) {
+			return nil
+		}
 		return s.chooseFilter(c)
 	}
 
@@ -366,7 +522,7 @@ func (s *service) messageHandler(c tele.Context) error {
 }
 
 func (s *service) locationHandler(c tele.Context) error {
-	userID := c.Sender().ID
+	userID := chatID(c)
 
 	s.messages.StoreMessage(userID, c.Message(), userMassage)
 
@@ -375,7 +531,7 @@ func (s *service) locationHandler(c tele.Context) error {
 		return s.params[action.(string)].change(c) // nolint: errcheck
 	}
 
-	m, err := s.sendMessageToBot(c.Sender().ID, unknownCommandMessage)
+	m, err := s.sendMessageToBot(chatID(c), unknownCommandMessage)
 	if err != nil {
 		return err
 	}
@@ -384,7 +540,7 @@ func (s *service) locationHandler(c tele.Context) error {
 }
 
 func (s *service) chooseFilter(c tele.Context) error {
-	userID := c.Sender().ID
+	userID := chatID(c)
 	name := c.Text()
 	f := &server.Filter{
 		Name: &name,
@@ -395,7 +551,7 @@ func (s *service) chooseFilter(c tele.Context) error {
 
 	filter, err := s.service.Filter(s.ctx, f)
 	if err != nil {
-		return err
+		return s.chooseFilterOrParse(c, err)
 	}
 
 	err = s.messages.CleanUserMessages(userID)
@@ -403,7 +559,7 @@ func (s *service) chooseFilter(c tele.Context) error {
 		return err
 	}
 
-	s.userAction.Delete(c.Sender().ID)
+	s.userAction.Delete(chatID(c))
 
 	return s.sendSettingFilter(c, filter)
 }
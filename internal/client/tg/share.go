@@ -0,0 +1,123 @@
+package tg
+
+import (
+	"fmt"
+
+	tele "gopkg.in/telebot.v3"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+const (
+	btnShareFilter = "btn_share_filter"
+	btnJoinFilter  = "btn_join_filter"
+	btnLeaveFilter = btnDelete
+
+	sharePayloadPrefix = "share_"
+	subscribeValue     = "0"
+	cloneValue         = "1"
+)
+
+func (s *service) shareFilterBtn(c tele.Context) error {
+	values := getValue(c)
+	if len(values) == 0 {
+		return errNotFoundHandler
+	}
+
+	token, err := s.service.ShareFilter(s.ctx, &server.Filter{
+		ID:   values[0],
+		User: userFromContext(c),
+	})
+	if err != nil {
+		return err
+	}
+
+	payload := sharePayloadPrefix + token
+	msg := fmt.Sprintf(shareFilterMessageLayout, s.b.Me.Username, payload, s.b.Me.Username, payload)
+
+	m, err := s.sendMessageToBot(chatID(c), msg, tele.NoPreview)
+	if err != nil {
+		return err
+	}
+
+	s.messages.StoreMessage(chatID(c), m, botMessage)
+	return c.Respond()
+}
+
+// sendJoinFilter offers to subscribe to the shared filter or to copy it.
+func (s *service) sendJoinFilter(c tele.Context, token string) error {
+	markup := &tele.ReplyMarkup{}
+	markup.Inline(tele.Row{
+		{Text: "👥 Subscribe", Data: actionData(btnJoinFilter, token, subscribeValue)},
+		{Text: "📋 Copy", Data: actionData(btnJoinFilter, token, cloneValue)},
+	})
+
+	m, err := s.sendMessageToBot(chatID(c), joinFilterMessage, markup)
+	if err != nil {
+		return err
+	}
+
+	s.messages.StoreMessage(chatID(c), m, botMessage)
+	return nil
+}
+
+func (s *service) joinFilterBtn(c tele.Context) error {
+	values := getValue(c)
+	if len(values) < 2 {
+		return errNotFoundHandler
+	}
+
+	isClone := values[1] == cloneValue
+	f, err := s.service.JoinFilter(s.ctx, server.FilterJoin{
+		User:  *userFromContext(c),
+		Token: values[0],
+		Clone: isClone,
+	})
+	if err != nil {
+		return err
+	}
+
+	msg := fmt.Sprintf("👥 You are subscribed to #%s, only its owner can change it", *f.Name)
+	if isClone {
+		msg = fmt.Sprintf("📋 #%s is copied to your filters, you can change it", *f.Name)
+	}
+
+	if _, err := s.sendMessageToBot(chatID(c), msg); err != nil {
+		return err
+	}
+
+	if err := c.Delete(); err != nil {
+		return err
+	}
+
+	return s.filtersListHandler(c)
+}
+
+// isFilterName reports whether the message is the name of the chat filter.
+func (s *service) isFilterName(c tele.Context) bool {
+	filters, err := s.service.Filters(s.ctx, userFromContext(c))
+	if err != nil {
+		return false
+	}
+
+	for _, f := range filters {
+		if f.Name != nil && *f.Name == c.Text() {
+			return true
+		}
+	}
+	return false
+}
+
+func shareInlineBtn(filterID string) tele.Btn {
+	return tele.Btn{
+		Text: "🔗 Share",
+		Data: actionData(btnShareFilter, filterID),
+	}
+}
+
+func leaveInlineBtn(filterID string) tele.Btn {
+	return tele.Btn{
+		Text: "🚪 Leave",
+		Data: actionData(btnLeaveFilter, filterID),
+	}
+}
@@ -0,0 +1,186 @@
+package tg
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tele "gopkg.in/telebot.v3"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+var (
+	maxStatsRows = 10
+)
+
+// statsHandler renders market statistics: /stats [city] [district]
+func (s *service) statsHandler(c tele.Context) error {
+	f, title, err := s.statsFilter(c.Args())
+	if err != nil {
+		return err
+	}
+
+	report, err := s.service.Stats(s.ctx, f)
+	if err != nil {
+		return err
+	}
+
+	msg := statsEmptyMessage
+	if len(report.Current) != 0 {
+		msg = statsString(title, f, report, s.money(chatID(c)))
+	}
+
+	m, err := s.sendMessageToBot(chatID(c), msg)
+	if err != nil {
+		return err
+	}
+
+	s.messages.StoreMessage(c.Chat().ID, m, botMessage)
+	return nil
+}
+
+func (s *service) statsFilter(args []string) (server.StatsFilter, string, error) {
+	var f server.StatsFilter
+
+	if len(args) == 0 {
+		allDistricts, allRooms := "", int64(0)
+		f.District = &allDistricts
+		f.Rooms = &allRooms
+		return f, "Cities", nil
+	}
+
+	city, ok := findIgnoreCase(s.service.AvailableCities(), args[0])
+	if !ok {
+		return f, "", fmt.Errorf("unknown city: %s", args[0])
+	}
+	f.City = &city
+
+	if len(args) == 1 {
+		return f, city, nil
+	}
+
+	district, ok := findIgnoreCase(s.service.AvailableDistrictsForCity(city), strings.Join(args[1:], " "))
+	if !ok {
+		return f, "", fmt.Errorf("unknown district: %s", strings.Join(args[1:], " "))
+	}
+	f.District = &district
+
+	return f, city + ", " + district, nil
+}
+
+func statsString(title string, f server.StatsFilter, report *server.StatsReport, m money) string {
+	weekAgo := make(map[string]server.Stats, len(report.WeekAgo))
+	for _, st := range report.WeekAgo {
+		weekAgo[st.Key()] = st
+	}
+
+	byType := make(map[int64][]server.Stats)
+	for _, st := range report.Current {
+		byType[st.AdType] = append(byType[st.AdType], st)
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("📊 %s, %s\n", title, report.Current[0].Date.Format("2 Jan 2006")))
+
+	for _, adType := range []int64{server.RentAdType, server.SaleAdType} {
+		stats := byType[adType]
+		if len(stats) == 0 {
+			continue
+		}
+
+		result.WriteString("\n" + typeMap[adType] + "\n")
+		for _, st := range statsRows(f, stats) {
+			result.WriteString(statsLine(f, st, weekAgo, m) + "\n")
+		}
+	}
+
+	return result.String()
+}
+
+// statsRows chooses the segments to show: cities, or the city or district with room counts and districts.
+func statsRows(f server.StatsFilter, stats []server.Stats) []server.Stats {
+	var total, rooms, districts []server.Stats
+	for _, st := range stats {
+		switch {
+		case f.City == nil:
+			districts = append(districts, st)
+		case f.District == nil && st.District != "" && st.Rooms == 0:
+			districts = append(districts, st)
+		case f.District == nil && st.District != "":
+		case st.Rooms == 0:
+			total = append(total, st)
+		default:
+			rooms = append(rooms, st)
+		}
+	}
+
+	sort.Slice(rooms, func(i, j int) bool {
+		return rooms[i].Rooms < rooms[j].Rooms
+	})
+
+	sort.Slice(districts, func(i, j int) bool {
+		return districts[i].Count > districts[j].Count
+	})
+	districts = districts[:min(len(districts), maxStatsRows)]
+
+	result := append(total, rooms...)
+	return append(result, districts...)
+}
+
+func statsLine(f server.StatsFilter, st server.Stats, weekAgo map[string]server.Stats, m money) string {
+	var name string
+	switch {
+	case f.City == nil:
+		name = st.City
+	case st.Rooms != 0:
+		name = roomsString(st.Rooms)
+	case f.District == nil && st.District != "":
+		name = st.District
+	default:
+		name = "All"
+	}
+
+	return fmt.Sprintf(
+		statsLineTemplate,
+		name,
+		st.Count,
+		m.String(st.MedianPrice),
+		trendString(st, weekAgo),
+		m.String(st.P25Price), m.String(st.P75Price),
+		m.String(st.MedianPricePerMeter),
+		st.MedianDaysOnMarket,
+	)
+}
+
+func roomsString(rooms int64) string {
+	if rooms >= 4 {
+		return fmt.Sprintf("%d+ rooms", rooms)
+	}
+	return fmt.Sprintf("%d rooms", rooms)
+}
+
+// trendString compares the median price with the snapshot of the week before.
+func trendString(st server.Stats, weekAgo map[string]server.Stats) string {
+	prev, ok := weekAgo[st.Key()]
+	if !ok || prev.MedianPrice == 0 {
+		return ""
+	}
+
+	change := (st.MedianPrice - prev.MedianPrice) / prev.MedianPrice * 100
+	mark := "📈"
+	if change < 0 {
+		mark = "📉"
+	}
+
+	return fmt.Sprintf(" %s %+.1f%% w/w", mark, change)
+}
+
+func findIgnoreCase(values []string, value string) (string, bool) {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return v, true
+		}
+	}
+	return "", false
+}
@@ -9,11 +9,33 @@ import (
 )
 
 type StartConfig struct {
-	Token               string
-	DisabledParameters  []string
-	AdminUsername       string
+	Token              string
+	DisabledParameters []string
+	AdminUsername      string
+	// AdminChatID is the user ID of the admin, it receives the alerts of the server and runs
+	// the admin commands. The alerts are only logged and the admin commands are disabled when it's zero
+	AdminChatID         int64
 	MaxPhotoCount       int
 	MessageSendInterval time.Duration
+	// NotifyRemovedApartments sends a message when any delivered apartment is removed,
+	// otherwise only saved and tracked apartments are reported
+	NotifyRemovedApartments bool
+	// Webhook receives the updates by the webhook instead of long polling when the public URL is set
+	Webhook WebhookConfig
+}
+
+// WebhookConfig configures the webhook behind the reverse proxy.
+type WebhookConfig struct {
+	// Listen is the address of the webhook HTTP server
+	Listen string
+	// PublicURL is the URL Telegram sends the updates to
+	PublicURL string
+	// SecretToken is checked in the X-Telegram-Bot-Api-Secret-Token header of every update
+	SecretToken string
+	// CertFile is uploaded to Telegram when the certificate is self-signed
+	CertFile string
+	// KeyFile enables TLS on the listener with CertFile, the proxy terminates TLS otherwise
+	KeyFile string
 }
 
 type MessageType int64
@@ -15,21 +15,38 @@ func (s *service) cleanUserMessages(userID int64) error {
 	return s.messages.CleanUserMessages(userID)
 }
 
-func filtersStr(filters []server.Filter) string {
+func filtersStr(filters []server.Filter, userID int64) string {
 	var result strings.Builder
 	for _, f := range filters {
-		result.WriteString("#" + *f.Name + "\n")
+		result.WriteString("#" + *f.Name)
+		if f.User != nil && !f.OwnedBy(userID) {
+			result.WriteString(" 👥 shared with you")
+		}
+		result.WriteString("\n")
 	}
 	return result.String()
 }
 
 func userFromContext(c tele.Context) *server.User {
 	return &server.User{
-		ID: c.Sender().ID,
+		ID: chatID(c),
+	}
+}
+
+// chatID returns the chat of the update, in group chats the chat is the filter owner.
+func chatID(c tele.Context) int64 {
+	if chat := c.Chat(); chat != nil {
+		return chat.ID
 	}
+	return c.Sender().ID
+}
+
+func isGroupChat(c tele.Context) bool {
+	chat := c.Chat()
+	return chat != nil && (chat.Type == tele.ChatGroup || chat.Type == tele.ChatSuperGroup)
 }
 
-func apartmentString(a server.Apartment, filters []string) string {
+func apartmentString(a server.Apartment, userID int64, filters []string, m money) string {
 	var hashtags strings.Builder
 	for _, name := range filters {
 		hashtags.WriteString("#" + name + "\n")
@@ -53,20 +70,54 @@ func apartmentString(a server.Apartment, filters []string) string {
 		a.URL,
 		typeMap[a.AdType],
 		ownerTypeMap[a.IsOwner],
-		a.Price,
+		m.price(a),
+		estimateString(a.Estimate, m),
 		a.Phone,
 		a.Rooms,
 		a.Bedrooms,
 		a.Floor,
 		a.Area,
-		a.District,
+		districtString(a),
 		a.City,
 		location,
 		comment,
 		day, month, year,
+		scoreString(a.Score[userID]),
 	)
 }
 
+// districtString returns the district with the subdistrict: Mtatsminda, Vera.
+func districtString(a server.Apartment) string {
+	if a.Subdistrict == "" {
+		return a.District
+	}
+	return a.District + ", " + a.Subdistrict
+}
+
+func apartmentActionsString(a server.Apartment, m money) string {
+	return fmt.Sprintf("⬆️ %.0f rooms, %.1f m2, %s", a.Rooms, a.Area, m.price(a))
+}
+
+func estimateString(estimate *server.PriceEstimate, m money) string {
+	if estimate == nil {
+		return ""
+	}
+	return fmt.Sprintf("\nFair price: ~%s (%s)", m.String(estimate.Price), priceLabelMap[estimate.Label])
+}
+
+func scoreString(score server.Score) string {
+	if len(score.Reasons) == 0 {
+		return ""
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("\n⭐ Score: %.0f/100\n", score.Value))
+	for _, reason := range score.Reasons {
+		result.WriteString(reason + "\n")
+	}
+	return result.String()
+}
+
 func actionData(args ...string) string {
 	return strings.Join(args, dataSep)
 }
@@ -20,7 +20,55 @@ var (
 	filterListIsEmptyMessage     = fmt.Sprintf(`You don't have any filters. You will not receive any apartments.
 	If you want to start searching for apartments, create a filter: %s`, filterCommand)
 	helpMessage = `Instructions: https://telegra.ph/Apartments-in-Georgia-bot-04-07
-If you have any questions, contact us at @%s.`
+Create a filter from text: /create_filter 2 rooms in Vake up to 900$, owner only
+Market statistics: /stats [city] [district]
+Saved apartments: /saved
+Search in any chat: @%[2]s 2br vake 800
+Upcoming viewings: /viewings
+Search together: open a filter, press 🔗 Share and send the link to a friend or a group chat
+Plans and limits: /plans
+Daily digest instead of instant messages: /digest [off|1h|24h]
+Prices in lari or euro: /currency [usd|gel|eur]
+If you have any questions, contact us at @%[1]s.`
+	statsEmptyMessage        = "There are no statistics yet, try again later"
+	savedListIsEmptyMessage  = "You don't have saved apartments. Press ⭐ under an apartment to save it"
+	joinFilterMessage        = "You are invited to a shared filter.\nSubscribe to receive its apartments or copy it to change it yourself"
+	shareFilterMessageLayout = `Send this link to share the filter:
+https://t.me/%s?start=%s
+
+Add the bot to a group chat with the filter:
+https://t.me/%s?startgroup=%s`
+	browseListIsEmptyMessage  = "There are no apartments matching the filter yet"
+	viewingListIsEmptyMessage = "You don't have upcoming viewings. Press 📋 under an apartment to book one"
+	userPlanLayout            = `Your plan: %s%s
+History requests today: %d of %s
+Digest: %s`
+	planLayout = `%s — %s
+Filters: %d, history requests per day: %s
+Delivery: %s, digests: %s`
+	digestHeaderLayout = "📬 Digest: %d new apartments\n\n"
+
+	planActivatedLayout = "✅ The %s plan is active%s\nSee /plans"
+
+	parsedFilterMessage     = "📝 The filter is parsed from your text, check it and press ✅ to save"
+	unparsedWordsLayout     = "\nNot recognized: %s. Set them with the buttons below"
+	parsedFilterNameMessage = "\nThe name is not set, add #name to the text or set it with the buttons"
+
+	adminStatsLayout = `Users: %d
+Filters: %d
+Active subscribers: %d
+Listings:
+%s`
+	adminUserLayout = `User %d
+Plan: %s%s
+Superuser: %s, banned: %s, blocked the bot: %s
+Last activity: %s
+Filters:
+%s`
+	adminRefreshMessage = "🔄 Refreshing the apartments, it takes a while"
+
+	providerUnavailableLayout = "🚨 The provider %s is unavailable since %s\n%s\nThe listings aren't updated or deleted until it recovers"
+	providerRecoveredLayout   = "✅ The provider %s is available again"
 
 	creatingFilterMessage = `Let's start creating a filter for apartment hunting! Specify the parameters you need.
 
@@ -42,15 +90,50 @@ var (
 		false: "Agency",
 	}
 
+	priceLabelMap = map[server.PriceLabel]string{
+		server.BelowMarketPrice: "🟢 below market",
+		server.AtMarketPrice:    "🟡 at market",
+		server.AboveMarketPrice: "🔴 above market",
+	}
+
+	pipelineStatusMap = map[server.PipelineStatus]string{
+		server.SeenStatus:          "👀 Seen",
+		server.ContactedStatus:     "📞 Contacted",
+		server.ViewingBookedStatus: "📅 Viewing",
+		server.RejectedStatus:      "❌ Rejected",
+		server.SignedStatus:        "✍️ Signed",
+	}
+
 	maxImageSizeMB = 2.0
 
+	statsLineTemplate = "%s: %d ads, median %s%s, 25-75%%: %s-%s, %s/m², %.0f days on market"
+
+	savedApartmentTemplate = `%d. %s, %.0f rooms, %.1f m2, %s
+%s, %s
+🌐 %s
+%s
+%s
+`
+
+	digestApartmentTemplate = `%d. %s, %.0f rooms, %.1f m2, %s
+%s, %s
+🌐 %s
+Filters: %s
+
+`
+
+	markApartmentTemplate = `%s, %.0f rooms, %.1f m2, %s
+%s, %s
+🌐 %s
+%s`
+
 	apartmentStrTemplate = `
 %s
 🌐 %s
 Type: %s
 From: %s
 
-Price: %.1f$
+Price: %s%s
 ☎️ +995%s
 
 Rooms: %.0f
@@ -65,5 +148,5 @@ City: %s
 %s 
 
 Date: %d %s %d
-`
+%s`
 )
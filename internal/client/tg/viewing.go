@@ -0,0 +1,264 @@
+package tg
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tele "gopkg.in/telebot.v3"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+const (
+	btnApartmentStatus = "btn_apartment_status"
+	setViewing         = "set_viewing"
+
+	viewingTimeLayout = "2006-01-02 15:04"
+	backStatusValue   = "0"
+)
+
+var (
+	// viewingLocation is the time zone of the viewings, all cities are in Georgia
+	viewingLocation = time.FixedZone("GET", 4*60*60)
+)
+
+// apartmentStatusBtn shows the pipeline statuses of the apartment: id:isSaved,
+// and changes the status: id:isSaved:status.
+func (s *service) apartmentStatusBtn(c tele.Context) error {
+	values := getValue(c)
+	if len(values) < 2 {
+		return errNotFoundHandler
+	}
+
+	apartmentID, err := strconv.ParseInt(values[0], 10, 64)
+	if err != nil {
+		return err
+	}
+	isSaved := values[1] == "1"
+
+	if len(values) == 2 {
+		if _, err := s.b.EditReplyMarkup(c.Message(), s.apartmentStatusMarkup(apartmentID, isSaved)); err != nil {
+			return err
+		}
+		return c.Respond()
+	}
+
+	if values[2] == backStatusValue {
+		if _, err := s.b.EditReplyMarkup(c.Message(), s.apartmentMarkup(apartmentID, isSaved)); err != nil {
+			return err
+		}
+		return c.Respond()
+	}
+
+	st, err := strconv.ParseInt(values[2], 10, 64)
+	if err != nil {
+		return err
+	}
+	status := server.PipelineStatus(st)
+
+	mark, err := s.service.MarkApartment(s.ctx, server.ApartmentMarkChange{
+		UserID:      chatID(c),
+		ApartmentID: apartmentID,
+		Status:      &status,
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.b.EditReplyMarkup(c.Message(), s.apartmentMarkup(apartmentID, mark.IsSaved)); err != nil {
+		return err
+	}
+
+	return c.Respond(&tele.CallbackResponse{Text: "Status: " + pipelineStatusMap[status]})
+}
+
+func (s *service) apartmentStatusMarkup(apartmentID int64, isSaved bool) *tele.ReplyMarkup {
+	id := strconv.FormatInt(apartmentID, 10)
+	saved := "0"
+	if isSaved {
+		saved = "1"
+	}
+
+	statusBtn := func(status server.PipelineStatus) tele.Btn {
+		return tele.Btn{
+			Text: pipelineStatusMap[status],
+			Data: actionData(btnApartmentStatus, id, saved, strconv.FormatInt(int64(status), 10)),
+		}
+	}
+
+	viewingRow := tele.Row{statusBtn(server.RejectedStatus), statusBtn(server.SignedStatus)}
+	if _, ok := s.params[setViewing]; ok {
+		viewingRow = append(tele.Row{{Text: pipelineStatusMap[server.ViewingBookedStatus], Data: actionData(setViewing, id)}}, viewingRow...)
+	}
+
+	m := &tele.ReplyMarkup{}
+	m.Inline(
+		tele.Row{statusBtn(server.SeenStatus), statusBtn(server.ContactedStatus)},
+		viewingRow,
+		tele.Row{{Text: "⬅️ Back", Data: actionData(btnApartmentStatus, id, saved, backStatusValue)}},
+	)
+	return m
+}
+
+func (s *service) setViewingInit(c tele.Context) error {
+	userID := chatID(c)
+
+	if values := getValue(c); len(values) != 0 && values[0] != anyValue {
+		apartmentID, err := strconv.ParseInt(values[0], 10, 64)
+		if err != nil {
+			return err
+		}
+		s.pendingApartment.Store(userID, apartmentID)
+	}
+
+	s.userAction.Store(userID, setViewing)
+
+	msg := fmt.Sprintf("Enter the viewing date and time (Tbilisi time), for example: %s\nPress reset to cancel the viewing", time.Now().In(viewingLocation).Add(24*time.Hour).Format(viewingTimeLayout))
+	m, err := s.sendMessageToBot(userID, msg, cancelOrResetMarkup(setViewing))
+	if err != nil {
+		return err
+	}
+
+	s.messages.StoreMessage(userID, m, actionMessage)
+	return nil
+}
+
+func (s *service) setViewing(c tele.Context) error {
+	userID := chatID(c)
+
+	values := getValue(c)
+	if c.Callback() != nil && (len(values) == 0 || values[0] != anyValue) {
+		return s.setViewingInit(c)
+	}
+
+	apartmentID, ok := s.pendingApartment.Load(userID)
+	if !ok {
+		s.userAction.Delete(userID)
+		return errNotFoundHandler
+	}
+
+	var viewingAt time.Time
+	if c.Callback() == nil {
+		var err error
+		viewingAt, err = parseViewingTime(c.Text(), time.Now())
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := s.service.MarkApartment(s.ctx, server.ApartmentMarkChange{
+		UserID:      userID,
+		ApartmentID: apartmentID.(int64), // nolint: errcheck
+		ViewingAt:   &viewingAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.userAction.Delete(userID)
+	s.pendingApartment.Delete(userID)
+
+	text := "📅 Viewing is booked for " + viewingAt.In(viewingLocation).Format(viewingTimeLayout) + ", see /viewings"
+	if viewingAt.IsZero() {
+		text = "📅 Viewing is cancelled"
+	}
+
+	m, err := s.sendMessageToBot(userID, text)
+	if err != nil {
+		return err
+	}
+
+	s.messages.StoreMessage(userID, m, botMessage)
+	return nil
+}
+
+func parseViewingTime(text string, now time.Time) (time.Time, error) {
+	viewingAt, err := time.ParseInLocation(viewingTimeLayout, strings.TrimSpace(text), viewingLocation)
+	if err != nil {
+		return time.Time{}, errInvalidViewingTime
+	}
+
+	if !viewingAt.After(now) {
+		return time.Time{}, errViewingInPast
+	}
+	return viewingAt, nil
+}
+
+// viewingsHandler lists the upcoming viewings and attaches them as a calendar file.
+func (s *service) viewingsHandler(c tele.Context) error {
+	userID := chatID(c)
+
+	marks, err := s.service.Viewings(s.ctx, userFromContext(c))
+	if err != nil {
+		return err
+	}
+
+	if len(marks) == 0 {
+		m, err := s.sendMessageToBot(userID, viewingListIsEmptyMessage)
+		if err != nil {
+			return err
+		}
+		s.messages.StoreMessage(userID, m, botMessage)
+		return nil
+	}
+
+	sort.Slice(marks, func(i, j int) bool {
+		return marks[i].ViewingAt.Before(*marks[j].ViewingAt)
+	})
+
+	m := s.money(userID)
+	var msg strings.Builder
+	for _, mark := range marks {
+		msg.WriteString(viewingString(mark, m))
+		msg.WriteString("\n")
+	}
+
+	if _, err := s.sendMessageToBot(userID, msg.String(), tele.NoPreview); err != nil {
+		return err
+	}
+
+	calendar := &tele.Document{
+		File:     tele.FromReader(bytes.NewReader(viewingsCalendar(marks, time.Now(), m))),
+		FileName: "viewings.ics",
+		MIME:     "text/calendar",
+	}
+
+	_, err = s.sendMessageToBot(userID, calendar)
+	return err
+}
+
+func viewingString(mark server.ApartmentMark, m money) string {
+	var viewingAt string
+	if mark.ViewingAt != nil {
+		viewingAt = "📅 " + mark.ViewingAt.In(viewingLocation).Format("Mon 02 Jan 15:04") + "\n"
+	}
+	return viewingAt + markApartmentString(mark, m)
+}
+
+func markApartmentString(mark server.ApartmentMark, m money) string {
+	if mark.Apartment == nil {
+		return fmt.Sprintf("Apartment %d\n", mark.ApartmentID)
+	}
+	a := mark.Apartment
+
+	var note string
+	if mark.Note != "" {
+		note = "📝 " + mark.Note + "\n"
+	}
+
+	return fmt.Sprintf(
+		markApartmentTemplate,
+		typeMap[a.AdType],
+		a.Rooms,
+		a.Area,
+		m.price(*a),
+		a.District,
+		a.City,
+		a.URL,
+		note,
+	)
+}
@@ -0,0 +1,109 @@
+package tg
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+const (
+	secretTokenHeader      = "X-Telegram-Bot-Api-Secret-Token"
+	webhookShutdownTimeout = 5 * time.Second
+)
+
+// webhook is the poller which receives the updates from Telegram by HTTP.
+// The webhook stays registered after the stop, so Telegram keeps the updates till the restart.
+type webhook struct {
+	cfg     WebhookConfig
+	updates chan tele.Update
+}
+
+func newWebhook(cfg WebhookConfig) *webhook {
+	return &webhook{
+		cfg:     cfg,
+		updates: make(chan tele.Update),
+	}
+}
+
+func (w *webhook) Poll(b *tele.Bot, dest chan tele.Update, stop chan struct{}) {
+	if err := b.SetWebhook(w.telegramWebhook()); err != nil {
+		b.OnError(err, nil)
+		<-stop
+		return
+	}
+
+	srv := &http.Server{
+		Addr:              w.cfg.Listen,
+		Handler:           w,
+		ReadHeaderTimeout: webhookShutdownTimeout,
+	}
+
+	go func() {
+		var err error
+		if w.cfg.KeyFile != "" {
+			err = srv.ListenAndServeTLS(w.cfg.CertFile, w.cfg.KeyFile)
+		} else {
+			err = srv.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			b.OnError(err, nil)
+		}
+	}()
+
+	for {
+		select {
+		case u := <-w.updates:
+			dest <- u
+		case <-stop:
+			ctx, cancel := context.WithTimeout(context.Background(), webhookShutdownTimeout)
+			defer cancel()
+
+			if err := srv.Shutdown(ctx); err != nil {
+				slog.Error("shutdown webhook", "err", err)
+			}
+			return
+		}
+	}
+}
+
+// ServeHTTP checks the secret token and passes the update to the bot.
+func (w *webhook) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		rw.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	if w.cfg.SecretToken == "" ||
+		subtle.ConstantTimeCompare([]byte(r.Header.Get(secretTokenHeader)), []byte(w.cfg.SecretToken)) != 1 {
+		rw.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var u tele.Update
+	if err := json.NewDecoder(r.Body).Decode(&u); err != nil {
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	select {
+	case w.updates <- u:
+	case <-r.Context().Done():
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}
+}
+
+func (w *webhook) telegramWebhook() *tele.Webhook {
+	return &tele.Webhook{
+		SecretToken: w.cfg.SecretToken,
+		Endpoint: &tele.WebhookEndpoint{
+			PublicURL: w.cfg.PublicURL,
+			Cert:      w.cfg.CertFile,
+		},
+	}
+}
@@ -0,0 +1,169 @@
+package tg
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	tele "gopkg.in/telebot.v3"
+)
+
+const testSecretToken = "secret"
+
+func TestWebhookServeHTTP(t *testing.T) {
+	testCases := []struct {
+		testCaseName   string
+		method         string
+		secretToken    string
+		body           string
+		expectedStatus int
+		expectedUpdate bool
+	}{
+		{
+			testCaseName:   "update",
+			method:         http.MethodPost,
+			secretToken:    testSecretToken,
+			body:           `{"update_id":1,"message":{"message_id":1,"text":"/start","chat":{"id":10}}}`,
+			expectedStatus: http.StatusOK,
+			expectedUpdate: true,
+		},
+		{
+			testCaseName:   "invalid secret token",
+			method:         http.MethodPost,
+			secretToken:    "invalid",
+			body:           `{"update_id":1}`,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			testCaseName:   "without secret token",
+			method:         http.MethodPost,
+			body:           `{"update_id":1}`,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			testCaseName:   "invalid body",
+			method:         http.MethodPost,
+			secretToken:    testSecretToken,
+			body:           `{`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			testCaseName:   "invalid method",
+			method:         http.MethodGet,
+			secretToken:    testSecretToken,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCaseName, func(t *testing.T) {
+			w := newWebhook(WebhookConfig{SecretToken: testSecretToken})
+
+			received := make(chan tele.Update, 1)
+			go func() {
+				if tc.expectedUpdate {
+					received <- <-w.updates
+				}
+			}()
+
+			r := httptest.NewRequest(tc.method, "/", strings.NewReader(tc.body))
+			r.Header.Set(secretTokenHeader, tc.secretToken)
+			rw := httptest.NewRecorder()
+			w.ServeHTTP(rw, r)
+
+			require.Equal(t, tc.expectedStatus, rw.Code)
+			if tc.expectedUpdate {
+				u := <-received
+				require.Equal(t, 1, u.ID)
+				require.Equal(t, "/start", u.Message.Text)
+			}
+		})
+	}
+}
+
+func TestWebhookPoll(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		params    = make(map[string]string)
+		decodeErr error
+	)
+	telegramAPI := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/setWebhook") {
+			mu.Lock()
+			decodeErr = json.NewDecoder(r.Body).Decode(&params)
+			mu.Unlock()
+		}
+		_, _ = rw.Write([]byte(`{"ok":true,"result":true}`))
+	}))
+	defer telegramAPI.Close()
+
+	w := newWebhook(WebhookConfig{
+		Listen:      "127.0.0.1:0",
+		PublicURL:   "https://bot.example.com/webhook",
+		SecretToken: testSecretToken,
+	})
+	b, err := tele.NewBot(tele.Settings{
+		URL:     telegramAPI.URL,
+		Token:   "token",
+		Poller:  w,
+		Offline: true,
+	})
+	require.NoError(t, err)
+
+	handled := make(chan string, 1)
+	b.Handle("/start", func(c tele.Context) error {
+		handled <- c.Text()
+		return nil
+	})
+
+	go b.Start()
+	defer b.Stop()
+
+	hook := httptest.NewServer(w)
+	defer hook.Close()
+
+	r, err := http.NewRequest(http.MethodPost, hook.URL, strings.NewReader(
+		`{"update_id":2,"message":{"message_id":2,"text":"/start","chat":{"id":10,"type":"private"},"from":{"id":10}}}`,
+	))
+	require.NoError(t, err)
+	r.Header.Set(secretTokenHeader, testSecretToken)
+
+	resp, err := http.DefaultClient.Do(r)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	select {
+	case text := <-handled:
+		require.Equal(t, "/start", text)
+	case <-time.After(time.Second):
+		t.Fatal("the update isn't handled")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.NoError(t, decodeErr)
+	require.Equal(t, "https://bot.example.com/webhook", params["url"])
+	require.Equal(t, testSecretToken, params["secret_token"])
+}
+
+func TestWebhookWithoutSecretToken(t *testing.T) {
+	w := newWebhook(WebhookConfig{})
+
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"update_id":1}`))
+	rw := httptest.NewRecorder()
+	w.ServeHTTP(rw, r)
+	require.Equal(t, http.StatusUnauthorized, rw.Code)
+}
+
+func TestNewServiceWebhookWithoutSecretToken(t *testing.T) {
+	_, err := NewService(StartConfig{
+		Webhook: WebhookConfig{PublicURL: "https://bot.example.com/webhook"},
+	}, nil, nil, nil)
+	require.ErrorIs(t, err, errWebhookSecretToken)
+}
@@ -12,7 +12,8 @@ type SaveFilterInfo struct {
 }
 
 type channels struct {
-	apartment chan server.Apartment
+	apartment    chan server.Apartment
+	notification chan server.Notification
 }
 
 type storage struct {
@@ -21,6 +22,9 @@ type storage struct {
 	historyReceiving  sync.Map
 	disconnectedUsers sync.Map
 	cities            citiesStorage
+	rates             ratesStorage
+	// currencies are the currencies of the users by id
+	currencies sync.Map
 }
 
 type turnedOffStorage struct {
@@ -28,6 +32,11 @@ type turnedOffStorage struct {
 	filters map[int64]map[string]time.Time
 }
 
+type ratesStorage struct {
+	sync.RWMutex
+	rates server.Rates
+}
+
 type citiesStorage struct {
 	sync.Map
 	firstCities []string
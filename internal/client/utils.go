@@ -56,6 +56,10 @@ func checkFilter(f *server.Filter) error {
 		return errMinAreaMoreThanMaxArea
 	}
 
+	if f.MinScore != nil && (*f.MinScore < 0 || *f.MinScore > 100) {
+		return errInvalidMinScore
+	}
+
 	return nil
 }
 
@@ -19,6 +19,23 @@ func TestCheckFilter(t *testing.T) {
 			filter:        &server.Filter{},
 			expectedError: ErrUnknownFilterName,
 		},
+		{
+			testCaseName: "min score above 100",
+			filter: &server.Filter{
+				IsUpdate: true,
+				Name:     stringPtr("test"),
+				MinScore: floatPtr(101),
+			},
+			expectedError: errInvalidMinScore,
+		},
+		{
+			testCaseName: "valid min score",
+			filter: &server.Filter{
+				IsUpdate: true,
+				Name:     stringPtr("test"),
+				MinScore: floatPtr(60),
+			},
+		},
 		// TODO: остальные кейсы
 	}
 
@@ -0,0 +1,110 @@
+package currency
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+var (
+	// maxRatesAge fails the health check, the stale rates are still used
+	maxRatesAge = 48 * time.Hour
+)
+
+// Source returns the amounts of the currencies for 1 USD.
+type Source interface {
+	Rates(ctx context.Context) (server.Rates, error)
+}
+
+type service struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	source Source
+
+	mu        sync.RWMutex
+	rates     server.Rates
+	updatedAt time.Time
+}
+
+// NewService creates the service of the currency rates, the fallback rates are used
+// until the source responds and for the currencies the source doesn't know.
+func NewService(source Source, fallback server.Rates) *service {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &service{
+		ctx:    ctx,
+		cancel: cancel,
+		source: source,
+		rates:  maps.Clone(fallback),
+	}
+}
+
+// Start updates the rates by the interval, the failed update keeps the previous rates.
+func (s *service) Start(updateInterval time.Duration) error {
+	if err := s.update(); err != nil {
+		slog.Error("update currency rates", "err", err)
+	}
+
+	go func() {
+		ticker := time.NewTicker(updateInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.update(); err != nil {
+					slog.Error("update currency rates", "err", err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (s *service) Stop() {
+	s.cancel()
+}
+
+// Rates returns the copy of the current rates.
+func (s *service) Rates() server.Rates {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return maps.Clone(s.rates)
+}
+
+// Health reports whether the rates were updated recently.
+func (s *service) Health(_ context.Context) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if age := time.Since(s.updatedAt); age > maxRatesAge {
+		return fmt.Errorf("rates were updated %s ago", age.Round(time.Second))
+	}
+	return nil
+}
+
+func (s *service) update() error {
+	rates, err := s.source.Rates(s.ctx)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	maps.Copy(s.rates, rates)
+	s.updatedAt = time.Now()
+
+	slog.Info("currency rates", "rates", s.rates)
+	return nil
+}
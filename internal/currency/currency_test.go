@@ -0,0 +1,77 @@
+package currency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+func TestParseRates(t *testing.T) {
+	testCases := []struct {
+		value         string
+		expectedRates server.Rates
+		expectedErr   error
+	}{
+		{value: "GEL:2.7,EUR:0.92", expectedRates: server.Rates{server.GEL: 2.7, server.EUR: 0.92}},
+		{value: " gel : 2.7 ,", expectedRates: server.Rates{server.GEL: 2.7}},
+		{value: "", expectedRates: server.Rates{}},
+		{value: "GEL=2.7", expectedErr: ErrInvalidRate},
+		{value: "RUB:90", expectedErr: ErrInvalidRate},
+		{value: "GEL:-1", expectedErr: ErrInvalidRate},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			rates, err := ParseRates(tc.value)
+			require.ErrorIs(t, err, tc.expectedErr)
+			require.Equal(t, tc.expectedRates, rates)
+		})
+	}
+}
+
+func TestNBG(t *testing.T) {
+	var currencies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		currencies = r.URL.Query()["currencies"]
+		_, _ = rw.Write([]byte(`[{"date": "2026-10-19T00:00:00.000Z", "currencies": [
+			{"code": "USD", "quantity": 1, "rate": 2.7},
+			{"code": "EUR", "quantity": 1, "rate": 3},
+			{"code": "JPY", "quantity": 100, "rate": 1.8}
+		]}]`))
+	}))
+	defer srv.Close()
+
+	source := NewNBG()
+	source.url = srv.URL
+
+	rates, err := source.Rates(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"USD", "EUR"}, currencies)
+	require.Equal(t, server.Rates{server.GEL: 2.7, server.EUR: 0.9}, rates)
+}
+
+type failingSource struct{}
+
+func (failingSource) Rates(context.Context) (server.Rates, error) {
+	return nil, errors.New("status code: 503")
+}
+
+func TestServiceFallback(t *testing.T) {
+	fallback := server.Rates{server.GEL: 2.7, server.EUR: 0.92}
+
+	s := NewService(failingSource{}, fallback)
+	require.Error(t, s.update())
+	require.Equal(t, fallback, s.Rates())
+	require.Error(t, s.Health(context.Background()))
+
+	s.source = NewStatic(server.Rates{server.GEL: 2.65})
+	require.NoError(t, s.update())
+	require.Equal(t, server.Rates{server.GEL: 2.65, server.EUR: 0.92}, s.Rates())
+	require.NoError(t, s.Health(context.Background()))
+}
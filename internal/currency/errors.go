@@ -0,0 +1,7 @@
+package currency
+
+import "errors"
+
+var (
+	ErrInvalidRate = errors.New("invalid rate, use CODE:amount for 1 USD")
+)
@@ -0,0 +1,95 @@
+package currency
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+var (
+	nbgURL         = "https://nbg.gov.ge/gw/api/ct/monetarypolicy/currencies/en/json/"
+	requestTimeout = 30 * time.Second
+)
+
+// nbg is the online source of the official rates of the National Bank of Georgia,
+// the rates are in lari for the quantity of the currency.
+type nbg struct {
+	client *http.Client
+	url    string
+}
+
+func NewNBG() *nbg {
+	return &nbg{
+		client: &http.Client{Timeout: requestTimeout},
+		url:    nbgURL,
+	}
+}
+
+type nbgResponse struct {
+	Currencies []nbgCurrency `json:"currencies"`
+}
+
+type nbgCurrency struct {
+	Code     string  `json:"code"`
+	Quantity float64 `json:"quantity"`
+	Rate     float64 `json:"rate"`
+}
+
+func (s *nbg) Rates(ctx context.Context) (server.Rates, error) {
+	q := url.Values{}
+	for _, c := range server.Currencies {
+		if c != server.GEL {
+			q.Add("currencies", string(c))
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status code: %d", res.StatusCode)
+	}
+
+	var days []nbgResponse
+	if err := json.NewDecoder(res.Body).Decode(&days); err != nil {
+		return nil, err
+	}
+	if len(days) == 0 {
+		return nil, fmt.Errorf("no rates")
+	}
+
+	// lari for 1 unit of the currency
+	lari := map[server.Currency]float64{server.GEL: 1}
+	for _, c := range days[0].Currencies {
+		code, ok := server.ParseCurrency(c.Code)
+		if ok && c.Rate > 0 && c.Quantity > 0 {
+			lari[code] = c.Rate / c.Quantity
+		}
+	}
+
+	usd, ok := lari[server.USD]
+	if !ok {
+		return nil, fmt.Errorf("no USD rate")
+	}
+
+	rates := make(server.Rates, len(lari))
+	for code, rate := range lari {
+		if code != server.USD {
+			rates[code] = usd / rate
+		}
+	}
+	return rates, nil
+}
@@ -0,0 +1,51 @@
+package currency
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+// static is the offline source of the configured rates.
+type static struct {
+	rates server.Rates
+}
+
+func NewStatic(rates server.Rates) *static {
+	return &static{rates: rates}
+}
+
+func (s *static) Rates(_ context.Context) (server.Rates, error) {
+	return maps.Clone(s.rates), nil
+}
+
+// ParseRates parses the amounts for 1 USD: GEL:2.7,EUR:0.92.
+func ParseRates(value string) (server.Rates, error) {
+	rates := make(server.Rates)
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		code, amount, ok := strings.Cut(pair, ":")
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidRate, pair)
+		}
+
+		c, ok := server.ParseCurrency(code)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown currency %q", ErrInvalidRate, code)
+		}
+
+		rate, err := strconv.ParseFloat(strings.TrimSpace(amount), 64)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidRate, pair)
+		}
+		rates[c] = rate
+	}
+	return rates, nil
+}
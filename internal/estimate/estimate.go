@@ -0,0 +1,117 @@
+package estimate
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+var (
+	// archiveTrainWindow is the age of the removed listings the models are trained on
+	archiveTrainWindow = 90 * 24 * time.Hour
+)
+
+type estimator struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	storage storage
+
+	mu     sync.RWMutex
+	models Models
+}
+
+type storage interface {
+	Apartments(ctx context.Context, f server.Filter) (<-chan server.Apartment, error)
+	ArchivedApartments(ctx context.Context, f server.ArchiveFilter) (<-chan server.ArchivedApartment, error)
+}
+
+// New creates an estimator trained on the stored listings
+func New(s storage) *estimator {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &estimator{
+		ctx:     ctx,
+		cancel:  cancel,
+		storage: s,
+		models:  make(Models),
+	}
+}
+
+func (s *estimator) Start(retrainInterval time.Duration) error {
+	if err := s.train(); err != nil {
+		return err
+	}
+
+	go func() {
+		ticker := time.NewTicker(retrainInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.train(); err != nil {
+					slog.Error("train price models", "err", err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (s *estimator) Stop() {
+	s.cancel()
+}
+
+// Estimate sets the fair price of the apartment if there is a model for its market.
+func (s *estimator) Estimate(a *server.Apartment) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	a.Estimate, _ = s.models.Estimate(*a)
+}
+
+func (s *estimator) train() error {
+	apartmentCh, err := s.storage.Apartments(s.ctx, server.Filter{})
+	if err != nil {
+		return err
+	}
+
+	apartments := make([]server.Apartment, 0)
+	ids := make(map[int64]struct{})
+	for a := range apartmentCh {
+		apartments = append(apartments, a)
+		ids[a.ID] = struct{}{}
+	}
+
+	// the removed listings of the window are trained on too, the relisted ones are active
+	removedFrom := time.Now().Add(-archiveTrainWindow)
+	archivedCh, err := s.storage.ArchivedApartments(s.ctx, server.ArchiveFilter{
+		RemovedFrom: &removedFrom,
+		Reasons:     []server.RemovalReason{server.InactiveRemoval, server.ExpiredRemoval, server.NotFoundRemoval},
+	})
+	if err != nil {
+		return err
+	}
+
+	for a := range archivedCh {
+		if _, ok := ids[a.ID]; !ok {
+			apartments = append(apartments, a.Apartment)
+		}
+	}
+
+	models := Train(apartments)
+	slog.Info("train price models", "apartments", len(apartments), "models", len(models))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.models = models
+	return nil
+}
@@ -0,0 +1,220 @@
+package estimate
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+var (
+	minTrainSize    = 30
+	minDistrictSize = 5
+	lambda          = 1.0
+
+	// belowMarketRatio and aboveMarketRatio are price to estimate ratios labeling the price
+	belowMarketRatio = 0.9
+	aboveMarketRatio = 1.1
+)
+
+// Models are price models by ad type and city.
+type Models map[string]*model
+
+type model struct {
+	scalers   []scaler
+	districts map[string]int
+	coef      []float64
+}
+
+// scaler standardizes a numeric feature
+type scaler struct {
+	mean float64
+	std  float64
+}
+
+// numeric features of the apartment: log area, rooms, floor, latitude, longitude
+const numericCount = 5
+
+// Train fits a log price regression for every ad type and city with enough listings.
+func Train(apartments []server.Apartment) Models {
+	groups := make(map[string][]server.Apartment)
+	for _, a := range apartments {
+		if a.Price <= 0 || a.Area <= 0 || a.City == "" {
+			continue
+		}
+
+		key := modelKey(a.AdType, a.City)
+		groups[key] = append(groups[key], a)
+	}
+
+	models := make(Models, len(groups))
+	for key, group := range groups {
+		if len(group) < minTrainSize {
+			continue
+		}
+
+		if m, ok := train(group); ok {
+			models[key] = m
+		}
+	}
+
+	return models
+}
+
+// Estimate predicts the fair price of the apartment and labels its price against it.
+func (s Models) Estimate(a server.Apartment) (*server.PriceEstimate, bool) {
+	m, ok := s[modelKey(a.AdType, a.City)]
+	if !ok || a.Area <= 0 {
+		return nil, false
+	}
+
+	estimate := &server.PriceEstimate{
+		Price: math.Round(math.Exp(dot(m.coef, m.features(a)))),
+		Label: server.AtMarketPrice,
+	}
+	if estimate.Price <= 0 {
+		return nil, false
+	}
+
+	switch ratio := a.Price / estimate.Price; {
+	case ratio < belowMarketRatio:
+		estimate.Label = server.BelowMarketPrice
+	case ratio > aboveMarketRatio:
+		estimate.Label = server.AboveMarketPrice
+	}
+
+	return estimate, true
+}
+
+func train(apartments []server.Apartment) (*model, bool) {
+	m := &model{
+		scalers:   make([]scaler, numericCount),
+		districts: make(map[string]int),
+	}
+
+	values := make([][]float64, numericCount)
+	districtCount := make(map[string]int)
+	for _, a := range apartments {
+		for i, v := range numeric(a) {
+			if !math.IsNaN(v) {
+				values[i] = append(values[i], v)
+			}
+		}
+		districtCount[a.District]++
+	}
+
+	for i := range values {
+		m.scalers[i] = newScaler(values[i])
+	}
+
+	for district, cnt := range districtCount {
+		if district != "" && cnt >= minDistrictSize {
+			m.districts[district] = len(m.districts)
+		}
+	}
+
+	x := make([][]float64, 0, len(apartments))
+	y := make([]float64, 0, len(apartments))
+	for _, a := range apartments {
+		x = append(x, m.features(a))
+		y = append(y, math.Log(a.Price))
+	}
+
+	coef, ok := ridge(x, y, lambda)
+	if !ok {
+		return nil, false
+	}
+	m.coef = coef
+
+	return m, true
+}
+
+// features returns the intercept, standardized numeric features,
+// building status and district indicators of the apartment.
+func (s *model) features(a server.Apartment) []float64 {
+	result := make([]float64, 0, 1+numericCount+2+len(s.districts))
+	result = append(result, 1)
+
+	for i, v := range numeric(a) {
+		result = append(result, s.scalers[i].scale(v))
+	}
+
+	result = append(result,
+		indicator(a.BuildingStatus == server.NewBuildingStatus),
+		indicator(a.BuildingStatus == server.UnderConstructionBuildingStatus),
+	)
+
+	districts := make([]float64, len(s.districts))
+	if idx, ok := s.districts[a.District]; ok {
+		districts[idx] = 1
+	}
+
+	return append(result, districts...)
+}
+
+// numeric returns NaN for unknown values
+func numeric(a server.Apartment) []float64 {
+	lat, lng := math.NaN(), math.NaN()
+	if a.Coordinates != nil {
+		lat, lng = a.Coordinates.Lat, a.Coordinates.Lng
+	}
+
+	return []float64{
+		math.Log(a.Area),
+		a.Rooms,
+		float64(a.Floor),
+		lat,
+		lng,
+	}
+}
+
+func newScaler(values []float64) scaler {
+	if len(values) == 0 {
+		return scaler{std: 1}
+	}
+
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	var variance float64
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+
+	std := math.Sqrt(variance / float64(len(values)))
+	if std == 0 {
+		std = 1
+	}
+
+	return scaler{mean: mean, std: std}
+}
+
+// scale maps unknown values to the mean
+func (s scaler) scale(v float64) float64 {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0
+	}
+	return (v - s.mean) / s.std
+}
+
+func indicator(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func dot(a, b []float64) float64 {
+	var result float64
+	for i := range a {
+		result += a[i] * b[i]
+	}
+	return result
+}
+
+func modelKey(adType int64, city string) string {
+	return strconv.FormatInt(adType, 10) + ":" + city
+}
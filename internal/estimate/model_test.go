@@ -0,0 +1,101 @@
+package estimate
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+func TestTrain(t *testing.T) {
+	models := Train(fixture(t))
+
+	require.Len(t, models, 1)
+	require.Contains(t, models, modelKey(server.RentAdType, "Tbilisi"))
+
+	testCases := []struct {
+		testCaseName  string
+		apartment     server.Apartment
+		expectedPrice float64
+		expectedLabel server.PriceLabel
+		isEstimated   bool
+	}{
+		{
+			testCaseName:  "below market",
+			apartment:     server.Apartment{AdType: server.RentAdType, City: "Tbilisi", District: "Vake", Coordinates: &server.Coordinates{Lat: 41.709, Lng: 44.757}, BuildingStatus: server.OldBuildingStatus, Area: 60, Rooms: 2, Floor: 5, Price: 350},
+			expectedPrice: 492,
+			expectedLabel: server.BelowMarketPrice,
+			isEstimated:   true,
+		},
+		{
+			testCaseName:  "at market",
+			apartment:     server.Apartment{AdType: server.RentAdType, City: "Tbilisi", District: "Gldani", Coordinates: &server.Coordinates{Lat: 41.799, Lng: 44.826}, BuildingStatus: server.NewBuildingStatus, Area: 60, Rooms: 2, Floor: 5, Price: 300},
+			expectedPrice: 300,
+			expectedLabel: server.AtMarketPrice,
+			isEstimated:   true,
+		},
+		{
+			testCaseName:  "above market without coordinates and unknown district",
+			apartment:     server.Apartment{AdType: server.RentAdType, City: "Tbilisi", BuildingStatus: server.OldBuildingStatus, Area: 60, Rooms: 2, Floor: 5, Price: 700},
+			expectedPrice: 364,
+			expectedLabel: server.AboveMarketPrice,
+			isEstimated:   true,
+		},
+		{
+			testCaseName: "no model for the city",
+			apartment:    server.Apartment{AdType: server.RentAdType, City: "Batumi", Area: 60, Price: 500},
+		},
+	}
+
+	for _, tc := range testCases {
+		estimate, ok := models.Estimate(tc.apartment)
+		require.Equal(t, tc.isEstimated, ok, tc.testCaseName)
+		if !ok {
+			continue
+		}
+
+		require.InEpsilon(t, tc.expectedPrice, estimate.Price, 0.1, tc.testCaseName)
+		require.Equal(t, tc.expectedLabel, estimate.Label, tc.testCaseName)
+	}
+}
+
+func TestEstimateZeroPrice(t *testing.T) {
+	models := Models{
+		modelKey(server.RentAdType, "Tbilisi"): &model{
+			scalers:   []scaler{{std: 1}, {std: 1}, {std: 1}, {std: 1}, {std: 1}},
+			districts: make(map[string]int),
+			coef:      []float64{-10},
+		},
+	}
+
+	estimate, ok := models.Estimate(server.Apartment{AdType: server.RentAdType, City: "Tbilisi", Area: 60, Price: 500})
+	require.False(t, ok)
+	require.Nil(t, estimate)
+}
+
+func TestRidge(t *testing.T) {
+	// y = 1 + 2x
+	x := [][]float64{{1, 0}, {1, 1}, {1, 2}, {1, 3}}
+	y := []float64{1, 3, 5, 7}
+
+	coef, ok := ridge(x, y, 0)
+	require.True(t, ok)
+	require.InDelta(t, 1, coef[0], 1e-9)
+	require.InDelta(t, 2, coef[1], 1e-9)
+
+	_, ok = ridge([][]float64{{1, 1}, {1, 1}}, []float64{1, 2}, 0)
+	require.False(t, ok)
+}
+
+func fixture(t *testing.T) []server.Apartment {
+	data, err := os.ReadFile("testdata/apartments.json")
+	require.NoError(t, err)
+
+	var apartments []server.Apartment
+	require.NoError(t, json.Unmarshal(data, &apartments))
+
+	return apartments
+}
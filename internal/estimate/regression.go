@@ -0,0 +1,72 @@
+package estimate
+
+import (
+	"math"
+)
+
+// ridge fits coefficients minimizing |Xb - y|² + lambda*|b|² without penalizing the intercept in the first column.
+func ridge(x [][]float64, y []float64, lambda float64) ([]float64, bool) {
+	if len(x) == 0 {
+		return nil, false
+	}
+
+	p := len(x[0])
+
+	// augmented matrix of the normal equations (XᵀX + λI | Xᵀy)
+	m := make([][]float64, p)
+	for i := range m {
+		m[i] = make([]float64, p+1)
+	}
+
+	for r, row := range x {
+		for i := 0; i < p; i++ {
+			for j := 0; j < p; j++ {
+				m[i][j] += row[i] * row[j]
+			}
+			m[i][p] += row[i] * y[r]
+		}
+	}
+
+	for i := 1; i < p; i++ {
+		m[i][i] += lambda
+	}
+
+	return solve(m)
+}
+
+// solve solves the augmented linear system with gaussian elimination and partial pivoting.
+func solve(m [][]float64) ([]float64, bool) {
+	n := len(m)
+
+	for col := 0; col < n; col++ {
+		pivot := col
+		for r := col + 1; r < n; r++ {
+			if math.Abs(m[r][col]) > math.Abs(m[pivot][col]) {
+				pivot = r
+			}
+		}
+
+		if math.Abs(m[pivot][col]) < 1e-12 {
+			return nil, false
+		}
+		m[col], m[pivot] = m[pivot], m[col]
+
+		for r := col + 1; r < n; r++ {
+			factor := m[r][col] / m[col][col]
+			for c := col; c <= n; c++ {
+				m[r][c] -= factor * m[col][c]
+			}
+		}
+	}
+
+	result := make([]float64, n)
+	for r := n - 1; r >= 0; r-- {
+		sum := m[r][n]
+		for c := r + 1; c < n; c++ {
+			sum -= m[r][c] * result[c]
+		}
+		result[r] = sum / m[r][r]
+	}
+
+	return result, true
+}
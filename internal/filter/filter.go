@@ -5,12 +5,16 @@ import (
 	"sync"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/irbgeo/apartment-bot/internal/server"
+	"github.com/irbgeo/apartment-bot/internal/tracing"
 )
 
 type filter struct {
 	storage storage
+	scorer  scorer
+	rates   rates
 
 	filter sync.Map
 }
@@ -21,9 +25,19 @@ type storage interface {
 	DeleteFilter(ctx context.Context, f server.Filter) error
 }
 
-func New(filterStorage storage) (*filter, error) {
+type scorer interface {
+	Score(f *server.Filter, a *server.Apartment) server.Score
+}
+
+type rates interface {
+	Rates() server.Rates
+}
+
+func New(filterStorage storage, scorer scorer, rates rates) (*filter, error) {
 	f := &filter{
 		storage: filterStorage,
+		scorer:  scorer,
+		rates:   rates,
 	}
 
 	filterList, err := f.storage.Filters(context.Background(), server.Filter{})
@@ -57,18 +71,61 @@ func (s *filter) Add(ctx context.Context, f server.Filter) (*server.Filter, erro
 }
 
 func (s *filter) Check(ctx context.Context, a *server.Apartment) {
+	ctx, span := tracing.Start(ctx, "filter.Check", attribute.Int64("apartment.id", a.ID))
+	defer span.End()
+
 	a.Filter = make(map[int64][]string)
+	a.Score = make(map[int64]server.Score)
+
+	// the prices of the apartments are in USD
+	rates := s.rates.Rates()
 
 	s.filter.Range(
 		func(_, value any) bool {
 			f := value.(server.Filter) // nolint: errcheck
 
-			if f.IsFit(a) {
-				a.Filter[f.User.ID] = append(a.Filter[f.User.ID], *f.Name)
+			if a.OriginalPrice <= 0 || f.Currency.OrUSD() != a.Currency.OrUSD() {
+				var ok bool
+				// the prices in the currency without the rate can't be compared
+				if f, ok = f.InCurrency(rates, server.USD); !ok {
+					return true
+				}
+			}
+
+			if f.IsFit(a) && s.rate(&f, a) {
+				for _, userID := range f.Recipients() {
+					a.Filter[userID] = append(a.Filter[userID], *f.Name)
+				}
 			}
 			return true
 		},
 	)
+
+	span.SetAttributes(attribute.Int("filter.matched_users", len(a.Filter)))
+	a.TraceContext = tracing.Inject(ctx)
+}
+
+// Rate scores the apartment for the filter and returns false if the score is below the filter minimum.
+func (s *filter) Rate(ctx context.Context, f server.Filter, a *server.Apartment) bool {
+	if a.Score == nil {
+		a.Score = make(map[int64]server.Score)
+	}
+	return s.rate(&f, a)
+}
+
+// rate keeps the best score of the apartment for the filter recipients.
+func (s *filter) rate(f *server.Filter, a *server.Apartment) bool {
+	sc := s.scorer.Score(f, a)
+	if f.MinScore != nil && sc.Value < *f.MinScore {
+		return false
+	}
+
+	for _, userID := range f.Recipients() {
+		if prev, ok := a.Score[userID]; !ok || prev.Value < sc.Value {
+			a.Score[userID] = sc
+		}
+	}
+	return true
 }
 
 func (s *filter) Get(ctx context.Context, f server.Filter) (*server.Filter, error) {
@@ -100,6 +157,25 @@ func (s *filter) GetForUser(ctx context.Context, id int64) ([]server.Filter, err
 	return filterList, nil
 }
 
+// GetShared returns the filters the user is subscribed to.
+func (s *filter) GetShared(ctx context.Context, id int64) ([]server.Filter, error) {
+	filterList, err := s.storage.Filters(context.Background(), server.Filter{SubscriberID: &id})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, filter := range filterList {
+		s.filter.Store(filter.ID, filter)
+	}
+
+	return filterList, nil
+}
+
+// List returns all filters.
+func (s *filter) List(ctx context.Context) ([]server.Filter, error) {
+	return s.storage.Filters(ctx, server.Filter{})
+}
+
 func (s *filter) Delete(ctx context.Context, f server.Filter) error {
 	filterList, err := s.storage.Filters(context.Background(), f)
 	if err != nil {
@@ -0,0 +1,7 @@
+package gazetteer
+
+import "errors"
+
+var (
+	ErrInvalidGazetteer = errors.New("invalid gazetteer")
+)
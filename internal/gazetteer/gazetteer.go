@@ -0,0 +1,252 @@
+package gazetteer
+
+import (
+	"cmp"
+	_ "embed"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+//go:embed gazetteer.yaml
+var bundledData []byte
+
+// Gazetteer resolves the titles of the providers to the canonical cities and districts.
+type Gazetteer struct {
+	// cities are the cities by the normalized alias
+	cities map[string]*city
+	list   []*city
+	// areas are the places with the polygons, the subdistricts first
+	areas []area
+}
+
+type area struct {
+	place   server.Place
+	polygon [][2]float64
+}
+
+type city struct {
+	name string
+	// places are the districts and the subdistricts by the normalized alias
+	places map[string]server.Place
+	// aliases are the normalized aliases of the places, the subdistricts and the longest first
+	aliases []string
+}
+
+// New reads the gazetteer from the YAML file, the bundled gazetteer is used when the path is empty.
+func New(path string) (*Gazetteer, error) {
+	if path == "" {
+		return Parse(bundledData)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse parses and validates the YAML gazetteer.
+func Parse(data []byte) (*Gazetteer, error) {
+	d := dataFile{}
+	if err := yaml.Unmarshal(data, &d); err != nil {
+		return nil, err
+	}
+
+	g := &Gazetteer{
+		cities: make(map[string]*city),
+	}
+	var districtAreas []area
+
+	for _, c := range d.Cities {
+		if c.Name == "" {
+			return nil, fmt.Errorf("%w: city %q without name", ErrInvalidGazetteer, c.ID)
+		}
+
+		ct := &city{
+			name:   c.Name,
+			places: make(map[string]server.Place),
+		}
+
+		for _, alias := range append([]string{c.Name}, c.Aliases...) {
+			key := normalize(alias)
+			if other, ok := g.cities[key]; ok && other != ct {
+				return nil, fmt.Errorf("%w: alias %q of %s and %s", ErrInvalidGazetteer, alias, other.name, c.Name)
+			}
+			g.cities[key] = ct
+		}
+
+		for _, district := range c.Districts {
+			p := server.Place{City: c.Name, District: district.Name}
+			if err := ct.add(district, p); err != nil {
+				return nil, err
+			}
+			if len(district.Polygon) != 0 {
+				districtAreas = append(districtAreas, area{place: p, polygon: district.Polygon})
+			}
+
+			for _, sub := range district.Subdistricts {
+				p := server.Place{City: c.Name, District: district.Name, Subdistrict: sub.Name}
+				if err := ct.add(sub, p); err != nil {
+					return nil, err
+				}
+				if len(sub.Polygon) != 0 {
+					g.areas = append(g.areas, area{place: p, polygon: sub.Polygon})
+				}
+			}
+		}
+
+		slices.SortFunc(ct.aliases, func(a, b string) int {
+			isSubA, isSubB := ct.places[a].Subdistrict != "", ct.places[b].Subdistrict != ""
+			if isSubA != isSubB && isSubA {
+				return -1
+			}
+			if isSubA != isSubB {
+				return 1
+			}
+			return cmp.Or(cmp.Compare(len(b), len(a)), cmp.Compare(a, b))
+		})
+
+		g.list = append(g.list, ct)
+	}
+
+	g.areas = append(g.areas, districtAreas...)
+	return g, nil
+}
+
+func (c *city) add(d districtData, p server.Place) error {
+	if d.Name == "" {
+		return fmt.Errorf("%w: district %q of %s without name", ErrInvalidGazetteer, d.ID, c.name)
+	}
+	if len(d.Polygon) != 0 && len(d.Polygon) < 3 {
+		return fmt.Errorf("%w: polygon of %s has less than 3 points", ErrInvalidGazetteer, d.Name)
+	}
+
+	for _, alias := range append([]string{d.Name}, d.Aliases...) {
+		key := normalize(alias)
+		other, ok := c.places[key]
+		if ok && other != p {
+			return fmt.Errorf("%w: alias %q of %s and %s", ErrInvalidGazetteer, alias, placeName(other), placeName(p))
+		}
+		if !ok {
+			c.places[key] = p
+			c.aliases = append(c.aliases, key)
+		}
+	}
+	return nil
+}
+
+// Resolve returns the canonical place of the titles, false is returned with the titles
+// of the unknown city or district. The district title may contain the alias: "Vake district".
+func (g *Gazetteer) Resolve(cityTitle, districtTitle string) (server.Place, bool) {
+	c, ok := g.cities[normalize(cityTitle)]
+	if !ok {
+		return server.Place{City: cityTitle, District: districtTitle}, false
+	}
+
+	key := normalize(districtTitle)
+	if key == "" {
+		return server.Place{City: c.name}, true
+	}
+
+	if p, ok := c.places[key]; ok {
+		return p, true
+	}
+
+	title := " " + key + " "
+	for _, alias := range c.aliases {
+		if strings.Contains(title, " "+alias+" ") {
+			return c.places[alias], true
+		}
+	}
+	return server.Place{City: c.name, District: districtTitle}, false
+}
+
+// Locate returns the place of the coordinates by the polygons of the districts and the subdistricts
+// of the city, the polygons of all cities are used when the city is empty. False is returned
+// when the city is unknown or the coordinates are out of its polygons.
+func (g *Gazetteer) Locate(cityTitle string, c server.Coordinates) (server.Place, bool) {
+	var cityName string
+	if cityTitle != "" {
+		ct, ok := g.cities[normalize(cityTitle)]
+		if !ok {
+			return server.Place{}, false
+		}
+		cityName = ct.name
+	}
+
+	for _, a := range g.areas {
+		if cityName != "" && a.place.City != cityName {
+			continue
+		}
+		if contains(a.polygon, c.Lng, c.Lat) {
+			return a.place, true
+		}
+	}
+	return server.Place{}, false
+}
+
+// contains checks the point is inside the polygon by the ray casting.
+func contains(polygon [][2]float64, x, y float64) bool {
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		xi, yi := polygon[i][0], polygon[i][1]
+		xj, yj := polygon[j][0], polygon[j][1]
+
+		if (yi > y) != (yj > y) && x < (xj-xi)*(y-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// Cities returns the canonical cities with the names of their districts and subdistricts.
+func (g *Gazetteer) Cities() []server.City {
+	result := make([]server.City, 0, len(g.list))
+	for _, c := range g.list {
+		districts := make(map[string]struct{})
+		for p := range maps.Values(c.places) {
+			if p.Subdistrict != "" {
+				districts[p.Subdistrict] = struct{}{}
+				continue
+			}
+			districts[p.District] = struct{}{}
+		}
+
+		result = append(result, server.City{
+			Name:     c.name,
+			District: districts,
+		})
+	}
+	return result
+}
+
+// normalize lowercases the name and drops the punctuation,
+// "T'bilisi" and "Vazha-Pshavela" are "tbilisi" and "vazha pshavela".
+func normalize(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r == '\'' || r == '’' || r == 'ʼ' || r == '`':
+			return -1
+		case unicode.IsLetter(r) || unicode.IsNumber(r):
+			return unicode.ToLower(r)
+		default:
+			return ' '
+		}
+	}, name)
+	return strings.Join(strings.Fields(name), " ")
+}
+
+func placeName(p server.Place) string {
+	if p.Subdistrict != "" {
+		return p.Subdistrict
+	}
+	return p.District
+}
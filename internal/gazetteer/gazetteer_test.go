@@ -0,0 +1,327 @@
+package gazetteer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+func TestResolve(t *testing.T) {
+	g, err := New("")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		testCaseName string
+		city         string
+		district     string
+		expected     server.Place
+		expectedOk   bool
+	}{
+		{
+			testCaseName: "canonical names",
+			city:         "Tbilisi",
+			district:     "Vake",
+			expected:     server.Place{City: "Tbilisi", District: "Vake"},
+			expectedOk:   true,
+		},
+		{
+			testCaseName: "georgian alias",
+			city:         "თბილისი",
+			district:     "ვაკე",
+			expected:     server.Place{City: "Tbilisi", District: "Vake"},
+			expectedOk:   true,
+		},
+		{
+			testCaseName: "russian alias",
+			city:         "Тбилиси",
+			district:     "Сабуртало",
+			expected:     server.Place{City: "Tbilisi", District: "Saburtalo"},
+			expectedOk:   true,
+		},
+		{
+			testCaseName: "subdistrict",
+			city:         "Tbilisi",
+			district:     "Vera",
+			expected:     server.Place{City: "Tbilisi", District: "Mtatsminda", Subdistrict: "Vera"},
+			expectedOk:   true,
+		},
+		{
+			testCaseName: "transliteration and punctuation",
+			city:         "T'bilisi",
+			district:     "Vazha Pshavela",
+			expected:     server.Place{City: "Tbilisi", District: "Saburtalo", Subdistrict: "Vazha-Pshavela"},
+			expectedOk:   true,
+		},
+		{
+			testCaseName: "alias in title",
+			city:         "Tbilisi",
+			district:     "Vake district",
+			expected:     server.Place{City: "Tbilisi", District: "Vake"},
+			expectedOk:   true,
+		},
+		{
+			testCaseName: "subdistrict before district in title",
+			city:         "Tbilisi",
+			district:     "Saburtalo, Nutsubidze Plateau",
+			expected:     server.Place{City: "Tbilisi", District: "Saburtalo", Subdistrict: "Nutsubidze Plateau"},
+			expectedOk:   true,
+		},
+		{
+			testCaseName: "alias isn't matched inside word",
+			city:         "Tbilisi",
+			district:     "Veranda",
+			expected:     server.Place{City: "Tbilisi", District: "Veranda"},
+			expectedOk:   false,
+		},
+		{
+			testCaseName: "empty district",
+			city:         "batumi",
+			district:     "",
+			expected:     server.Place{City: "Batumi"},
+			expectedOk:   true,
+		},
+		{
+			testCaseName: "unknown city",
+			city:         "Zugdidi",
+			district:     "Center",
+			expected:     server.Place{City: "Zugdidi", District: "Center"},
+			expectedOk:   false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCaseName, func(t *testing.T) {
+			p, ok := g.Resolve(tc.city, tc.district)
+			require.Equal(t, tc.expectedOk, ok)
+			require.Equal(t, tc.expected, p)
+		})
+	}
+}
+
+func TestLocate(t *testing.T) {
+	// the test polygons are rectangles, the Batumi one overlaps Vake to check the city limit
+	g, err := Parse([]byte(`
+cities:
+  - name: Tbilisi
+    districts:
+      - name: Mtatsminda
+        polygon: [[44.765, 41.685], [44.8, 41.685], [44.8, 41.71], [44.765, 41.71]]
+        subdistricts:
+          - name: Vera
+            polygon: [[44.775, 41.70], [44.79, 41.70], [44.79, 41.71], [44.775, 41.71]]
+      - name: Vake
+        polygon: [[44.7, 41.69], [44.765, 41.69], [44.765, 41.715], [44.7, 41.715]]
+  - name: Batumi
+    aliases: [батуми]
+    districts:
+      - name: Old Batumi
+        polygon: [[41.62, 41.63], [41.66, 41.63], [41.66, 41.66], [41.62, 41.66]]
+      - name: Overlap
+        polygon: [[44.73, 41.70], [44.75, 41.70], [44.75, 41.71], [44.73, 41.71]]
+`))
+	require.NoError(t, err)
+
+	testCases := []struct {
+		testCaseName string
+		city         string
+		coordinates  server.Coordinates
+		expected     server.Place
+		expectedOk   bool
+	}{
+		{
+			testCaseName: "district",
+			city:         "Tbilisi",
+			coordinates:  server.Coordinates{Lat: 41.705, Lng: 44.74},
+			expected:     server.Place{City: "Tbilisi", District: "Vake"},
+			expectedOk:   true,
+		},
+		{
+			testCaseName: "subdistrict before district",
+			city:         "Tbilisi",
+			coordinates:  server.Coordinates{Lat: 41.705, Lng: 44.78},
+			expected:     server.Place{City: "Tbilisi", District: "Mtatsminda", Subdistrict: "Vera"},
+			expectedOk:   true,
+		},
+		{
+			testCaseName: "district out of subdistrict",
+			city:         "Tbilisi",
+			coordinates:  server.Coordinates{Lat: 41.69, Lng: 44.795},
+			expected:     server.Place{City: "Tbilisi", District: "Mtatsminda"},
+			expectedOk:   true,
+		},
+		{
+			testCaseName: "polygons of the city only",
+			city:         "Батуми",
+			coordinates:  server.Coordinates{Lat: 41.705, Lng: 44.74},
+			expected:     server.Place{City: "Batumi", District: "Overlap"},
+			expectedOk:   true,
+		},
+		{
+			testCaseName: "out of polygons of the city",
+			city:         "Batumi",
+			coordinates:  server.Coordinates{Lat: 41.705, Lng: 44.78},
+			expectedOk:   false,
+		},
+		{
+			testCaseName: "without city",
+			coordinates:  server.Coordinates{Lat: 41.65, Lng: 41.64},
+			expected:     server.Place{City: "Batumi", District: "Old Batumi"},
+			expectedOk:   true,
+		},
+		{
+			testCaseName: "unknown city",
+			city:         "Zugdidi",
+			coordinates:  server.Coordinates{Lat: 41.705, Lng: 44.74},
+			expectedOk:   false,
+		},
+		{
+			testCaseName: "out of polygons",
+			coordinates:  server.Coordinates{Lat: 42.27, Lng: 42.70},
+			expectedOk:   false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCaseName, func(t *testing.T) {
+			p, ok := g.Locate(tc.city, tc.coordinates)
+			require.Equal(t, tc.expectedOk, ok)
+			require.Equal(t, tc.expected, p)
+		})
+	}
+}
+
+func TestLocateBundled(t *testing.T) {
+	g, err := New("")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		testCaseName string
+		city         string
+		coordinates  server.Coordinates
+		expected     server.Place
+	}{
+		{
+			testCaseName: "Vake park",
+			city:         "Tbilisi",
+			coordinates:  server.Coordinates{Lat: 41.711, Lng: 44.752},
+			expected:     server.Place{City: "Tbilisi", District: "Vake"},
+		},
+		{
+			testCaseName: "Gldani",
+			city:         "Tbilisi",
+			coordinates:  server.Coordinates{Lat: 41.797, Lng: 44.818},
+			expected:     server.Place{City: "Tbilisi", District: "Gldani"},
+		},
+		{
+			testCaseName: "Varketili",
+			city:         "Tbilisi",
+			coordinates:  server.Coordinates{Lat: 41.699, Lng: 44.885},
+			expected:     server.Place{City: "Tbilisi", District: "Samgori"},
+		},
+		{
+			testCaseName: "Old Batumi",
+			city:         "Batumi",
+			coordinates:  server.Coordinates{Lat: 41.650, Lng: 41.640},
+			expected:     server.Place{City: "Batumi", District: "Old Batumi"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCaseName, func(t *testing.T) {
+			p, ok := g.Locate(tc.city, tc.coordinates)
+			require.True(t, ok)
+			require.Equal(t, tc.expected, p)
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		testCaseName string
+		data         string
+		expectedErr  error
+	}{
+		{
+			testCaseName: "valid",
+			data: `
+cities:
+  - name: Tbilisi
+    districts:
+      - name: Vake
+        aliases: [ваке]
+        subdistricts:
+          - name: Bagebi
+`,
+		},
+		{
+			testCaseName: "city without name",
+			data: `
+cities:
+  - id: tbilisi
+`,
+			expectedErr: ErrInvalidGazetteer,
+		},
+		{
+			testCaseName: "alias of two cities",
+			data: `
+cities:
+  - name: Tbilisi
+    aliases: [tiflis]
+  - name: Tiflis
+`,
+			expectedErr: ErrInvalidGazetteer,
+		},
+		{
+			testCaseName: "alias of two districts",
+			data: `
+cities:
+  - name: Tbilisi
+    districts:
+      - name: Vake
+        aliases: [center]
+      - name: Sololaki
+        aliases: [center]
+`,
+			expectedErr: ErrInvalidGazetteer,
+		},
+		{
+			testCaseName: "short polygon",
+			data: `
+cities:
+  - name: Tbilisi
+    districts:
+      - name: Vake
+        polygon: [[44.7, 41.7], [44.8, 41.7]]
+`,
+			expectedErr: ErrInvalidGazetteer,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCaseName, func(t *testing.T) {
+			_, err := Parse([]byte(tc.data))
+			require.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestCities(t *testing.T) {
+	g, err := Parse([]byte(`
+cities:
+  - name: Tbilisi
+    districts:
+      - name: Mtatsminda
+        aliases: [мтацминда]
+        subdistricts:
+          - name: Vera
+  - name: Kutaisi
+`))
+	require.NoError(t, err)
+
+	require.Equal(t, []server.City{
+		{Name: "Tbilisi", District: map[string]struct{}{"Mtatsminda": {}, "Vera": {}}},
+		{Name: "Kutaisi", District: map[string]struct{}{}},
+	}, g.Cities())
+}
@@ -0,0 +1,21 @@
+package gazetteer
+
+type dataFile struct {
+	Cities []cityData `yaml:"cities"`
+}
+
+type cityData struct {
+	ID        string         `yaml:"id"`
+	Name      string         `yaml:"name"`
+	Aliases   []string       `yaml:"aliases"`
+	Districts []districtData `yaml:"districts"`
+}
+
+// districtData is the district or the subdistrict, Polygon is the optional boundary of [lng, lat] points.
+type districtData struct {
+	ID           string         `yaml:"id"`
+	Name         string         `yaml:"name"`
+	Aliases      []string       `yaml:"aliases"`
+	Polygon      [][2]float64   `yaml:"polygon"`
+	Subdistricts []districtData `yaml:"subdistricts"`
+}
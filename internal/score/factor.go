@@ -0,0 +1,153 @@
+package score
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+const (
+	goodMark = "👍"
+	badMark  = "👎"
+)
+
+var (
+	minMarketSize = 5
+
+	priceWeight       = 0.3
+	distanceWeight    = 0.2
+	areaPerRoomWeight = 0.15
+	ownerWeight       = 0.1
+	freshnessWeight   = 0.15
+	photoWeight       = 0.1
+
+	defaultMaxDistance = 5000.0
+	minAreaPerRoom     = 15.0
+	maxAreaPerRoom     = 35.0
+	freshnessPeriod    = 72 * time.Hour
+	enoughPhotos       = 10
+)
+
+// factor is a single aspect of the score, value is between 0 and 1
+type factor struct {
+	weight float64
+	value  float64
+	reason string
+}
+
+func score(f *server.Filter, a *server.Apartment, median float64, now time.Time) server.Score {
+	factors := make([]factor, 0, 6)
+
+	if median > 0 && a.Area > 0 && a.Price > 0 {
+		factors = append(factors, priceFactor(a.Price/a.Area, median))
+	}
+
+	if f.Coordinates != nil && a.Coordinates != nil {
+		maxDistance := defaultMaxDistance
+		if f.MaxDistance != nil && *f.MaxDistance > 0 {
+			maxDistance = *f.MaxDistance
+		}
+		factors = append(factors, distanceFactor(a.Coordinates.Distance(*f.Coordinates), maxDistance))
+	}
+
+	if a.Area > 0 && a.Rooms > 0 {
+		factors = append(factors, areaPerRoomFactor(a.Area/a.Rooms))
+	}
+
+	factors = append(factors,
+		ownerFactor(a.IsOwner),
+		photoFactor(len(a.PhotoURLs)),
+	)
+
+	if !a.OrderDate.IsZero() {
+		factors = append(factors, freshnessFactor(now.Sub(a.OrderDate)))
+	}
+
+	var value, weight float64
+	reasons := make([]string, 0, len(factors))
+	for _, f := range factors {
+		value += f.weight * f.value
+		weight += f.weight
+
+		mark := goodMark
+		if f.value < 0.5 {
+			mark = badMark
+		}
+		reasons = append(reasons, mark+" "+f.reason)
+	}
+
+	return server.Score{
+		Value:   math.Round(100 * value / weight),
+		Reasons: reasons,
+	}
+}
+
+func priceFactor(pricePerMeter, median float64) factor {
+	ratio := pricePerMeter / median
+
+	reason := fmt.Sprintf("%.0f%% below district median price per m²", (1-ratio)*100)
+	if ratio > 1 {
+		reason = fmt.Sprintf("%.0f%% above district median price per m²", (ratio-1)*100)
+	}
+
+	return factor{
+		weight: priceWeight,
+		value:  clamp(1.5 - ratio),
+		reason: reason,
+	}
+}
+
+func distanceFactor(distance, maxDistance float64) factor {
+	return factor{
+		weight: distanceWeight,
+		value:  clamp(1 - distance/maxDistance),
+		reason: fmt.Sprintf("%.1f km to your location", distance/1000),
+	}
+}
+
+func areaPerRoomFactor(areaPerRoom float64) factor {
+	return factor{
+		weight: areaPerRoomWeight,
+		value:  clamp((areaPerRoom - minAreaPerRoom) / (maxAreaPerRoom - minAreaPerRoom)),
+		reason: fmt.Sprintf("%.0f m² per room", areaPerRoom),
+	}
+}
+
+func ownerFactor(isOwner bool) factor {
+	if isOwner {
+		return factor{weight: ownerWeight, value: 1, reason: "owner"}
+	}
+	return factor{weight: ownerWeight, value: 0, reason: "agency"}
+}
+
+func freshnessFactor(age time.Duration) factor {
+	reason := "listed " + strconv.Itoa(int(age.Hours())) + " h ago"
+	if age >= 24*time.Hour {
+		reason = "listed " + strconv.Itoa(int(age.Hours()/24)) + " d ago"
+	}
+
+	return factor{
+		weight: freshnessWeight,
+		value:  clamp(1 - float64(age)/float64(freshnessPeriod)),
+		reason: reason,
+	}
+}
+
+func photoFactor(count int) factor {
+	return factor{
+		weight: photoWeight,
+		value:  float64(min(count, enoughPhotos)) / float64(enoughPhotos),
+		reason: strconv.Itoa(count) + " photos",
+	}
+}
+
+func marketKey(adType int64, city, district string) string {
+	return strconv.FormatInt(adType, 10) + ":" + city + ":" + district
+}
+
+func clamp(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
@@ -0,0 +1,97 @@
+package score
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+func TestScore(t *testing.T) {
+	now := time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC)
+	maxDistance := 2000.0
+
+	testCases := []struct {
+		testCaseName    string
+		filter          *server.Filter
+		apartment       *server.Apartment
+		median          float64
+		expectedValue   float64
+		expectedReasons []string
+	}{
+		{
+			testCaseName: "best match",
+			filter: &server.Filter{
+				Coordinates: &server.Coordinates{Lat: 41.7, Lng: 44.8},
+				MaxDistance: &maxDistance,
+			},
+			apartment: &server.Apartment{
+				Price:       500,
+				Area:        70,
+				Rooms:       2,
+				IsOwner:     true,
+				Coordinates: &server.Coordinates{Lat: 41.7, Lng: 44.8},
+				PhotoURLs:   make([]string, 10),
+				OrderDate:   now,
+			},
+			median:        500.0 / 70 * 2,
+			expectedValue: 100,
+			expectedReasons: []string{
+				"👍 50% below district median price per m²",
+				"👍 0.0 km to your location",
+				"👍 35 m² per room",
+				"👍 owner",
+				"👍 10 photos",
+				"👍 listed 0 h ago",
+			},
+		},
+		{
+			testCaseName: "worst match without market data",
+			filter:       &server.Filter{},
+			apartment: &server.Apartment{
+				Price:     500,
+				Area:      30,
+				Rooms:     2,
+				OrderDate: now.Add(-96 * time.Hour),
+			},
+			expectedValue: 0,
+			expectedReasons: []string{
+				"👎 15 m² per room",
+				"👎 agency",
+				"👎 0 photos",
+				"👎 listed 4 d ago",
+			},
+		},
+		{
+			testCaseName: "partial match",
+			filter:       &server.Filter{},
+			apartment: &server.Apartment{
+				Price:     1000,
+				Area:      100,
+				Rooms:     4,
+				PhotoURLs: make([]string, 5),
+			},
+			median:        10,
+			expectedValue: 42,
+			expectedReasons: []string{
+				"👍 0% below district median price per m²",
+				"👍 25 m² per room",
+				"👎 agency",
+				"👍 5 photos",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		result := score(tc.filter, tc.apartment, tc.median, now)
+		require.Equal(t, tc.expectedValue, result.Value, tc.testCaseName)
+		require.Equal(t, tc.expectedReasons, result.Reasons, tc.testCaseName)
+	}
+}
+
+func TestMedian(t *testing.T) {
+	require.Equal(t, 2.0, median([]float64{3, 1, 2}))
+	require.Equal(t, 2.5, median([]float64{4, 1, 3, 2}))
+}
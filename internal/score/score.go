@@ -0,0 +1,129 @@
+package score
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+type scorer struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	storage storage
+
+	mu      sync.RWMutex
+	medians map[string]float64
+}
+
+type storage interface {
+	Apartments(ctx context.Context, f server.Filter) (<-chan server.Apartment, error)
+}
+
+// New creates a scorer rating apartments against the market of stored listings
+func New(s storage) *scorer {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &scorer{
+		ctx:     ctx,
+		cancel:  cancel,
+		storage: s,
+		medians: make(map[string]float64),
+	}
+}
+
+func (s *scorer) Start(updateInterval time.Duration) error {
+	if err := s.updateMedians(); err != nil {
+		return err
+	}
+
+	go func() {
+		ticker := time.NewTicker(updateInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.updateMedians(); err != nil {
+					slog.Error("update medians", "err", err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (s *scorer) Stop() {
+	s.cancel()
+}
+
+// Score rates how well the apartment fits the filter.
+func (s *scorer) Score(f *server.Filter, a *server.Apartment) server.Score {
+	return score(f, a, s.median(a), time.Now())
+}
+
+// median returns the median price per m² of the apartment district,
+// falling back to the whole city.
+func (s *scorer) median(a *server.Apartment) float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if m, ok := s.medians[marketKey(a.AdType, a.City, a.District)]; ok {
+		return m
+	}
+	return s.medians[marketKey(a.AdType, a.City, "")]
+}
+
+func (s *scorer) updateMedians() error {
+	apartmentCh, err := s.storage.Apartments(s.ctx, server.Filter{})
+	if err != nil {
+		return err
+	}
+
+	prices := make(map[string][]float64)
+	for a := range apartmentCh {
+		if a.Area <= 0 || a.Price <= 0 {
+			continue
+		}
+
+		ppm := a.Price / a.Area
+		districtKey := marketKey(a.AdType, a.City, a.District)
+		cityKey := marketKey(a.AdType, a.City, "")
+
+		prices[districtKey] = append(prices[districtKey], ppm)
+		if districtKey != cityKey {
+			prices[cityKey] = append(prices[cityKey], ppm)
+		}
+	}
+
+	medians := make(map[string]float64, len(prices))
+	for key, p := range prices {
+		if len(p) < minMarketSize {
+			continue
+		}
+		medians[key] = median(p)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.medians = medians
+	return nil
+}
+
+func median(values []float64) float64 {
+	sort.Float64s(values)
+
+	mid := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[mid-1] + values[mid]) / 2
+	}
+	return values[mid]
+}
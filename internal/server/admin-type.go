@@ -0,0 +1,17 @@
+package server
+
+// AdminStats is the overview of the bot for the admin.
+type AdminStats struct {
+	Users   int64
+	Filters int64
+	// ActiveSubscribers is the number of users receiving apartments of active filters
+	ActiveSubscribers int64
+	// Apartments is the number of stored listings by provider
+	Apartments map[string]int64
+}
+
+// AdminUser is the user with the filters for the admin.
+type AdminUser struct {
+	User    User
+	Filters []Filter
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+)
+
+// AdminStats counts the users, filters and stored listings.
+func (s *service) AdminStats(ctx context.Context) (*AdminStats, error) {
+	users, err := s.storage.Users(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filters, err := s.filter.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	subscribers := make(map[int64]struct{})
+	for _, f := range filters {
+		if f.PauseTimestamp != nil {
+			continue
+		}
+		for _, userID := range f.Recipients() {
+			subscribers[userID] = struct{}{}
+		}
+	}
+
+	hosts, err := s.storage.ApartmentCountByHost(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	apartments := make(map[string]int64, len(hosts))
+	for host, count := range hosts {
+		apartments[providerName(host)] += count
+	}
+
+	return &AdminStats{
+		Users:             int64(len(users)),
+		Filters:           int64(len(filters)),
+		ActiveSubscribers: int64(len(subscribers)),
+		Apartments:        apartments,
+	}, nil
+}
+
+// AdminUser returns the user with the own and shared filters.
+func (s *service) AdminUser(ctx context.Context, u User) (*AdminUser, error) {
+	user, err := s.storage.User(ctx, Filter{User: &User{ID: u.ID}})
+	if err != nil {
+		// the user blocked the bot or never started it
+		user = User{ID: u.ID, IsBlocked: true}
+	}
+
+	filters, err := s.Filters(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AdminUser{
+		User:    user,
+		Filters: filters,
+	}, nil
+}
+
+// AdminSetSuperuser grants or revokes the superuser of the user.
+func (s *service) AdminSetSuperuser(ctx context.Context, u User) (*User, error) {
+	user, _ := s.storage.User(ctx, Filter{User: &User{ID: u.ID}})
+	user.ID = u.ID
+	user.IsSuperuser = u.IsSuperuser
+
+	if err := s.storage.InsertUser(ctx, user); err != nil {
+		return nil, err
+	}
+
+	s.setUser(user)
+	return &user, nil
+}
+
+// AdminBan bans the user and deletes the filters of the user, or lifts the ban.
+func (s *service) AdminBan(ctx context.Context, u User) (*User, error) {
+	user, _ := s.storage.User(ctx, Filter{User: &User{ID: u.ID}})
+	user.ID = u.ID
+	user.IsBanned = u.IsBanned
+
+	if user.IsBanned {
+		if err := s.deleteUserFilters(ctx, u.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.storage.InsertUser(ctx, user); err != nil {
+		return nil, err
+	}
+
+	s.setUser(user)
+	return &user, nil
+}
+
+// AdminRefresh reloads the apartments in the background.
+func (s *service) AdminRefresh(_ context.Context) error {
+	if !s.isRefreshing.CompareAndSwap(false, true) {
+		return errRefreshIsInProgress
+	}
+
+	go func() {
+		defer s.isRefreshing.Store(false)
+
+		if err := s.RefreshApartments(); err != nil {
+			slog.Error("refresh apartments", "err", err)
+			return
+		}
+		slog.Info("apartments are refreshed")
+	}()
+	return nil
+}
+
+// providerName returns the listing site by the host of the apartment url.
+func providerName(host string) string {
+	if host == "" {
+		return "unknown"
+	}
+	return strings.TrimPrefix(host, "www.")
+}
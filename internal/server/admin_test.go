@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminBan(t *testing.T) {
+	ctx := context.Background()
+	f := &fakeFilter{
+		filters: map[string]Filter{
+			"own":    {ID: "own", User: &User{ID: 1}},
+			"shared": {ID: "shared", User: &User{ID: 2}, Subscribers: []int64{1}},
+		},
+	}
+	st := &fakeStorage{users: map[int64]User{1: {ID: 1}}}
+	s := &service{filter: f, storage: st, plans: DefaultPlans}
+
+	user, err := s.AdminBan(ctx, User{ID: 1, IsBanned: true})
+	require.NoError(t, err)
+	require.True(t, user.IsBanned)
+
+	require.NotContains(t, f.filters, "own")
+	require.Empty(t, f.filters["shared"].Subscribers)
+
+	require.Equal(t, errUserBanned, s.ConnectUser(ctx, User{ID: 1}))
+	require.Equal(t, errUserBanned, s.useHistoryReplay(ctx, 1))
+
+	_, err = s.AdminBan(ctx, User{ID: 1})
+	require.NoError(t, err)
+	require.NoError(t, s.ConnectUser(ctx, User{ID: 1}))
+	require.NotNil(t, st.users[1].LastActivityAt)
+}
+
+func TestProviderName(t *testing.T) {
+	testCases := []struct {
+		testCaseName string
+		host         string
+		expected     string
+	}{
+		{
+			testCaseName: "host",
+			host:         "home.ss.ge",
+			expected:     "home.ss.ge",
+		},
+		{
+			testCaseName: "www",
+			host:         "www.myhome.ge",
+			expected:     "myhome.ge",
+		},
+		{
+			testCaseName: "without host",
+			expected:     "unknown",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCaseName, func(t *testing.T) {
+			require.Equal(t, tc.expected, providerName(tc.host))
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"slices"
+	"time"
+)
+
+// PipelineStatus is the stage of the apartment in the user's flat hunt
+type PipelineStatus int64
+
+const (
+	SeenStatus PipelineStatus = iota + 1
+	ContactedStatus
+	ViewingBookedStatus
+	RejectedStatus
+	SignedStatus
+)
+
+// ActivePipelineStatuses are the stages the user is still working on.
+var ActivePipelineStatuses = []PipelineStatus{SeenStatus, ContactedStatus, ViewingBookedStatus}
+
+func (p PipelineStatus) IsActive() bool {
+	return slices.Contains(ActivePipelineStatuses, p)
+}
+
+// ApartmentMark keeps what the user did with a delivered apartment.
+type ApartmentMark struct {
+	UserID      int64
+	ApartmentID int64
+	IsSaved     bool
+	IsHidden    bool
+	Note        string
+	Status      PipelineStatus
+	ViewingAt   *time.Time
+	IsReminded  bool
+	UpdatedAt   time.Time
+
+	// Apartment is the snapshot of the apartment, it is kept after the listing is removed
+	Apartment *Apartment
+	// IsAvailable is set for saved apartments on request
+	IsAvailable bool
+}
+
+// ApartmentMarkChange changes only the set fields of the mark.
+type ApartmentMarkChange struct {
+	UserID      int64
+	ApartmentID int64
+	IsSaved     *bool
+	IsHidden    *bool
+	Note        *string
+	Status      *PipelineStatus
+	// ViewingAt books the viewing, zero time cancels it
+	ViewingAt *time.Time
+}
+
+type ApartmentMarkFilter struct {
+	UserID      *int64
+	ApartmentID *int64
+	IsSaved     *bool
+	IsHidden    *bool
+	Statuses    []PipelineStatus
+	ViewingFrom *time.Time
+	ViewingTill *time.Time
+	IsReminded  *bool
+}
@@ -0,0 +1,174 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// MarkApartment saves, hides or annotates the apartment for the user.
+func (s *service) MarkApartment(ctx context.Context, c ApartmentMarkChange) (*ApartmentMark, error) {
+	mark := ApartmentMark{
+		UserID:      c.UserID,
+		ApartmentID: c.ApartmentID,
+	}
+
+	marks, err := s.storage.ApartmentMarks(ctx, ApartmentMarkFilter{UserID: &c.UserID, ApartmentID: &c.ApartmentID})
+	if err != nil {
+		return nil, err
+	}
+	if len(marks) != 0 {
+		mark = marks[0]
+	}
+
+	if c.IsSaved != nil {
+		mark.IsSaved = *c.IsSaved
+	}
+	if c.IsHidden != nil {
+		mark.IsHidden = *c.IsHidden
+	}
+	if c.Note != nil {
+		mark.Note = *c.Note
+	}
+	if c.Status != nil {
+		mark.Status = *c.Status
+	}
+	if c.ViewingAt != nil {
+		mark.ViewingAt, mark.IsReminded = nil, false
+		if !c.ViewingAt.IsZero() {
+			mark.ViewingAt = c.ViewingAt
+			mark.Status = ViewingBookedStatus
+		}
+	}
+	mark.UpdatedAt = time.Now()
+
+	if a, ok := s.storedApartment(ctx, c.ApartmentID); ok {
+		mark.Apartment = &a
+	}
+	if mark.Apartment == nil {
+		return nil, errApartmentNotFound
+	}
+
+	if err := s.storage.SaveApartmentMark(ctx, mark); err != nil {
+		return nil, err
+	}
+
+	s.setHidden(mark.UserID, mark.ApartmentID, mark.IsHidden)
+	return &mark, nil
+}
+
+// SavedApartments returns the saved apartments of the user with their current availability.
+func (s *service) SavedApartments(ctx context.Context, u User) ([]ApartmentMark, error) {
+	isSaved := true
+	marks, err := s.storage.ApartmentMarks(ctx, ApartmentMarkFilter{UserID: &u.ID, IsSaved: &isSaved})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, m := range marks {
+		if m.Apartment == nil {
+			continue
+		}
+
+		reason, err := s.apartment.Check(ctx, *m.Apartment)
+		if err != nil {
+			slog.Error("check_saved_apartment", "apartment_id", m.ApartmentID, "err", err)
+		}
+		marks[i].IsAvailable = reason == NotRemoved
+	}
+
+	return marks, nil
+}
+
+// Viewings returns the upcoming viewings of the user.
+func (s *service) Viewings(ctx context.Context, u User) ([]ApartmentMark, error) {
+	now := time.Now()
+	return s.storage.ApartmentMarks(ctx, ApartmentMarkFilter{
+		UserID:      &u.ID,
+		Statuses:    []PipelineStatus{ViewingBookedStatus},
+		ViewingFrom: &now,
+	})
+}
+
+func (s *service) storedApartment(ctx context.Context, id int64) (Apartment, bool) {
+	a, err := s.storage.Apartment(ctx, id)
+	if errors.Is(err, ErrNotFound) {
+		// the apartment is removed, the mark keeps its last copy
+		return Apartment{}, false
+	}
+	if err != nil {
+		slog.Error("get_apartment", "apartment_id", id, "err", err)
+		return Apartment{}, false
+	}
+	return a, true
+}
+
+func (s *service) updateHidden() error {
+	isHidden := true
+	marks, err := s.storage.ApartmentMarks(s.ctx, ApartmentMarkFilter{IsHidden: &isHidden})
+	if err != nil {
+		return err
+	}
+
+	hidden := make(map[int64]map[int64]struct{})
+	for _, m := range marks {
+		if hidden[m.ApartmentID] == nil {
+			hidden[m.ApartmentID] = make(map[int64]struct{})
+		}
+		hidden[m.ApartmentID][m.UserID] = struct{}{}
+	}
+
+	s.hiddenMutex.Lock()
+	defer s.hiddenMutex.Unlock()
+
+	s.hidden = hidden
+	return nil
+}
+
+func (s *service) setHidden(userID, apartmentID int64, isHidden bool) {
+	s.hiddenMutex.Lock()
+	defer s.hiddenMutex.Unlock()
+
+	if !isHidden {
+		delete(s.hidden[apartmentID], userID)
+		return
+	}
+
+	if s.hidden[apartmentID] == nil {
+		s.hidden[apartmentID] = make(map[int64]struct{})
+	}
+	s.hidden[apartmentID][userID] = struct{}{}
+}
+
+func (s *service) isHidden(userID, apartmentID int64) bool {
+	s.hiddenMutex.RLock()
+	defer s.hiddenMutex.RUnlock()
+
+	_, ok := s.hidden[apartmentID][userID]
+	return ok
+}
+
+// hiddenApartments returns the apartments hidden by the user.
+func (s *service) hiddenApartments(userID int64) []int64 {
+	s.hiddenMutex.RLock()
+	defer s.hiddenMutex.RUnlock()
+
+	var apartmentIDs []int64
+	for apartmentID, users := range s.hidden {
+		if _, ok := users[userID]; ok {
+			apartmentIDs = append(apartmentIDs, apartmentID)
+		}
+	}
+	return apartmentIDs
+}
+
+// removeHidden drops the users who hid the apartment from its recipients.
+func (s *service) removeHidden(a *Apartment) {
+	for userID := range a.Filter {
+		if s.isHidden(userID, a.ID) {
+			delete(a.Filter, userID)
+			delete(a.Score, userID)
+		}
+	}
+}
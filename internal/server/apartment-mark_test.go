@@ -0,0 +1,49 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoveHidden(t *testing.T) {
+	s := &service{
+		hidden: make(map[int64]map[int64]struct{}),
+	}
+
+	s.setHidden(1, 100, true)
+	s.setHidden(2, 100, true)
+	s.setHidden(2, 100, false)
+
+	a := &Apartment{
+		ID: 100,
+		Filter: map[int64][]string{
+			1: {"first"},
+			2: {"second"},
+		},
+		Score: map[int64]Score{
+			1: {Value: 50},
+		},
+	}
+	s.removeHidden(a)
+
+	require.Equal(t, map[int64][]string{2: {"second"}}, a.Filter)
+	require.Empty(t, a.Score)
+	require.True(t, s.isHidden(1, 100))
+	require.False(t, s.isHidden(1, 200))
+}
+
+func TestStoredApartment(t *testing.T) {
+	s := &service{
+		storage: &fakeStorage{apartments: []Apartment{{ID: 1}}},
+	}
+
+	a, ok := s.storedApartment(context.Background(), 1)
+	require.True(t, ok)
+	require.Equal(t, int64(1), a.ID)
+
+	// the removed apartment isn't an error
+	_, ok = s.storedApartment(context.Background(), 2)
+	require.False(t, ok)
+}
@@ -32,5 +32,38 @@ type Apartment struct {
 	PhotoURLs      []string
 	IsOwner        bool
 
+	// OriginalPrice is the price of the listing in Currency, Price is converted to USD
+	OriginalPrice float64
+	Currency      Currency
+
+	// Subdistrict is the part of District, e.g. Vera in Mtatsminda, it's empty when unknown
+	Subdistrict string
+
+	Estimate *PriceEstimate
+
 	Filter map[int64][]string
+	// Score is the best fit of the apartment among matched filters by user id
+	Score map[int64]Score
+
+	// TraceContext carries the span context of the listing through the pipeline
+	TraceContext map[string]string
+}
+
+const (
+	BelowMarketPrice PriceLabel = iota + 1
+	AtMarketPrice
+	AboveMarketPrice
+)
+
+type PriceLabel int64
+
+// PriceEstimate is the fair price of the apartment predicted from similar listings.
+type PriceEstimate struct {
+	Price float64
+	Label PriceLabel
+}
+
+type Score struct {
+	Value   float64
+	Reasons []string
 }
@@ -0,0 +1,45 @@
+package server
+
+import "time"
+
+// RemovalReason is why the apartment is removed from the listings.
+type RemovalReason int64
+
+const (
+	// NotRemoved is the reason of the available apartment
+	NotRemoved RemovalReason = iota
+	// InactiveRemoval is the listing deactivated by the owner
+	InactiveRemoval
+	// ExpiredRemoval is the listing older than the TTL of the provider
+	ExpiredRemoval
+	// NotFoundRemoval is the listing deleted from the provider
+	NotFoundRemoval
+	// RefreshRemoval is the listing archived by RefreshApartments
+	RefreshRemoval
+)
+
+func (r RemovalReason) String() string {
+	switch r {
+	case InactiveRemoval:
+		return "inactive"
+	case ExpiredRemoval:
+		return "expired"
+	case NotFoundRemoval:
+		return "not_found"
+	case RefreshRemoval:
+		return "refresh"
+	}
+	return "not_removed"
+}
+
+// ArchivedApartment is the removed apartment, it's kept for the statistics and the disputes.
+type ArchivedApartment struct {
+	Apartment
+	RemovedAt time.Time
+	Reason    RemovalReason
+}
+
+type ArchiveFilter struct {
+	RemovedFrom *time.Time
+	Reasons     []RemovalReason
+}
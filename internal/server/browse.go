@@ -0,0 +1,206 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxBrowseAttempts limits the lookups of the next apartment when the apartments at the cursor are removed.
+const maxBrowseAttempts = 3
+
+// BrowseApartments returns the apartment of the filter at the cursor.
+// A new browsing or a browsing with the other sort starts from the first apartment,
+// only the new browsing counts against the history limit.
+func (s *service) BrowseApartments(ctx context.Context, b ApartmentBrowse) (*ApartmentBrowsePage, error) {
+	filter, err := s.Filter(ctx, b.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	userID := filter.User.ID
+	if b.Filter.User != nil {
+		userID = b.Filter.User.ID
+	}
+
+	usdFilter, err := s.withUSDPrices(*filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor *ApartmentCursor
+	switch b.Cursor {
+	case "":
+		if err := s.useHistoryReplay(ctx, userID); err != nil {
+			return nil, err
+		}
+	default:
+		sort, direction, id, err := parseCursor(b.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if sort == b.Sort {
+			cursor = &ApartmentCursor{ApartmentID: id, Direction: direction}
+		}
+	}
+
+	if b.Sort == ClosestSort && filter.Coordinates == nil {
+		return nil, errLocationNotSet
+	}
+
+	q := ApartmentSearch{
+		Filter: usdFilter,
+		Sort:   b.Sort,
+		Limit:  1,
+	}
+	q.Filter.ExcludedApartmentIDs = s.hiddenApartments(userID)
+
+	if cursor != nil {
+		cursor.Key, err = s.storage.ApartmentSortKey(ctx, q, cursor.ApartmentID)
+		switch {
+		case errors.Is(err, ErrNotFound):
+			// the apartment of the cursor is removed, the browsing starts from the first apartment
+		case err != nil:
+			return nil, err
+		default:
+			q.Cursor = cursor
+		}
+	}
+
+	page := &ApartmentBrowsePage{
+		Sort: b.Sort,
+	}
+	for attempt := 1; attempt <= maxBrowseAttempts; attempt++ {
+		page.Total, err = s.storage.ApartmentCount(ctx, q.Filter)
+		if err != nil {
+			return nil, err
+		}
+		if page.Total == 0 {
+			return page, nil
+		}
+
+		apartments, err := s.browseApartment(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		if len(apartments) == 0 {
+			return page, nil
+		}
+
+		a := apartments[0]
+		// the removed apartment is deleted, so the same search finds the next one
+		if attempt < maxBrowseAttempts && !s.checkApartment(ctx, a) {
+			continue
+		}
+
+		page.Position, err = s.apartmentPosition(ctx, q, a.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		s.estimator.Estimate(&a)
+		a.Score = nil
+		s.filter.Rate(ctx, *filter, &a)
+		a.Filter = map[int64][]string{
+			userID: {*filter.Name},
+		}
+
+		page.Apartment = &a
+		page.Cursor = formatCursor(b.Sort, AtCursor, a.ID)
+		if page.Position > 1 {
+			page.PrevCursor = formatCursor(b.Sort, BeforeCursor, a.ID)
+		}
+		if page.Position < page.Total {
+			page.NextCursor = formatCursor(b.Sort, AfterCursor, a.ID)
+		}
+		return page, nil
+	}
+	return page, nil
+}
+
+// browseApartment searches the apartment from the cursor,
+// the nearest apartment in the other direction is returned when the apartments past the cursor are removed.
+func (s *service) browseApartment(ctx context.Context, q ApartmentSearch) ([]Apartment, error) {
+	apartments, err := s.storage.SearchApartments(ctx, q)
+	if err != nil || len(apartments) != 0 || q.Cursor == nil {
+		return apartments, err
+	}
+
+	c := *q.Cursor
+	c.Direction = BeforeCursor
+	if q.Cursor.Direction == BeforeCursor {
+		c.Direction = AtCursor
+	}
+	q.Cursor = &c
+	return s.storage.SearchApartments(ctx, q)
+}
+
+// apartmentPosition returns the position of the apartment in the sorted apartments, it starts from 1.
+func (s *service) apartmentPosition(ctx context.Context, q ApartmentSearch, id int64) (int64, error) {
+	key, err := s.storage.ApartmentSortKey(ctx, q, id)
+	if err != nil {
+		return 0, err
+	}
+
+	q.Cursor = &ApartmentCursor{
+		Key:         key,
+		ApartmentID: id,
+		Direction:   BeforeCursor,
+	}
+	before, err := s.storage.SearchApartmentCount(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+	return before + 1, nil
+}
+
+// cursorDirections are the signs of the directions in the cursor.
+var cursorDirections = map[CursorDirection]byte{
+	AtCursor:     '=',
+	AfterCursor:  '>',
+	BeforeCursor: '<',
+}
+
+// formatCursor returns the cursor of the apartment in the sorted apartments,
+// the id is encoded in base64 to fit the cursor with the filter id into the Telegram callback data.
+func formatCursor(sort ApartmentSort, direction CursorDirection, id int64) string {
+	b := binary.BigEndian.AppendUint64(nil, uint64(id))
+	for len(b) > 1 && b[0] == 0 {
+		b = b[1:]
+	}
+	return fmt.Sprintf("%d%c%s", sort, cursorDirections[direction], base64.RawURLEncoding.EncodeToString(b))
+}
+
+func parseCursor(cursor string) (ApartmentSort, CursorDirection, int64, error) {
+	i := strings.IndexAny(cursor, "=<>")
+	if i < 1 {
+		return 0, 0, 0, errInvalidCursor
+	}
+
+	sort, err := strconv.ParseInt(cursor[:i], 10, 64)
+	if err != nil {
+		return 0, 0, 0, errInvalidCursor
+	}
+
+	var direction CursorDirection
+	for d, sign := range cursorDirections {
+		if sign == cursor[i] {
+			direction = d
+		}
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(cursor[i+1:])
+	if err != nil || len(b) == 0 || len(b) > 8 {
+		return 0, 0, 0, errInvalidCursor
+	}
+
+	var id uint64
+	for _, v := range b {
+		id = id<<8 | uint64(v)
+	}
+	return ApartmentSort(sort), direction, int64(id), nil
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBrowseApartments(t *testing.T) {
+	name := "flat"
+	s := &service{
+		storage: &fakeStorage{
+			apartments: []Apartment{{ID: 1}, {ID: 2}, {ID: 3}},
+			users:      map[int64]User{1: {ID: 1}},
+		},
+		filter: &fakeFilter{filters: map[string]Filter{
+			"f1": {ID: "f1", Name: &name, User: &User{ID: 1}},
+		}},
+		apartment: &fakeApartment{},
+		estimator: fakeEstimator{},
+		plans:     DefaultPlans,
+	}
+	ctx := context.Background()
+	b := ApartmentBrowse{Filter: Filter{ID: "f1", User: &User{ID: 1}}}
+
+	page, err := s.BrowseApartments(ctx, b)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), page.Apartment.ID)
+	require.Equal(t, int64(1), page.Position)
+	require.Equal(t, int64(3), page.Total)
+	require.Empty(t, page.PrevCursor)
+	require.Equal(t, []string{name}, page.Apartment.Filter[1])
+	require.Equal(t, int64(1), s.storage.(*fakeStorage).users[1].HistoryReplays)
+
+	b.Cursor = page.NextCursor
+	page, err = s.BrowseApartments(ctx, b)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), page.Apartment.ID)
+	require.NotEmpty(t, page.PrevCursor)
+
+	// the removed apartment before the cursor doesn't move it
+	storage := s.storage.(*fakeStorage)
+	storage.apartments = storage.apartments[1:]
+	b.Cursor = page.NextCursor
+	page, err = s.BrowseApartments(ctx, b)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), page.Apartment.ID)
+	require.Equal(t, int64(2), page.Position)
+	require.Empty(t, page.NextCursor)
+
+	b.Cursor = page.PrevCursor
+	page, err = s.BrowseApartments(ctx, b)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), page.Apartment.ID)
+
+	// the removed apartment of the cursor starts the browsing from the first apartment
+	b.Cursor = formatCursor(NewestSort, AfterCursor, 1)
+	page, err = s.BrowseApartments(ctx, b)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), page.Apartment.ID)
+	require.Equal(t, int64(1), page.Position)
+
+	// the other sort starts from the first apartment
+	b.Sort = CheapestSort
+	page, err = s.BrowseApartments(ctx, b)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), page.Apartment.ID)
+	require.Equal(t, CheapestSort, page.Sort)
+	require.Equal(t, int64(1), s.storage.(*fakeStorage).users[1].HistoryReplays)
+
+	b.Sort = ClosestSort
+	_, err = s.BrowseApartments(ctx, b)
+	require.Equal(t, errLocationNotSet, err)
+}
+
+func TestParseCursor(t *testing.T) {
+	testCases := []struct {
+		testCaseName      string
+		cursor            string
+		expectedSort      ApartmentSort
+		expectedDirection CursorDirection
+		expectedID        int64
+		expectedErr       error
+	}{
+		{
+			testCaseName:      "valid",
+			cursor:            formatCursor(ClosestSort, BeforeCursor, 12),
+			expectedSort:      ClosestSort,
+			expectedDirection: BeforeCursor,
+			expectedID:        12,
+		},
+		{
+			testCaseName:      "hashed id",
+			cursor:            formatCursor(NewestSort, AfterCursor, math.MaxInt64),
+			expectedSort:      NewestSort,
+			expectedDirection: AfterCursor,
+			expectedID:        math.MaxInt64,
+		},
+		{
+			testCaseName: "no id",
+			cursor:       "1=",
+			expectedErr:  errInvalidCursor,
+		},
+		{
+			testCaseName: "invalid",
+			cursor:       "cursor",
+			expectedErr:  errInvalidCursor,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCaseName, func(t *testing.T) {
+			sort, direction, id, err := parseCursor(tc.cursor)
+			require.Equal(t, tc.expectedErr, err)
+			require.Equal(t, tc.expectedSort, sort)
+			require.Equal(t, tc.expectedDirection, direction)
+			require.Equal(t, tc.expectedID, id)
+			require.LessOrEqual(t, len(tc.cursor), 13)
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+var (
+	crawlScopeInterval = 10 * time.Minute
+	// maxCrawlScopeCities is the number of the cities after which all cities are crawled
+	maxCrawlScopeCities = 3
+)
+
+func (s *service) startCrawlScopeUpdates() {
+	ticker := time.NewTicker(crawlScopeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.crawlScopeCh:
+		}
+
+		if err := s.updateCrawlScope(s.ctx); err != nil {
+			slog.Error("update crawl scope", "err", err)
+		}
+	}
+}
+
+// requestCrawlScopeUpdate updates the crawl scope without waiting for the ticker,
+// the saved or joined filter is crawled by the next crawl.
+func (s *service) requestCrawlScopeUpdate() {
+	select {
+	case s.crawlScopeCh <- struct{}{}:
+	default:
+	}
+}
+
+// updateCrawlScope pushes the union of the active filters to the provider,
+// so the page budget is spent on the relevant listings.
+func (s *service) updateCrawlScope(ctx context.Context) error {
+	filters, err := s.filter.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i, f := range filters {
+		if f.Currency.OrUSD() == USD {
+			continue
+		}
+
+		usdFilter, ok := f.InCurrency(s.rates.Rates(), USD)
+		if !ok {
+			// the prices in the unknown currency don't restrict the scope
+			usdFilter.MinPrice, usdFilter.MaxPrice = nil, nil
+		}
+		filters[i] = usdFilter
+	}
+
+	s.apartment.SetCrawlScope(crawlScope(filters))
+	return nil
+}
+
+// crawlScope returns the union of the active filters, the parameter isn't restricted
+// when any filter doesn't set it or the filters are too diverse.
+func crawlScope(filters []Filter) CrawlScope {
+	var (
+		scope    CrawlScope
+		cities   = make(map[string]struct{})
+		isActive bool
+
+		isAllCities, isAllAdTypes, isAnyMinPrice, isAnyMaxPrice bool
+	)
+
+	for _, f := range filters {
+		if f.PauseTimestamp != nil {
+			continue
+		}
+
+		if !isActive {
+			isActive = true
+			scope.AdType = f.AdType
+			scope.MinPrice = f.MinPrice
+			scope.MaxPrice = f.MaxPrice
+		}
+
+		if f.City == nil {
+			isAllCities = true
+		} else {
+			cities[*f.City] = struct{}{}
+		}
+
+		if f.AdType == nil || (scope.AdType != nil && *scope.AdType != *f.AdType) {
+			isAllAdTypes = true
+		}
+
+		if f.MinPrice == nil {
+			isAnyMinPrice = true
+		} else if scope.MinPrice != nil && *f.MinPrice < *scope.MinPrice {
+			scope.MinPrice = f.MinPrice
+		}
+
+		if f.MaxPrice == nil {
+			isAnyMaxPrice = true
+		} else if scope.MaxPrice != nil && *f.MaxPrice > *scope.MaxPrice {
+			scope.MaxPrice = f.MaxPrice
+		}
+	}
+
+	if !isActive {
+		return CrawlScope{}
+	}
+
+	if !isAllCities && len(cities) <= maxCrawlScopeCities {
+		scope.Cities = make([]string, 0, len(cities))
+		for city := range cities {
+			scope.Cities = append(scope.Cities, city)
+		}
+		slices.Sort(scope.Cities)
+	}
+
+	if isAllAdTypes {
+		scope.AdType = nil
+	}
+	if isAnyMinPrice {
+		scope.MinPrice = nil
+	}
+	if isAnyMaxPrice {
+		scope.MaxPrice = nil
+	}
+	return scope
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCrawlScope(t *testing.T) {
+	var (
+		tbilisi, batumi, kutaisi, rustavi = "Tbilisi", "Batumi", "Kutaisi", "Rustavi"
+
+		rent, sale = RentAdType, SaleAdType
+
+		price300, price500, price800, price1000 = 300.0, 500.0, 800.0, 1000.0
+
+		paused int64 = 1
+	)
+
+	testCases := []struct {
+		testCaseName string
+		filters      []Filter
+		expected     CrawlScope
+	}{
+		{
+			testCaseName: "no filters",
+			expected:     CrawlScope{},
+		},
+		{
+			testCaseName: "union",
+			filters: []Filter{
+				{City: &tbilisi, AdType: &rent, MinPrice: &price500, MaxPrice: &price800},
+				{City: &batumi, AdType: &rent, MinPrice: &price300, MaxPrice: &price1000},
+				{City: &tbilisi, AdType: &rent, MinPrice: &price500, MaxPrice: &price500},
+			},
+			expected: CrawlScope{
+				Cities:   []string{batumi, tbilisi},
+				AdType:   &rent,
+				MinPrice: &price300,
+				MaxPrice: &price1000,
+			},
+		},
+		{
+			testCaseName: "paused filter is skipped",
+			filters: []Filter{
+				{City: &tbilisi, AdType: &rent, MaxPrice: &price800},
+				{PauseTimestamp: &paused},
+			},
+			expected: CrawlScope{
+				Cities:   []string{tbilisi},
+				AdType:   &rent,
+				MaxPrice: &price800,
+			},
+		},
+		{
+			testCaseName: "unrestricted parameters",
+			filters: []Filter{
+				{City: &tbilisi, AdType: &rent, MinPrice: &price300},
+				{AdType: &sale, MaxPrice: &price800},
+			},
+			expected: CrawlScope{},
+		},
+		{
+			testCaseName: "too diverse cities",
+			filters: []Filter{
+				{City: &tbilisi, AdType: &sale},
+				{City: &batumi, AdType: &sale},
+				{City: &kutaisi, AdType: &sale},
+				{City: &rustavi, AdType: &sale},
+			},
+			expected: CrawlScope{AdType: &sale},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCaseName, func(t *testing.T) {
+			require.Equal(t, tc.expected, crawlScope(tc.filters))
+		})
+	}
+}
+
+func TestFilterChangeRequestsCrawlScope(t *testing.T) {
+	token := "token"
+	ctx := context.Background()
+	s := &service{
+		filter: &fakeFilter{filters: map[string]Filter{
+			"shared": {ID: "shared", User: &User{ID: 1}, ShareToken: &token},
+		}},
+		storage:      &fakeStorage{},
+		crawlScopeCh: make(chan struct{}, 1),
+	}
+
+	_, err := s.JoinFilter(ctx, FilterJoin{User: User{ID: 2}, Token: token})
+	require.NoError(t, err)
+	require.Len(t, s.crawlScopeCh, 1)
+
+	// the requests are merged till the loop takes them
+	require.NoError(t, s.DeleteFilter(ctx, Filter{User: &User{ID: 1}}))
+	require.Len(t, s.crawlScopeCh, 1)
+
+	<-s.crawlScopeCh
+	require.NoError(t, s.DeleteFilter(ctx, Filter{User: &User{ID: 1}}))
+	require.Len(t, s.crawlScopeCh, 1)
+}
@@ -0,0 +1,37 @@
+package server
+
+import (
+	"log/slog"
+	"time"
+)
+
+// CrawlCursor is the newest listing seen by the crawl of the provider.
+type CrawlCursor struct {
+	Provider    string
+	OrderDate   time.Time
+	ApartmentID int64
+	// BackfilledAt is the start of the last crawl of all pages
+	BackfilledAt time.Time
+}
+
+// CrawlScope restricts the listings requested from the provider, the nil fields aren't restricted.
+type CrawlScope struct {
+	Cities   []string
+	AdType   *int64
+	MinPrice *float64
+	MaxPrice *float64
+}
+
+func (s CrawlScope) LogValue() slog.Value {
+	attrs := []slog.Attr{slog.Any("cities", s.Cities)}
+	if s.AdType != nil {
+		attrs = append(attrs, slog.Int64("ad_type", *s.AdType))
+	}
+	if s.MinPrice != nil {
+		attrs = append(attrs, slog.Float64("min_price", *s.MinPrice))
+	}
+	if s.MaxPrice != nil {
+		attrs = append(attrs, slog.Float64("max_price", *s.MaxPrice))
+	}
+	return slog.GroupValue(attrs...)
+}
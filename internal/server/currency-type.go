@@ -0,0 +1,77 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Currency is the ISO code of the currency, the empty currency is USD.
+type Currency string
+
+const (
+	USD Currency = "USD"
+	GEL Currency = "GEL"
+	EUR Currency = "EUR"
+)
+
+// Currencies are the currencies the users can choose.
+var Currencies = []Currency{USD, GEL, EUR}
+
+var currencySymbols = map[Currency]string{
+	USD: "$",
+	GEL: "₾",
+	EUR: "€",
+}
+
+// ParseCurrency parses the code of the supported currency case-insensitively.
+func ParseCurrency(code string) (Currency, bool) {
+	c := Currency(strings.ToUpper(strings.TrimSpace(code)))
+	_, ok := currencySymbols[c]
+	return c, ok
+}
+
+// OrUSD returns USD for the empty currency.
+func (c Currency) OrUSD() Currency {
+	if c == "" {
+		return USD
+	}
+	return c
+}
+
+func (c Currency) Symbol() string {
+	if symbol, ok := currencySymbols[c.OrUSD()]; ok {
+		return symbol
+	}
+	return string(c)
+}
+
+// Format rounds the amount and appends the symbol of the currency: 1200$, 3240₾.
+func (c Currency) Format(amount float64) string {
+	return fmt.Sprintf("%.0f%s", amount, c.Symbol())
+}
+
+// Rates are the amounts of the currencies for 1 USD.
+type Rates map[Currency]float64
+
+// Convert converts the amount between the currencies, false is returned when the rate is unknown.
+func (r Rates) Convert(amount float64, from, to Currency) (float64, bool) {
+	from, to = from.OrUSD(), to.OrUSD()
+	if from == to {
+		return amount, true
+	}
+
+	fromRate, isFromKnown := r.rate(from)
+	toRate, isToKnown := r.rate(to)
+	if !isFromKnown || !isToKnown {
+		return amount, false
+	}
+	return amount / fromRate * toRate, true
+}
+
+func (r Rates) rate(c Currency) (float64, bool) {
+	if c == USD {
+		return 1, true
+	}
+	rate, ok := r[c]
+	return rate, ok && rate > 0
+}
@@ -0,0 +1,188 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRatesConvert(t *testing.T) {
+	rates := Rates{GEL: 2.7, EUR: 0.9}
+
+	testCases := []struct {
+		testCaseName string
+		amount       float64
+		from, to     Currency
+		expected     float64
+		expectedOk   bool
+	}{
+		{
+			testCaseName: "same currency",
+			amount:       1000,
+			from:         GEL,
+			to:           GEL,
+			expected:     1000,
+			expectedOk:   true,
+		},
+		{
+			testCaseName: "empty currency is USD",
+			amount:       1000,
+			from:         "",
+			to:           USD,
+			expected:     1000,
+			expectedOk:   true,
+		},
+		{
+			testCaseName: "from USD",
+			amount:       1000,
+			from:         USD,
+			to:           GEL,
+			expected:     2700,
+			expectedOk:   true,
+		},
+		{
+			testCaseName: "to USD",
+			amount:       2700,
+			from:         GEL,
+			to:           USD,
+			expected:     1000,
+			expectedOk:   true,
+		},
+		{
+			testCaseName: "cross rate",
+			amount:       900,
+			from:         EUR,
+			to:           GEL,
+			expected:     2700,
+			expectedOk:   true,
+		},
+		{
+			testCaseName: "unknown rate",
+			amount:       1000,
+			from:         USD,
+			to:           "TRY",
+			expected:     1000,
+			expectedOk:   false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCaseName, func(t *testing.T) {
+			amount, ok := rates.Convert(tc.amount, tc.from, tc.to)
+			require.Equal(t, tc.expectedOk, ok)
+			require.InDelta(t, tc.expected, amount, 0.001)
+		})
+	}
+}
+
+func TestFilterInCurrency(t *testing.T) {
+	minPrice, maxPrice := 1350.0, 2700.0
+	f := Filter{MinPrice: &minPrice, MaxPrice: &maxPrice, Currency: GEL}
+
+	usd, ok := f.InCurrency(Rates{GEL: 2.7}, USD)
+	require.True(t, ok)
+	require.Equal(t, USD, usd.Currency)
+	require.InDelta(t, 500, *usd.MinPrice, 0.001)
+	require.InDelta(t, 1000, *usd.MaxPrice, 0.001)
+
+	// the prices of the filter aren't changed
+	require.Equal(t, 1350.0, *f.MinPrice)
+	require.Equal(t, GEL, f.Currency)
+
+	// the filter isn't changed without the rate
+	eur := Filter{MinPrice: &minPrice, MaxPrice: &maxPrice, Currency: EUR}
+	unchanged, ok := eur.InCurrency(Rates{GEL: 2.7}, USD)
+	require.False(t, ok)
+	require.Equal(t, eur, unchanged)
+	require.Equal(t, 1350.0, *unchanged.MinPrice)
+
+	_, ok = f.InCurrency(nil, USD)
+	require.False(t, ok)
+
+	// the filter without the prices doesn't need the rate
+	f.MinPrice, f.MaxPrice = nil, nil
+	usd, ok = f.InCurrency(nil, USD)
+	require.True(t, ok)
+	require.Nil(t, usd.MinPrice)
+	require.Equal(t, USD, usd.Currency)
+}
+
+func TestFilterIsFitPrice(t *testing.T) {
+	maxPrice := 2700.0
+
+	testCases := []struct {
+		testCaseName string
+		filter       Filter
+		apartment    Apartment
+		expected     bool
+	}{
+		{
+			testCaseName: "original price in the filter currency",
+			filter:       Filter{MaxPrice: &maxPrice, Currency: GEL},
+			// the price in USD is converted by the rate 2.65, the original price is at the limit
+			apartment: Apartment{Price: 1018.87, OriginalPrice: 2700, Currency: GEL},
+			expected:  true,
+		},
+		{
+			testCaseName: "original price above the limit",
+			filter:       Filter{MaxPrice: &maxPrice, Currency: GEL},
+			apartment:    Apartment{Price: 999, OriginalPrice: 2701, Currency: GEL},
+			expected:     false,
+		},
+		{
+			testCaseName: "price in USD for the other currency",
+			filter:       Filter{MaxPrice: &maxPrice},
+			apartment:    Apartment{Price: 2600, OriginalPrice: 7000, Currency: GEL},
+			expected:     true,
+		},
+		{
+			testCaseName: "listing in USD",
+			filter:       Filter{MaxPrice: &maxPrice},
+			apartment:    Apartment{Price: 2800},
+			expected:     false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCaseName, func(t *testing.T) {
+			require.Equal(t, tc.expected, tc.filter.IsFit(&tc.apartment))
+		})
+	}
+}
+
+func TestConvertPrice(t *testing.T) {
+	s := &service{rates: fakeRates{GEL: 2.5}}
+
+	a := Apartment{OriginalPrice: 2500, Currency: GEL}
+	require.True(t, s.convertPrice(&a))
+	require.InDelta(t, 1000, a.Price, 0.001)
+
+	a = Apartment{OriginalPrice: 2500, Currency: EUR}
+	require.False(t, s.convertPrice(&a))
+	require.Zero(t, a.Price)
+
+	a = Apartment{Price: 1000, OriginalPrice: 1000, Currency: USD}
+	require.True(t, s.convertPrice(&a))
+	require.Equal(t, 1000.0, a.Price)
+}
+
+func TestWithUSDPrices(t *testing.T) {
+	s := &service{rates: fakeRates{GEL: 2.5}}
+	minPrice, maxPrice := 1000.0, 2500.0
+
+	// the prices of the filter are kept for the apartments in its currency
+	f, err := s.withUSDPrices(Filter{MinPrice: &minPrice, MaxPrice: &maxPrice, Currency: GEL})
+	require.NoError(t, err)
+	require.Equal(t, 1000.0, *f.MinPrice)
+	require.Equal(t, 2500.0, *f.MaxPrice)
+	require.InDelta(t, 400, *f.USDMinPrice, 0.001)
+	require.InDelta(t, 1000, *f.USDMaxPrice, 0.001)
+
+	f, err = s.withUSDPrices(Filter{MinPrice: &minPrice})
+	require.NoError(t, err)
+	require.Equal(t, 1000.0, *f.USDMinPrice)
+	require.Nil(t, f.USDMaxPrice)
+
+	_, err = s.withUSDPrices(Filter{MinPrice: &minPrice, Currency: EUR})
+	require.Equal(t, errUnknownRate, err)
+}
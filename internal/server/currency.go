@@ -0,0 +1,62 @@
+package server
+
+import (
+	"context"
+)
+
+// Rates returns the current currency rates for 1 USD.
+func (s *service) Rates(_ context.Context) Rates {
+	return s.rates.Rates()
+}
+
+// SetCurrency changes the currency of the messages of the user.
+func (s *service) SetCurrency(ctx context.Context, u User) (*User, error) {
+	currency, ok := ParseCurrency(string(u.Currency))
+	if !ok {
+		return nil, errUnknownCurrency
+	}
+
+	user, err := s.storage.User(ctx, Filter{User: &User{ID: u.ID}})
+	if err != nil {
+		return nil, err
+	}
+
+	user.Currency = currency
+	if err := s.storage.InsertUser(ctx, user); err != nil {
+		return nil, err
+	}
+
+	s.setUser(user)
+	return &user, nil
+}
+
+// convertPrice sets the price in USD of the listing in the other currency,
+// false is returned when the rate is unknown and the listing has no price in USD.
+func (s *service) convertPrice(a *Apartment) bool {
+	if a.Currency.OrUSD() == USD || a.OriginalPrice <= 0 {
+		return true
+	}
+
+	price, ok := s.rates.Rates().Convert(a.OriginalPrice, a.Currency, USD)
+	if !ok {
+		return false
+	}
+
+	a.Price = price
+	return true
+}
+
+// withUSDPrices sets the prices of the filter in USD, the stored apartments in the currency of the filter
+// are compared by the original price and the others by the price in USD, the same way as IsFit.
+func (s *service) withUSDPrices(f Filter) (Filter, error) {
+	usd := f
+	if f.Currency.OrUSD() != USD {
+		var ok bool
+		if usd, ok = f.InCurrency(s.rates.Rates(), USD); !ok {
+			return f, errUnknownRate
+		}
+	}
+
+	f.USDMinPrice, f.USDMaxPrice = usd.MinPrice, usd.MaxPrice
+	return f, nil
+}
@@ -0,0 +1,32 @@
+package server
+
+import "time"
+
+// Delivery is the apartment delivered to the user.
+type Delivery struct {
+	UserID      int64
+	ApartmentID int64
+	// MessageID is the client message with the apartment, it is edited when the apartment is removed
+	MessageID   int64
+	DeliveredAt time.Time
+}
+
+type DeliveryFilter struct {
+	UserID      *int64
+	ApartmentID *int64
+}
+
+// PendingDelivery is the apartment postponed by the plan delay or collected into the digest of the user.
+type PendingDelivery struct {
+	UserID      int64
+	ApartmentID int64
+	// Filters are the names of the filters of the user matched the apartment
+	Filters []string
+	Score   *Score
+	SendAt  time.Time
+}
+
+type PendingDeliveryFilter struct {
+	UserID   *int64
+	SendTill *time.Time
+}
@@ -0,0 +1,210 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// pendingDeliveryInterval is how often the pending deliveries are checked
+var pendingDeliveryInterval = time.Minute
+
+// SaveDelivery records that the apartment was delivered to the user.
+func (s *service) SaveDelivery(ctx context.Context, d Delivery) error {
+	if d.DeliveredAt.IsZero() {
+		d.DeliveredAt = time.Now()
+	}
+	return s.storage.SaveDelivery(ctx, d)
+}
+
+// deliver sends the apartment to the subscribers, the delivery postponed by the delay
+// of the user plan or collected into the digest of the user is stored till the send time.
+func (s *service) deliver(a Apartment, now time.Time) {
+	for at, userIDs := range s.deliverySchedule(a, now) {
+		if !at.After(now) {
+			go s.sendToSubscribers(apartmentForUsers(a, userIDs))
+			continue
+		}
+
+		for _, userID := range userIDs {
+			d := PendingDelivery{
+				UserID:      userID,
+				ApartmentID: a.ID,
+				Filters:     a.Filter[userID],
+				SendAt:      at,
+			}
+			if score, ok := a.Score[userID]; ok {
+				d.Score = &score
+			}
+
+			if err := s.storage.SavePendingDelivery(s.ctx, d); err != nil {
+				slog.Error("save pending delivery", "user_id", userID, "apartment_id", a.ID, "err", err)
+			}
+		}
+	}
+}
+
+func (s *service) startPendingDeliveries() {
+	ticker := time.NewTicker(pendingDeliveryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.sendPendingDeliveries(s.ctx, time.Now()); err != nil {
+				slog.Error("send pending deliveries", "err", err)
+			}
+		}
+	}
+}
+
+// sendPendingDeliveries sends the pending deliveries due by now, the apartments of the user
+// with the digest are sent as one DigestNotification.
+func (s *service) sendPendingDeliveries(ctx context.Context, now time.Time) error {
+	pending, err := s.storage.PendingDeliveries(ctx, PendingDeliveryFilter{SendTill: &now})
+	if err != nil {
+		return err
+	}
+
+	byUser := make(map[int64][]PendingDelivery)
+	for _, d := range pending {
+		byUser[d.UserID] = append(byUser[d.UserID], d)
+	}
+
+	for userID, deliveries := range byUser {
+		apartments := s.pendingApartments(ctx, deliveries)
+
+		switch {
+		case len(apartments) == 0:
+		case s.cachedUser(userID).DigestInterval > 0:
+			s.sendNotification(Notification{
+				Type:       DigestNotification,
+				Mark:       ApartmentMark{UserID: userID},
+				Apartments: apartments,
+			})
+		default:
+			for _, a := range apartments {
+				s.sendToSubscribers(a)
+			}
+		}
+
+		if err := s.storage.DeletePendingDeliveries(ctx, PendingDeliveryFilter{UserID: &userID, SendTill: &now}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pendingApartments loads the apartments of the pending deliveries,
+// the apartments removed or hidden in the meantime are skipped.
+func (s *service) pendingApartments(ctx context.Context, deliveries []PendingDelivery) []Apartment {
+	apartments := make([]Apartment, 0, len(deliveries))
+	for _, d := range deliveries {
+		if s.isHidden(d.UserID, d.ApartmentID) {
+			continue
+		}
+
+		a, err := s.storage.Apartment(ctx, d.ApartmentID)
+		if err != nil {
+			slog.Debug("pending apartment", "user_id", d.UserID, "apartment_id", d.ApartmentID, "err", err)
+			continue
+		}
+
+		s.estimator.Estimate(&a)
+		a.Filter = map[int64][]string{d.UserID: d.Filters}
+		if d.Score != nil {
+			a.Score = map[int64]Score{d.UserID: *d.Score}
+		}
+		apartments = append(apartments, a)
+	}
+	return apartments
+}
+
+// deliverySchedule groups the users of the apartment by the delivery time.
+func (s *service) deliverySchedule(a Apartment, now time.Time) map[time.Time][]int64 {
+	schedule := make(map[time.Time][]int64)
+	for userID := range a.Filter {
+		at := s.deliveryTime(s.cachedUser(userID), now)
+		schedule[at] = append(schedule[at], userID)
+	}
+	return schedule
+}
+
+func (s *service) deliveryTime(u User, now time.Time) time.Time {
+	at := now
+	if !u.IsSuperuser {
+		at = at.Add(s.plans.Get(u.ActivePlan(now)).DeliveryDelay)
+	}
+
+	if u.DigestInterval > 0 {
+		// the digest is sent at the end of the interval
+		at = at.Truncate(u.DigestInterval).Add(u.DigestInterval)
+	}
+	return at
+}
+
+// apartmentForUsers returns the copy of the apartment matched only for the users.
+func apartmentForUsers(a Apartment, userIDs []int64) Apartment {
+	filters := make(map[int64][]string, len(userIDs))
+	var scores map[int64]Score
+	for _, userID := range userIDs {
+		filters[userID] = a.Filter[userID]
+
+		score, ok := a.Score[userID]
+		if !ok {
+			continue
+		}
+		if scores == nil {
+			scores = make(map[int64]Score, len(userIDs))
+		}
+		scores[userID] = score
+	}
+
+	a.Filter = filters
+	a.Score = scores
+	return a
+}
+
+func (s *service) updateUsers() error {
+	users, err := s.storage.Users(s.ctx)
+	if err != nil {
+		return err
+	}
+
+	cache := make(map[int64]User, len(users))
+	for _, u := range users {
+		cache[u.ID] = u
+	}
+
+	s.usersMutex.Lock()
+	defer s.usersMutex.Unlock()
+
+	s.users = cache
+	return nil
+}
+
+func (s *service) setUser(u User) {
+	s.usersMutex.Lock()
+	defer s.usersMutex.Unlock()
+
+	if s.users == nil {
+		s.users = make(map[int64]User)
+	}
+	s.users[u.ID] = u
+}
+
+func (s *service) deleteUser(userID int64) {
+	s.usersMutex.Lock()
+	defer s.usersMutex.Unlock()
+
+	delete(s.users, userID)
+}
+
+func (s *service) cachedUser(userID int64) User {
+	s.usersMutex.RLock()
+	defer s.usersMutex.RUnlock()
+
+	return s.users[userID]
+}
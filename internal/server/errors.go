@@ -3,5 +3,26 @@ package server
 import "errors"
 
 var (
-	errLimitExceeded = errors.New("the limit on the number of filters is 1")
+	// ErrNotFound is returned by the storage when the requested item isn't stored
+	ErrNotFound = errors.New("not found")
+
+	errLimitExceeded = errors.New("the filter limit of your plan is reached, see /plans")
+	errNoSubscribers = errors.New("no subscribers connected")
+
+	errApartmentNotFound = errors.New("apartment not found")
+	errInvalidCursor     = errors.New("invalid cursor")
+	errLocationNotSet    = errors.New("set the location of the filter to sort by distance")
+
+	errPermissionDenied     = errors.New("only the owner can change the filter")
+	errSharedFilterNotFound = errors.New("the shared filter is not found, ask for a new link")
+
+	errHistoryLimitExceeded = errors.New("the daily history limit of your plan is reached, see /plans")
+	errDigestNotAllowed     = errors.New("the digest interval is not available on your plan, see /plans")
+	errUnknownCurrency      = errors.New("unknown currency, use USD, GEL or EUR")
+	errUnknownRate          = errors.New("the rate of the filter currency is unknown, try later")
+	errPlanNotFound         = errors.New("plan not found")
+	errFreePlanNotFound     = errors.New("the free plan is not configured")
+
+	errUserBanned          = errors.New("you are banned, contact the admin")
+	errRefreshIsInProgress = errors.New("the apartments are already being refreshed")
 )
@@ -0,0 +1,266 @@
+package server
+
+import (
+	"context"
+	"slices"
+)
+
+// fakeStorage keeps the users, the apartments, the cities and the pending deliveries in memory
+type fakeStorage struct {
+	storage
+	users       map[int64]User
+	apartments  []Apartment
+	archived    map[int64]RemovalReason
+	cities      []City
+	savedCities []City
+	pending     []PendingDelivery
+}
+
+func (s *fakeStorage) User(_ context.Context, f Filter) (User, error) {
+	return s.users[f.User.ID], nil
+}
+
+func (s *fakeStorage) InsertUser(_ context.Context, u User) error {
+	if s.users == nil {
+		s.users = make(map[int64]User)
+	}
+	s.users[u.ID] = u
+	return nil
+}
+
+func (s *fakeStorage) Apartment(_ context.Context, id int64) (Apartment, error) {
+	for _, a := range s.apartments {
+		if a.ID == id {
+			return a, nil
+		}
+	}
+	return Apartment{}, ErrNotFound
+}
+
+func (s *fakeStorage) SaveApartment(_ context.Context, a Apartment) error {
+	s.apartments = append(s.apartments, a)
+	return nil
+}
+
+func (s *fakeStorage) ApartmentCount(context.Context, Filter) (int64, error) {
+	return int64(len(s.apartments)), nil
+}
+
+// SearchApartments keeps the order of the apartments for any sort, the sort key is the index of the apartment
+func (s *fakeStorage) SearchApartments(_ context.Context, q ApartmentSearch) ([]Apartment, error) {
+	apartments := s.cursorApartments(q.Cursor)
+	from := min(q.Offset, int64(len(apartments)))
+	to := min(q.Offset+q.Limit, int64(len(apartments)))
+	return apartments[from:to], nil
+}
+
+func (s *fakeStorage) SearchApartmentCount(_ context.Context, q ApartmentSearch) (int64, error) {
+	return int64(len(s.cursorApartments(q.Cursor))), nil
+}
+
+func (s *fakeStorage) ApartmentSortKey(_ context.Context, _ ApartmentSearch, id int64) (float64, error) {
+	for i, a := range s.apartments {
+		if a.ID == id {
+			return float64(i), nil
+		}
+	}
+	return 0, ErrNotFound
+}
+
+func (s *fakeStorage) cursorApartments(c *ApartmentCursor) []Apartment {
+	if c == nil {
+		return s.apartments
+	}
+
+	var result []Apartment
+	for i, a := range s.apartments {
+		key := float64(i)
+		switch {
+		case c.Direction == AtCursor && (key > c.Key || key == c.Key && a.ID >= c.ApartmentID):
+		case c.Direction == AfterCursor && (key > c.Key || key == c.Key && a.ID > c.ApartmentID):
+		case c.Direction == BeforeCursor && (key < c.Key || key == c.Key && a.ID < c.ApartmentID):
+			result = append([]Apartment{a}, result...)
+			continue
+		default:
+			continue
+		}
+		result = append(result, a)
+	}
+	return result
+}
+
+func (s *fakeStorage) ArchiveApartment(_ context.Context, a Apartment, reason RemovalReason) error {
+	if s.archived == nil {
+		s.archived = make(map[int64]RemovalReason)
+	}
+	s.archived[a.ID] = reason
+	return nil
+}
+
+func (s *fakeStorage) Deliveries(context.Context, DeliveryFilter) ([]Delivery, error) {
+	return nil, nil
+}
+
+func (s *fakeStorage) DeleteDeliveries(context.Context, DeliveryFilter) error {
+	return nil
+}
+
+func (s *fakeStorage) ApartmentMarks(context.Context, ApartmentMarkFilter) ([]ApartmentMark, error) {
+	return nil, nil
+}
+
+func (s *fakeStorage) Cities(context.Context) ([]City, error) {
+	return s.cities, nil
+}
+
+func (s *fakeStorage) SaveCity(_ context.Context, c City) error {
+	s.savedCities = append(s.savedCities, c)
+	return nil
+}
+
+func (s *fakeStorage) SavePendingDelivery(_ context.Context, d PendingDelivery) error {
+	s.pending = append(s.pending, d)
+	return nil
+}
+
+func (s *fakeStorage) PendingDeliveries(_ context.Context, f PendingDeliveryFilter) ([]PendingDelivery, error) {
+	var result []PendingDelivery
+	for _, d := range s.pending {
+		if !d.SendAt.After(*f.SendTill) {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+
+func (s *fakeStorage) DeletePendingDeliveries(_ context.Context, f PendingDeliveryFilter) error {
+	s.pending = slices.DeleteFunc(s.pending, func(d PendingDelivery) bool {
+		return d.UserID == *f.UserID && !d.SendAt.After(*f.SendTill)
+	})
+	return nil
+}
+
+type fakeFilter struct {
+	filter
+	filters map[string]Filter
+}
+
+func (s *fakeFilter) Add(_ context.Context, f Filter) (*Filter, error) {
+	if len(f.ID) == 0 {
+		f.ID = "clone"
+	}
+	s.filters[f.ID] = f
+	return &f, nil
+}
+
+func (s *fakeFilter) Get(_ context.Context, f Filter) (*Filter, error) {
+	for _, filter := range s.filters {
+		if f.ID != "" && filter.ID != f.ID {
+			continue
+		}
+		if f.ShareToken != nil && (filter.ShareToken == nil || *filter.ShareToken != *f.ShareToken) {
+			continue
+		}
+		return &filter, nil
+	}
+	return nil, errSharedFilterNotFound
+}
+
+func (s *fakeFilter) GetForUser(_ context.Context, u int64) ([]Filter, error) {
+	var result []Filter
+	for _, f := range s.filters {
+		if f.OwnedBy(u) {
+			result = append(result, f)
+		}
+	}
+	return result, nil
+}
+
+func (s *fakeFilter) GetShared(_ context.Context, u int64) ([]Filter, error) {
+	var result []Filter
+	for _, f := range s.filters {
+		if !f.OwnedBy(u) && slices.Contains(f.Recipients(), u) {
+			result = append(result, f)
+		}
+	}
+	return result, nil
+}
+
+func (s *fakeFilter) Delete(_ context.Context, f Filter) error {
+	for id, filter := range s.filters {
+		if f.User != nil && filter.OwnedBy(f.User.ID) {
+			delete(s.filters, id)
+		}
+	}
+	return nil
+}
+
+func (s *fakeFilter) Rate(context.Context, Filter, *Apartment) bool {
+	return true
+}
+
+type fakeApartment struct {
+	apartment
+
+	state   BreakerState
+	removal RemovalReason
+	err     error
+	checked int
+}
+
+func (s *fakeApartment) Check(context.Context, Apartment) (RemovalReason, error) {
+	s.checked++
+	if s.err != nil {
+		return NotRemoved, s.err
+	}
+	return s.removal, nil
+}
+
+func (s *fakeApartment) ProviderState() ProviderState {
+	return ProviderState{Provider: "fake", State: s.state}
+}
+
+func (s *fakeApartment) DeleteFromCache(Apartment) {}
+
+type fakeEstimator struct{}
+
+func (fakeEstimator) Estimate(*Apartment) {}
+
+// fakePlaces knows Vera of Mtatsminda in Tbilisi by the name and the coordinates
+type fakePlaces struct{}
+
+func (fakePlaces) Resolve(city, district string) (Place, bool) {
+	if city != "Tbilisi" && city != "თბილისი" {
+		return Place{City: city, District: district}, false
+	}
+
+	switch district {
+	case "":
+		return Place{City: "Tbilisi"}, true
+	case "Mtatsminda":
+		return Place{City: "Tbilisi", District: "Mtatsminda"}, true
+	case "Vera", "ვერა":
+		return Place{City: "Tbilisi", District: "Mtatsminda", Subdistrict: "Vera"}, true
+	}
+	return Place{City: "Tbilisi", District: district}, false
+}
+
+func (fakePlaces) Locate(city string, c Coordinates) (Place, bool) {
+	if city != "" && city != "Tbilisi" {
+		return Place{}, false
+	}
+	if c.Lat > 41.70 && c.Lat < 41.71 && c.Lng > 44.77 && c.Lng < 44.79 {
+		return Place{City: "Tbilisi", District: "Mtatsminda", Subdistrict: "Vera"}, true
+	}
+	return Place{}, false
+}
+
+func (fakePlaces) Cities() []City {
+	return []City{{Name: "Tbilisi", District: map[string]struct{}{"Mtatsminda": {}, "Vera": {}}}}
+}
+
+type fakeRates Rates
+
+func (r fakeRates) Rates() Rates {
+	return Rates(r)
+}
@@ -24,12 +24,36 @@ type Filter struct {
 	IsOwner        *bool
 	Coordinates    *Coordinates
 	MaxDistance    *float64
+	MinScore       *float64
+
+	// Currency is the currency of MinPrice and MaxPrice, the empty currency is USD
+	Currency Currency
+	// USDMinPrice and USDMaxPrice are MinPrice and MaxPrice in USD, the queries of the stored apartments
+	// compare them with the apartments in the other currency like IsFit does
+	USDMinPrice *float64
+	USDMaxPrice *float64
+
+	// ShareToken is the token of the filter share link
+	ShareToken *string
+	// Subscribers receive apartments of the filter but can't change it
+	Subscribers []int64
 
 	TillTimestamp  *int64
 	FromTimestamp  *int64
 	PauseTimestamp *int64
 
-	ApartmentID *int64
+	ApartmentID  *int64
+	SubscriberID *int64
+	// ExcludedApartmentIDs are skipped by the apartment queries, e.g. hidden by the user
+	ExcludedApartmentIDs []int64
+}
+
+// FilterJoin is the request to join the shared filter.
+type FilterJoin struct {
+	User  User
+	Token string
+	// Clone copies the filter to the user instead of subscribing to it
+	Clone bool
 }
 
 type Coordinates struct {
@@ -37,6 +61,44 @@ type Coordinates struct {
 	Lng float64
 }
 
+// OwnedBy reports whether the user can change the filter.
+func (s *Filter) OwnedBy(userID int64) bool {
+	return s.User != nil && s.User.ID == userID
+}
+
+// Recipients returns the owner and the subscribers of the filter.
+func (s *Filter) Recipients() []int64 {
+	recipients := make([]int64, 0, len(s.Subscribers)+1)
+	if s.User != nil {
+		recipients = append(recipients, s.User.ID)
+	}
+	return append(recipients, s.Subscribers...)
+}
+
+// InCurrency returns the filter with the prices converted to the currency,
+// false and the filter without changes are returned when the rate of the prices is unknown.
+func (s Filter) InCurrency(r Rates, c Currency) (Filter, bool) {
+	if s.Currency.OrUSD() == c.OrUSD() {
+		return s, true
+	}
+
+	out := s
+	for _, price := range []**float64{&out.MinPrice, &out.MaxPrice} {
+		if *price == nil {
+			continue
+		}
+
+		v, ok := r.Convert(**price, s.Currency, c)
+		if !ok {
+			return s, false
+		}
+		*price = &v
+	}
+
+	out.Currency = c
+	return out, true
+}
+
 func (s *Filter) CheckDistance(a *Apartment) bool {
 	if s.MaxDistance == nil || s.Coordinates == nil {
 		return true
@@ -59,13 +121,18 @@ func (s *Filter) CheckDistrict(a *Apartment) bool {
 	}
 
 	for district := range s.District {
-		if strings.Contains(strings.ToLower(a.District), strings.ToLower(district)) {
+		if a.District != "" && strings.EqualFold(a.District, district) {
+			return true
+		}
+		if a.Subdistrict != "" && strings.EqualFold(a.Subdistrict, district) {
 			return true
 		}
 	}
 	return false
 }
 
+// IsFit checks the apartment by the filter, the prices of the filter must be in USD
+// unless the filter is in the currency of the listing.
 func (s *Filter) IsFit(a *Apartment) bool {
 	if s.PauseTimestamp != nil {
 		return false
@@ -88,11 +155,18 @@ func (s *Filter) IsFit(a *Apartment) bool {
 		isFit = isFit && strings.Contains(a.City, *s.City)
 	}
 
+	// the listing in the currency of the filter is compared by the original price,
+	// the other listings are compared by the price in USD
+	price := a.Price
+	if a.OriginalPrice > 0 && a.Currency.OrUSD() == s.Currency.OrUSD() {
+		price = a.OriginalPrice
+	}
+
 	if s.MinPrice != nil {
-		isFit = isFit && *s.MinPrice <= a.Price
+		isFit = isFit && *s.MinPrice <= price
 	}
 	if s.MaxPrice != nil {
-		isFit = isFit && *s.MaxPrice >= a.Price
+		isFit = isFit && *s.MaxPrice >= price
 	}
 
 	if s.MinRooms != nil {
@@ -0,0 +1,24 @@
+package server
+
+type NotificationType int64
+
+const (
+	ViewingReminderNotification NotificationType = iota + 1
+	ApartmentRemovedNotification
+	// ProviderStateNotification is sent to the admin when the provider goes down or recovers
+	ProviderStateNotification
+	// DigestNotification collects the apartments postponed for the user into one message
+	DigestNotification
+)
+
+// Notification is a message about the apartment for a single user.
+type Notification struct {
+	Type NotificationType
+	Mark ApartmentMark
+	// Delivery is set when the apartment was delivered to the user
+	Delivery *Delivery
+	// Provider is set for ProviderStateNotification
+	Provider *ProviderState
+	// Apartments are set for DigestNotification
+	Apartments []Apartment
+}
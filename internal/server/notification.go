@@ -0,0 +1,173 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/irbgeo/apartment-bot/internal/utils"
+)
+
+var (
+	viewingReminderInterval = time.Minute
+	viewingReminderBefore   = time.Hour
+
+	notificationBufferSize = 100
+)
+
+func (s *service) SubscribeNotifications(ctx context.Context) <-chan Notification {
+	var id int64
+	utils.UnpackVar(ctx, utils.IDKey, &id) // nolint: errcheck
+
+	subCh := make(chan Notification, notificationBufferSize)
+
+	s.notificationSubscribers.Store(id, subCh)
+
+	slog.Info("new notification subscriber", "id", id)
+
+	return subCh
+}
+
+func (s *service) UnsubscribeNotifications(ctx context.Context) {
+	var id int64
+	utils.UnpackVar(ctx, utils.IDKey, &id) // nolint: errcheck
+
+	subCh, isExist := s.notificationSubscribers.Load(id)
+	if isExist {
+		s.notificationSubscribers.Delete(id)
+		close(subCh.(chan Notification)) // nolint: errcheck
+	}
+
+	slog.Info("notification unsubscribed", "id", id)
+}
+
+func (s *service) sendNotification(n Notification) {
+	s.notificationSubscribers.Range(
+		func(_, value any) bool {
+			subCh := value.(chan Notification) // nolint: errcheck
+
+			select {
+			case subCh <- n:
+			default:
+				slog.Error("notification dropped", "user_id", n.Mark.UserID, "type", n.Type)
+			}
+
+			return true
+		},
+	)
+}
+
+func (s *service) startViewingReminders() {
+	ticker := time.NewTicker(viewingReminderInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.remindViewings(s.ctx); err != nil {
+				slog.Error("remind viewings", "err", err)
+			}
+		}
+	}
+}
+
+// remindViewings notifies users about the viewings starting within viewingReminderBefore.
+func (s *service) remindViewings(ctx context.Context) error {
+	now := time.Now()
+	till := now.Add(viewingReminderBefore)
+	isReminded := false
+
+	marks, err := s.storage.ApartmentMarks(ctx, ApartmentMarkFilter{
+		Statuses:    []PipelineStatus{ViewingBookedStatus},
+		ViewingFrom: &now,
+		ViewingTill: &till,
+		IsReminded:  &isReminded,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, m := range marks {
+		s.sendNotification(Notification{
+			Type: ViewingReminderNotification,
+			Mark: m,
+		})
+
+		m.IsReminded = true
+		if err := s.storage.SaveApartmentMark(ctx, m); err != nil {
+			slog.Error("save_apartment_mark", "user_id", m.UserID, "apartment_id", m.ApartmentID, "err", err)
+		}
+	}
+
+	return nil
+}
+
+// notifyRemoved notifies users who received, saved or track the apartment that it is no longer available.
+func (s *service) notifyRemoved(ctx context.Context, a Apartment) {
+	f := DeliveryFilter{ApartmentID: &a.ID}
+
+	deliveries, err := s.storage.Deliveries(ctx, f)
+	if err != nil {
+		slog.Error("get_deliveries", "apartment_id", a.ID, "err", err)
+	}
+
+	marks, err := s.storage.ApartmentMarks(ctx, ApartmentMarkFilter{ApartmentID: &a.ID})
+	if err != nil {
+		slog.Error("get_apartment_marks", "apartment_id", a.ID, "err", err)
+	}
+
+	for _, n := range removedNotifications(a, deliveries, marks) {
+		s.sendNotification(n)
+	}
+
+	if err := s.storage.DeleteDeliveries(ctx, f); err != nil {
+		slog.Error("delete_deliveries", "apartment_id", a.ID, "err", err)
+	}
+}
+
+// removedNotifications merges deliveries and marks of the removed apartment by user,
+// users who hid the apartment are skipped.
+func removedNotifications(a Apartment, deliveries []Delivery, marks []ApartmentMark) []Notification {
+	notifications := make(map[int64]*Notification, len(deliveries))
+	for _, d := range deliveries {
+		notifications[d.UserID] = &Notification{
+			Type: ApartmentRemovedNotification,
+			Mark: ApartmentMark{
+				UserID:      d.UserID,
+				ApartmentID: a.ID,
+				Apartment:   &a,
+			},
+			Delivery: &d,
+		}
+	}
+
+	for _, m := range marks {
+		if m.IsHidden {
+			delete(notifications, m.UserID)
+			continue
+		}
+
+		n, ok := notifications[m.UserID]
+		if !ok {
+			if !m.IsSaved && !m.Status.IsActive() {
+				continue
+			}
+
+			n = &Notification{Type: ApartmentRemovedNotification}
+			notifications[m.UserID] = n
+		}
+
+		if m.Apartment == nil {
+			m.Apartment = &a
+		}
+		n.Mark = m
+	}
+
+	result := make([]Notification, 0, len(notifications))
+	for _, n := range notifications {
+		result = append(result, *n)
+	}
+	return result
+}
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemovedNotifications(t *testing.T) {
+	a := Apartment{ID: 100}
+
+	deliveries := []Delivery{
+		{UserID: 1, ApartmentID: 100, MessageID: 11},
+		{UserID: 2, ApartmentID: 100, MessageID: 22},
+		{UserID: 3, ApartmentID: 100, MessageID: 33},
+	}
+
+	marks := []ApartmentMark{
+		// delivered and saved
+		{UserID: 2, ApartmentID: 100, IsSaved: true},
+		// delivered and hidden
+		{UserID: 3, ApartmentID: 100, IsHidden: true},
+		// tracked without delivery
+		{UserID: 4, ApartmentID: 100, Status: ContactedStatus},
+		// rejected without delivery
+		{UserID: 5, ApartmentID: 100, Status: RejectedStatus},
+	}
+
+	notifications := removedNotifications(a, deliveries, marks)
+	sort.Slice(notifications, func(i, j int) bool {
+		return notifications[i].Mark.UserID < notifications[j].Mark.UserID
+	})
+
+	require.Len(t, notifications, 3)
+
+	require.Equal(t, int64(1), notifications[0].Mark.UserID)
+	require.Equal(t, int64(11), notifications[0].Delivery.MessageID)
+	require.False(t, notifications[0].Mark.IsSaved)
+
+	require.Equal(t, int64(2), notifications[1].Mark.UserID)
+	require.Equal(t, int64(22), notifications[1].Delivery.MessageID)
+	require.True(t, notifications[1].Mark.IsSaved)
+
+	require.Equal(t, int64(4), notifications[2].Mark.UserID)
+	require.Nil(t, notifications[2].Delivery)
+	require.Equal(t, ContactedStatus, notifications[2].Mark.Status)
+
+	for _, n := range notifications {
+		require.Equal(t, ApartmentRemovedNotification, n.Type)
+		require.Equal(t, &a, n.Mark.Apartment)
+	}
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+const (
+	FreePlan = "free"
+	PlusPlan = "plus"
+	ProPlan  = "pro"
+)
+
+// Plan defines the limits of the subscription tier.
+type Plan struct {
+	Name string
+	// Price is the price of the Period in the smallest units of the payment currency, zero for free plans
+	Price  int64
+	Period time.Duration
+
+	MaxFilters int64
+	// MaxHistoryReplays is the number of history requests per day, zero is unlimited
+	MaxHistoryReplays int64
+	// DigestIntervals are the digest intervals the user can choose
+	DigestIntervals []time.Duration
+	// DeliveryDelay postpones the delivery of new apartments
+	DeliveryDelay time.Duration
+}
+
+// Plans are the available plans by name.
+type Plans map[string]Plan
+
+// UserPlan sets the plan of the user for the period, zero period never expires.
+type UserPlan struct {
+	UserID int64
+	Plan   string
+	Period time.Duration
+}
+
+// DefaultPlans are used when the plans are not configured.
+var DefaultPlans = Plans{
+	// the free plan keeps the single filter with the instant delivery and the unlimited history
+	FreePlan: {
+		Name:       FreePlan,
+		MaxFilters: 1,
+	},
+	PlusPlan: {
+		Name:            PlusPlan,
+		Price:           100,
+		Period:          30 * 24 * time.Hour,
+		MaxFilters:      3,
+		DigestIntervals: []time.Duration{24 * time.Hour},
+	},
+	ProPlan: {
+		Name:            ProPlan,
+		Price:           250,
+		Period:          30 * 24 * time.Hour,
+		MaxFilters:      10,
+		DigestIntervals: []time.Duration{time.Hour, 24 * time.Hour},
+	},
+}
+
+// Get returns the plan by name, unknown plans fall back to the free one.
+func (p Plans) Get(name string) Plan {
+	if plan, ok := p[name]; ok {
+		return plan
+	}
+	return p[FreePlan]
+}
+
+// List returns the plans ordered by price.
+func (p Plans) List() []Plan {
+	list := make([]Plan, 0, len(p))
+	for _, plan := range p {
+		list = append(list, plan)
+	}
+
+	slices.SortFunc(list, func(a, b Plan) int {
+		return cmp.Or(cmp.Compare(a.Price, b.Price), cmp.Compare(a.MaxFilters, b.MaxFilters))
+	})
+	return list
+}
+
+// AllowsDigest reports whether the user of the plan can receive digests with the interval.
+func (p Plan) AllowsDigest(interval time.Duration) bool {
+	return interval == 0 || slices.Contains(p.DigestIntervals, interval)
+}
+
+// AllowsHistoryReplay reports whether the user of the plan can request the history after the replays of the day.
+func (p Plan) AllowsHistoryReplay(replays int64) bool {
+	return p.MaxHistoryReplays == 0 || replays < p.MaxHistoryReplays
+}
@@ -0,0 +1,211 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+var (
+	historyReplayDayLayout = "2006-01-02"
+)
+
+type planConfig struct {
+	Name              string   `json:"name"`
+	Price             int64    `json:"price"`
+	Period            string   `json:"period"`
+	MaxFilters        int64    `json:"max_filters"`
+	MaxHistoryReplays int64    `json:"max_history_replays"`
+	DigestIntervals   []string `json:"digest_intervals"`
+	DeliveryDelay     string   `json:"delivery_delay"`
+}
+
+// LoadPlans reads plans from the JSON file, the default plans are returned for the empty path.
+func LoadPlans(path string) (Plans, error) {
+	if path == "" {
+		return DefaultPlans, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var configs []planConfig
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, err
+	}
+
+	plans := make(Plans, len(configs))
+	for _, cfg := range configs {
+		plan, err := cfg.plan()
+		if err != nil {
+			return nil, fmt.Errorf("plan %s: %w", cfg.Name, err)
+		}
+		plans[plan.Name] = plan
+	}
+
+	if _, ok := plans[FreePlan]; !ok {
+		return nil, errFreePlanNotFound
+	}
+	return plans, nil
+}
+
+func (c planConfig) plan() (Plan, error) {
+	p := Plan{
+		Name:              c.Name,
+		Price:             c.Price,
+		MaxFilters:        c.MaxFilters,
+		MaxHistoryReplays: c.MaxHistoryReplays,
+	}
+
+	var err error
+	if p.Period, err = parseDuration(c.Period); err != nil {
+		return p, err
+	}
+
+	if p.DeliveryDelay, err = parseDuration(c.DeliveryDelay); err != nil {
+		return p, err
+	}
+
+	for _, interval := range c.DigestIntervals {
+		d, err := parseDuration(interval)
+		if err != nil {
+			return p, err
+		}
+		p.DigestIntervals = append(p.DigestIntervals, d)
+	}
+	return p, nil
+}
+
+func parseDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// Plans returns the available plans ordered by price.
+func (s *service) Plans(_ context.Context) []Plan {
+	return s.plans.List()
+}
+
+// UserInfo returns the user with the plan.
+func (s *service) UserInfo(ctx context.Context, u User) (*User, error) {
+	user, err := s.storage.User(ctx, Filter{User: &User{ID: u.ID}})
+	if err != nil {
+		return nil, err
+	}
+
+	// the replays are counted only for the current day
+	if user.HistoryReplayDay != time.Now().Format(historyReplayDayLayout) {
+		user.HistoryReplays = 0
+	}
+	return &user, nil
+}
+
+// SetUserPlan activates the plan, the paid period of the same plan is extended.
+func (s *service) SetUserPlan(ctx context.Context, p UserPlan) (*User, error) {
+	if _, ok := s.plans[p.Plan]; !ok {
+		return nil, errPlanNotFound
+	}
+
+	user, _ := s.storage.User(ctx, Filter{User: &User{ID: p.UserID}})
+	user.ID = p.UserID
+
+	now := time.Now()
+	switch {
+	case p.Period == 0:
+		user.PlanExpiresAt = nil
+	case user.ActivePlan(now) == p.Plan && user.PlanExpiresAt != nil:
+		expiresAt := user.PlanExpiresAt.Add(p.Period)
+		user.PlanExpiresAt = &expiresAt
+	default:
+		expiresAt := now.Add(p.Period)
+		user.PlanExpiresAt = &expiresAt
+	}
+	user.Plan = p.Plan
+
+	// the digest of the previous plan may be not available
+	if !s.plans.Get(user.Plan).AllowsDigest(user.DigestInterval) {
+		user.DigestInterval = 0
+	}
+
+	if err := s.storage.InsertUser(ctx, user); err != nil {
+		return nil, err
+	}
+
+	s.setUser(user)
+	return &user, nil
+}
+
+// SetDigest changes the digest interval of the user.
+func (s *service) SetDigest(ctx context.Context, u User) (*User, error) {
+	user, err := s.storage.User(ctx, Filter{User: &User{ID: u.ID}})
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.plans.Get(user.ActivePlan(time.Now())).AllowsDigest(u.DigestInterval) {
+		return nil, errDigestNotAllowed
+	}
+
+	user.DigestInterval = u.DigestInterval
+	if err := s.storage.InsertUser(ctx, user); err != nil {
+		return nil, err
+	}
+
+	s.setUser(user)
+	return &user, nil
+}
+
+// checkFilterLimit denies creating more filters than the plan of the user allows.
+func (s *service) checkFilterLimit(ctx context.Context, f *Filter, existingFilters []Filter) error {
+	user, err := s.storage.User(ctx, *f)
+	if err != nil {
+		return err
+	}
+
+	if user.IsSuperuser {
+		return nil
+	}
+
+	if int64(len(existingFilters)) >= s.plans.Get(user.ActivePlan(time.Now())).MaxFilters {
+		return errLimitExceeded
+	}
+	return nil
+}
+
+// useHistoryReplay counts the history request of the user against the daily limit of the plan.
+func (s *service) useHistoryReplay(ctx context.Context, userID int64) error {
+	user, err := s.storage.User(ctx, Filter{User: &User{ID: userID}})
+	if err != nil {
+		return err
+	}
+
+	if user.IsBanned {
+		return errUserBanned
+	}
+
+	now := time.Now()
+	user.LastActivityAt = &now
+
+	if user.IsSuperuser {
+		return s.storage.InsertUser(ctx, user)
+	}
+
+	today := now.Format(historyReplayDayLayout)
+	if user.HistoryReplayDay != today {
+		user.HistoryReplayDay = today
+		user.HistoryReplays = 0
+	}
+
+	if !s.plans.Get(user.ActivePlan(now)).AllowsHistoryReplay(user.HistoryReplays) {
+		return errHistoryLimitExceeded
+	}
+
+	user.HistoryReplays++
+	return s.storage.InsertUser(ctx, user)
+}
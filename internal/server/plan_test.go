@@ -0,0 +1,289 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// limitedPlans limit the history and delay the delivery of the free plan
+var limitedPlans = Plans{
+	FreePlan: {
+		Name:              FreePlan,
+		MaxFilters:        1,
+		MaxHistoryReplays: 3,
+		DeliveryDelay:     15 * time.Minute,
+	},
+	PlusPlan: DefaultPlans[PlusPlan],
+	ProPlan:  DefaultPlans[ProPlan],
+}
+
+func TestActivePlan(t *testing.T) {
+	now := time.Date(2024, 9, 20, 10, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	testCases := []struct {
+		testCaseName string
+		user         User
+		expected     string
+	}{
+		{
+			testCaseName: "without plan",
+			expected:     FreePlan,
+		},
+		{
+			testCaseName: "never expires",
+			user:         User{Plan: ProPlan},
+			expected:     ProPlan,
+		},
+		{
+			testCaseName: "active",
+			user:         User{Plan: PlusPlan, PlanExpiresAt: &future},
+			expected:     PlusPlan,
+		},
+		{
+			testCaseName: "expired",
+			user:         User{Plan: PlusPlan, PlanExpiresAt: &past},
+			expected:     FreePlan,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCaseName, func(t *testing.T) {
+			require.Equal(t, tc.expected, tc.user.ActivePlan(now))
+		})
+	}
+}
+
+func TestLoadPlans(t *testing.T) {
+	plans, err := LoadPlans("")
+	require.NoError(t, err)
+	require.Equal(t, DefaultPlans, plans)
+
+	path := filepath.Join(t.TempDir(), "plans.json")
+	err = os.WriteFile(path, []byte(`[
+		{"name": "free", "max_filters": 2, "max_history_replays": 1, "delivery_delay": "30m"},
+		{"name": "pro", "price": 500, "period": "720h", "max_filters": 20, "max_history_replays": 100, "digest_intervals": ["1h", "24h"]}
+	]`), 0o600)
+	require.NoError(t, err)
+
+	plans, err = LoadPlans(path)
+	require.NoError(t, err)
+	require.Equal(t, []Plan{
+		{
+			Name:              FreePlan,
+			MaxFilters:        2,
+			MaxHistoryReplays: 1,
+			DeliveryDelay:     30 * time.Minute,
+		},
+		{
+			Name:              ProPlan,
+			Price:             500,
+			Period:            720 * time.Hour,
+			MaxFilters:        20,
+			MaxHistoryReplays: 100,
+			DigestIntervals:   []time.Duration{time.Hour, 24 * time.Hour},
+		},
+	}, plans.List())
+
+	// unknown plans fall back to the free one
+	require.Equal(t, FreePlan, plans.Get(PlusPlan).Name)
+
+	err = os.WriteFile(path, []byte(`[{"name": "pro", "price": 500}]`), 0o600)
+	require.NoError(t, err)
+
+	_, err = LoadPlans(path)
+	require.Equal(t, errFreePlanNotFound, err)
+}
+
+func TestCheckFilter(t *testing.T) {
+	ctx := context.Background()
+	f := &fakeFilter{
+		filters: map[string]Filter{
+			"first": {ID: "first", User: &User{ID: 1}},
+		},
+	}
+	st := &fakeStorage{users: map[int64]User{1: {ID: 1}}}
+	s := &service{filter: f, storage: st, plans: DefaultPlans}
+
+	// changing the existing filter isn't limited
+	_, err := s.checkFilter(ctx, &Filter{ID: "first", User: &User{ID: 1}})
+	require.NoError(t, err)
+
+	_, err = s.checkFilter(ctx, &Filter{ID: "second", User: &User{ID: 1}})
+	require.Equal(t, errLimitExceeded, err)
+
+	_, err = s.SetUserPlan(ctx, UserPlan{UserID: 1, Plan: PlusPlan, Period: time.Hour})
+	require.NoError(t, err)
+
+	_, err = s.checkFilter(ctx, &Filter{ID: "second", User: &User{ID: 1}})
+	require.NoError(t, err)
+}
+
+func TestUseHistoryReplay(t *testing.T) {
+	ctx := context.Background()
+	st := &fakeStorage{users: map[int64]User{
+		1: {ID: 1, HistoryReplays: 5, HistoryReplayDay: "2024-09-20"},
+		2: {ID: 2, IsSuperuser: true},
+	}}
+	s := &service{storage: st, plans: limitedPlans}
+
+	// the replays of the previous day are reset
+	for range limitedPlans[FreePlan].MaxHistoryReplays {
+		require.NoError(t, s.useHistoryReplay(ctx, 1))
+	}
+	require.Equal(t, errHistoryLimitExceeded, s.useHistoryReplay(ctx, 1))
+
+	user, err := s.UserInfo(ctx, User{ID: 1})
+	require.NoError(t, err)
+	require.Equal(t, limitedPlans[FreePlan].MaxHistoryReplays, user.HistoryReplays)
+
+	for range limitedPlans[FreePlan].MaxHistoryReplays + 1 {
+		require.NoError(t, s.useHistoryReplay(ctx, 2))
+	}
+
+	// the built-in free plan doesn't limit the history
+	s.plans = DefaultPlans
+	require.NoError(t, s.useHistoryReplay(ctx, 1))
+}
+
+func TestSetUserPlan(t *testing.T) {
+	ctx := context.Background()
+	st := &fakeStorage{users: map[int64]User{}}
+	s := &service{storage: st, plans: DefaultPlans}
+
+	_, err := s.SetUserPlan(ctx, UserPlan{UserID: 1, Plan: "gold"})
+	require.Equal(t, errPlanNotFound, err)
+
+	_, err = s.SetDigest(ctx, User{ID: 1, DigestInterval: time.Hour})
+	require.Equal(t, errDigestNotAllowed, err)
+
+	user, err := s.SetUserPlan(ctx, UserPlan{UserID: 1, Plan: ProPlan, Period: 24 * time.Hour})
+	require.NoError(t, err)
+	expiresAt := *user.PlanExpiresAt
+
+	// the paid period of the same plan is extended
+	user, err = s.SetUserPlan(ctx, UserPlan{UserID: 1, Plan: ProPlan, Period: 24 * time.Hour})
+	require.NoError(t, err)
+	require.Equal(t, expiresAt.Add(24*time.Hour), *user.PlanExpiresAt)
+
+	user, err = s.SetDigest(ctx, User{ID: 1, DigestInterval: time.Hour})
+	require.NoError(t, err)
+	require.Equal(t, time.Hour, user.DigestInterval)
+
+	// the hourly digest isn't available on the plus plan
+	user, err = s.SetUserPlan(ctx, UserPlan{UserID: 1, Plan: PlusPlan})
+	require.NoError(t, err)
+	require.Nil(t, user.PlanExpiresAt)
+	require.Zero(t, user.DigestInterval)
+	require.Equal(t, *user, s.cachedUser(1))
+}
+
+func TestDeliverySchedule(t *testing.T) {
+	now := time.Date(2024, 9, 20, 10, 20, 0, 0, time.UTC)
+	s := &service{
+		plans: limitedPlans,
+		users: map[int64]User{
+			2: {ID: 2, Plan: PlusPlan},
+			3: {ID: 3, Plan: ProPlan, DigestInterval: time.Hour},
+			4: {ID: 4, IsSuperuser: true},
+		},
+	}
+
+	a := Apartment{
+		ID: 100,
+		Filter: map[int64][]string{
+			1: {"free"},
+			2: {"plus"},
+			3: {"pro"},
+			4: {"superuser"},
+		},
+		Score: map[int64]Score{
+			3: {Value: 0.5},
+		},
+	}
+
+	schedule := s.deliverySchedule(a, now)
+	for _, userIDs := range schedule {
+		slices.Sort(userIDs)
+	}
+
+	require.Equal(t, map[time.Time][]int64{
+		now.Add(15 * time.Minute): {1},
+		now:                       {2, 4},
+		now.Add(40 * time.Minute): {3},
+	}, schedule)
+
+	part := apartmentForUsers(a, []int64{3})
+	require.Equal(t, map[int64][]string{3: {"pro"}}, part.Filter)
+	require.Equal(t, map[int64]Score{3: {Value: 0.5}}, part.Score)
+	require.Len(t, a.Filter, 4)
+
+	part = apartmentForUsers(a, []int64{1, 2})
+	require.Nil(t, part.Score)
+}
+
+func TestPendingDeliveries(t *testing.T) {
+	now := time.Date(2024, 9, 20, 10, 20, 0, 0, time.UTC)
+	st := &fakeStorage{
+		apartments: []Apartment{
+			{ID: 100, URL: "100"},
+			{ID: 101, URL: "101"},
+		},
+	}
+	s := &service{
+		ctx:       context.Background(),
+		plans:     limitedPlans,
+		storage:   st,
+		estimator: fakeEstimator{},
+		hidden:    make(map[int64]map[int64]struct{}),
+		users: map[int64]User{
+			2: {ID: 2, Plan: PlusPlan},
+			3: {ID: 3, Plan: ProPlan, DigestInterval: time.Hour},
+		},
+	}
+
+	apartmentCh := make(chan Apartment, 10)
+	s.subscribers.Store(int64(1), apartmentCh)
+	notificationCh := make(chan Notification, 10)
+	s.notificationSubscribers.Store(int64(1), notificationCh)
+
+	for _, a := range []Apartment{
+		{ID: 100, Filter: map[int64][]string{1: {"free"}, 3: {"pro"}}, Score: map[int64]Score{3: {Value: 0.5}}},
+		{ID: 101, Filter: map[int64][]string{3: {"pro"}}},
+		// removed before the digest is sent
+		{ID: 102, Filter: map[int64][]string{3: {"pro"}}},
+	} {
+		s.deliver(a, now)
+	}
+	require.Len(t, st.pending, 4)
+
+	// nothing is due before the digest interval ends
+	require.NoError(t, s.sendPendingDeliveries(s.ctx, now.Add(30*time.Minute)))
+	require.Len(t, st.pending, 3)
+	require.Empty(t, notificationCh)
+
+	a := <-apartmentCh
+	require.Equal(t, int64(100), a.ID)
+	require.Equal(t, map[int64][]string{1: {"free"}}, a.Filter)
+
+	require.NoError(t, s.sendPendingDeliveries(s.ctx, now.Add(40*time.Minute)))
+	require.Empty(t, st.pending)
+	require.Empty(t, apartmentCh)
+	require.Len(t, notificationCh, 1)
+
+	n := <-notificationCh
+	require.Equal(t, DigestNotification, n.Type)
+	require.Equal(t, int64(3), n.Mark.UserID)
+	require.Len(t, n.Apartments, 2)
+	require.Equal(t, int64(100), n.Apartments[0].ID)
+	require.Equal(t, map[int64][]string{3: {"pro"}}, n.Apartments[0].Filter)
+	require.Equal(t, map[int64]Score{3: {Value: 0.5}}, n.Apartments[0].Score)
+	require.Equal(t, int64(101), n.Apartments[1].ID)
+}
@@ -0,0 +1,31 @@
+package server
+
+import "time"
+
+type BreakerState int64
+
+const (
+	BreakerClosed BreakerState = iota
+	BreakerOpen
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return "closed"
+}
+
+// ProviderState is the state of the circuit breaker of the apartment provider.
+type ProviderState struct {
+	Provider string
+	State    BreakerState
+	// Since is the time of the last state change
+	Since time.Time
+	// Err is the last failure of the provider
+	Err string
+}
@@ -0,0 +1,69 @@
+package server
+
+const (
+	NewestSort ApartmentSort = iota
+	CheapestSort
+	ClosestSort
+)
+
+const (
+	// AtCursor starts from the apartment of the cursor
+	AtCursor CursorDirection = iota
+	// AfterCursor starts from the apartment next to the cursor
+	AfterCursor
+	// BeforeCursor starts from the apartment previous to the cursor and goes backwards
+	BeforeCursor
+)
+
+// ApartmentSort is the order of the found apartments.
+type ApartmentSort int64
+
+// CursorDirection is the direction of the search from the cursor.
+type CursorDirection int64
+
+// ApartmentCursor is the place of the apartment in the sorted apartments,
+// Key is the value of its sort field: the order date in unix milliseconds, the price or the distance.
+// The place is kept when the other apartments are removed.
+type ApartmentCursor struct {
+	Key         float64
+	ApartmentID int64
+	Direction   CursorDirection
+}
+
+// ApartmentSearch requests the page of the stored apartments matching the filter,
+// the page starts from the cursor instead of the offset when the cursor is set.
+type ApartmentSearch struct {
+	Filter Filter
+	Sort   ApartmentSort
+	Offset int64
+	Limit  int64
+	Cursor *ApartmentCursor
+}
+
+// ApartmentPage is the page of the search, NextOffset is 0 on the last page.
+type ApartmentPage struct {
+	Apartments []Apartment
+	NextOffset int64
+}
+
+// ApartmentBrowse requests the apartment of the filter at the cursor,
+// the browsing starts from the first apartment without the cursor.
+type ApartmentBrowse struct {
+	Filter Filter
+	Sort   ApartmentSort
+	Cursor string
+}
+
+// ApartmentBrowsePage is the single apartment of the filter with the cursors of its neighbours,
+// Apartment is nil when no apartment matches the filter.
+type ApartmentBrowsePage struct {
+	Apartment *Apartment
+	Sort      ApartmentSort
+	// Position starts from 1
+	Position int64
+	Total    int64
+
+	Cursor     string
+	PrevCursor string
+	NextCursor string
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"context"
+)
+
+const (
+	defaultSearchLimit = 20
+	// maxSearchLimit is the limit of the inline query results in Telegram
+	maxSearchLimit = 50
+)
+
+// SearchApartments returns the page of the newest apartments matching the filter.
+func (s *service) SearchApartments(ctx context.Context, q ApartmentSearch) (*ApartmentPage, error) {
+	if q.Filter.User != nil && s.cachedUser(q.Filter.User.ID).IsBanned {
+		return nil, errUserBanned
+	}
+
+	filter, err := s.withUSDPrices(q.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := q.Limit
+	if limit <= 0 || limit > maxSearchLimit {
+		limit = defaultSearchLimit
+	}
+	offset := max(q.Offset, 0)
+
+	// one more apartment shows whether the next page exists
+	apartments, err := s.storage.SearchApartments(ctx, ApartmentSearch{
+		Filter: filter,
+		Sort:   q.Sort,
+		Offset: offset,
+		Limit:  limit + 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	page := &ApartmentPage{
+		Apartments: apartments,
+	}
+	if int64(len(apartments)) > limit {
+		page.Apartments = apartments[:limit]
+		page.NextOffset = offset + limit
+	}
+	return page, nil
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchApartments(t *testing.T) {
+	apartments := make([]Apartment, 45)
+	for i := range apartments {
+		apartments[i].ID = int64(i)
+	}
+	s := &service{
+		storage: &fakeStorage{apartments: apartments},
+	}
+
+	testCases := []struct {
+		testCaseName       string
+		search             ApartmentSearch
+		expectedCount      int
+		expectedFirstID    int64
+		expectedNextOffset int64
+	}{
+		{
+			testCaseName:       "first page",
+			search:             ApartmentSearch{},
+			expectedCount:      defaultSearchLimit,
+			expectedFirstID:    0,
+			expectedNextOffset: defaultSearchLimit,
+		},
+		{
+			testCaseName:       "last page",
+			search:             ApartmentSearch{Offset: 40, Limit: 10},
+			expectedCount:      5,
+			expectedFirstID:    40,
+			expectedNextOffset: 0,
+		},
+		{
+			testCaseName:       "limit is too big",
+			search:             ApartmentSearch{Offset: 20, Limit: 100},
+			expectedCount:      defaultSearchLimit,
+			expectedFirstID:    20,
+			expectedNextOffset: 40,
+		},
+		{
+			testCaseName:       "exact page",
+			search:             ApartmentSearch{Offset: 35, Limit: 10},
+			expectedCount:      10,
+			expectedFirstID:    35,
+			expectedNextOffset: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCaseName, func(t *testing.T) {
+			page, err := s.SearchApartments(context.Background(), tc.search)
+			require.NoError(t, err)
+			require.Len(t, page.Apartments, tc.expectedCount)
+			require.Equal(t, tc.expectedFirstID, page.Apartments[0].ID)
+			require.Equal(t, tc.expectedNextOffset, page.NextOffset)
+		})
+	}
+}
+
+func TestSearchApartmentsBanned(t *testing.T) {
+	s := &service{
+		users: map[int64]User{1: {ID: 1, IsBanned: true}},
+	}
+
+	_, err := s.SearchApartments(context.Background(), ApartmentSearch{Filter: Filter{User: &User{ID: 1}}})
+	require.Equal(t, errUserBanned, err)
+}
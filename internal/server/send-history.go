@@ -4,7 +4,8 @@ import (
 	"context"
 )
 
-func (s *service) startSendHistoryData(ctx context.Context, f Filter, source <-chan Apartment) <-chan Apartment {
+// startSendHistoryData sends the stored apartments of the filter to the user.
+func (s *service) startSendHistoryData(ctx context.Context, f Filter, userID int64, source <-chan Apartment) <-chan Apartment {
 	resultCh := make(chan Apartment)
 
 	histCtx, cancel := context.WithCancel(ctx)
@@ -22,12 +23,25 @@ func (s *service) startSendHistoryData(ctx context.Context, f Filter, source <-c
 				if !ok {
 					return
 				}
-				if s.checkApartment(histCtx, a) {
-					a.Filter = map[int64][]string{
-						f.User.ID: {*f.Name},
-					}
-					resultCh <- a
+				if s.isHidden(userID, a.ID) {
+					continue
 				}
+
+				s.estimator.Estimate(&a)
+
+				a.Score = nil
+				if !s.filter.Rate(histCtx, f, &a) {
+					continue
+				}
+
+				if !s.checkApartment(histCtx, a) {
+					continue
+				}
+
+				a.Filter = map[int64][]string{
+					userID: {*f.Name},
+				}
+				resultCh <- a
 			}
 		}
 	}()
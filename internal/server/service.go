@@ -1,13 +1,18 @@
 package server
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
-	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/irbgeo/apartment-bot/internal/tracing"
 	"github.com/irbgeo/apartment-bot/internal/utils"
 )
 
@@ -22,57 +27,125 @@ type service struct {
 	apartment apartment
 	storage   storage
 	filter    filter
-
-	historySending sync.Map
-	subscribers    sync.Map
-
-	cityMutex sync.RWMutex
-	cities    map[string][]string
+	estimator estimator
+	rates     rates
+	places    places
+	plans     Plans
+
+	historySending          sync.Map
+	isRefreshing            atomic.Bool
+	subscribers             sync.Map
+	notificationSubscribers sync.Map
+
+	// hidden contains users who hid the apartment by apartment id
+	hiddenMutex sync.RWMutex
+	hidden      map[int64]map[int64]struct{}
+
+	// users caches the users by id to schedule the deliveries without a storage request
+	usersMutex sync.RWMutex
+	users      map[int64]User
+
+	// crawlScopeCh requests the update of the crawl scope after the filters are changed
+	crawlScopeCh chan struct{}
 }
 
 //go:generate mockery --name apartment --structname Apartment
 type apartment interface {
 	Watcher() <-chan Apartment
 	Apartments() (<-chan Apartment, error)
-	IsAvailable(ctx context.Context, apartment Apartment) (bool, error)
+	Check(ctx context.Context, apartment Apartment) (RemovalReason, error)
 	DeleteFromCache(a Apartment)
+	ProviderState() ProviderState
+	StateWatcher() <-chan ProviderState
+	SetCrawlScope(scope CrawlScope)
 }
 
 //go:generate mockery --name storage --structname Storage
 type storage interface {
 	SaveApartment(ctx context.Context, a Apartment) error
 	UpdateApartment(ctx context.Context, a Apartment) error
+	Apartment(ctx context.Context, id int64) (Apartment, error)
 	Apartments(ctx context.Context, f Filter) (<-chan Apartment, error)
 	ApartmentCount(ctx context.Context, f Filter) (int64, error)
-	DeleteApartment(ctx context.Context, a Apartment) error
-	DeleteApartments(ctx context.Context) error
+	ApartmentCountByHost(ctx context.Context) (map[string]int64, error)
+	SearchApartments(ctx context.Context, q ApartmentSearch) ([]Apartment, error)
+	SearchApartmentCount(ctx context.Context, q ApartmentSearch) (int64, error)
+	ApartmentSortKey(ctx context.Context, q ApartmentSearch, id int64) (float64, error)
+	ArchiveApartment(ctx context.Context, a Apartment, reason RemovalReason) error
+	ArchiveApartments(ctx context.Context, reason RemovalReason) error
 
 	InsertUser(ctx context.Context, u User) error
 	User(ctx context.Context, f Filter) (User, error)
+	Users(ctx context.Context) ([]User, error)
 	DeleteUser(ctx context.Context, u User) error
 
 	SaveCity(ctx context.Context, c City) error
 	Cities(ctx context.Context) ([]City, error)
+
+	Stats(ctx context.Context, f StatsFilter) ([]Stats, error)
+
+	SaveApartmentMark(ctx context.Context, m ApartmentMark) error
+	ApartmentMarks(ctx context.Context, f ApartmentMarkFilter) ([]ApartmentMark, error)
+
+	SaveDelivery(ctx context.Context, d Delivery) error
+	Deliveries(ctx context.Context, f DeliveryFilter) ([]Delivery, error)
+	DeleteDeliveries(ctx context.Context, f DeliveryFilter) error
+
+	SavePendingDelivery(ctx context.Context, d PendingDelivery) error
+	PendingDeliveries(ctx context.Context, f PendingDeliveryFilter) ([]PendingDelivery, error)
+	DeletePendingDeliveries(ctx context.Context, f PendingDeliveryFilter) error
 }
 
 //go:generate mockery --name filter --structname Filter
 type filter interface {
 	Add(ctx context.Context, f Filter) (*Filter, error)
 	Check(ctx context.Context, a *Apartment)
+	Rate(ctx context.Context, f Filter, a *Apartment) bool
 	Get(ctx context.Context, f Filter) (*Filter, error)
 	GetForUser(ctx context.Context, u int64) ([]Filter, error)
+	GetShared(ctx context.Context, u int64) ([]Filter, error)
+	List(ctx context.Context) ([]Filter, error)
 	Delete(ctx context.Context, f Filter) error
 }
 
+//go:generate mockery --name estimator --structname Estimator
+type estimator interface {
+	Estimate(a *Apartment)
+}
+
+//go:generate mockery --name rates --structname Rates
+type rates interface {
+	Rates() Rates
+}
+
+//go:generate mockery --name places --structname Places
+type places interface {
+	Resolve(city, district string) (Place, bool)
+	Locate(city string, c Coordinates) (Place, bool)
+	Cities() []City
+}
+
 func NewService(
 	a apartment,
 	s storage,
 	f filter,
+	e estimator,
+	r rates,
+	p places,
+	plans Plans,
 ) *service {
 	svc := &service{
 		apartment: a,
 		storage:   s,
 		filter:    f,
+		estimator: e,
+		rates:     r,
+		places:    p,
+		plans:     plans,
+		hidden:    make(map[int64]map[int64]struct{}),
+		users:     make(map[int64]User),
+
+		crawlScopeCh: make(chan struct{}, 1),
 	}
 
 	svc.ctx, svc.cancel = context.WithCancel(context.Background())
@@ -81,11 +154,22 @@ func NewService(
 }
 
 func (s *service) Start() error {
-	err := s.updatedCities()
-	if err != nil {
+	if err := s.updateHidden(); err != nil {
+		return err
+	}
+
+	if err := s.updateUsers(); err != nil {
 		return err
 	}
 
+	if err := s.updateCrawlScope(s.ctx); err != nil {
+		return err
+	}
+
+	go s.startViewingReminders()
+	go s.startPendingDeliveries()
+	go s.startCrawlScopeUpdates()
+
 	go func() {
 		err := s.checkSavedApartment(s.ctx)
 		if err != nil {
@@ -107,33 +191,35 @@ func (s *service) Start() error {
 					return
 				}
 
-				a, updated := s.saveApartment(a)
+				// the zero price would match any filter
+				if !s.convertPrice(&a) {
+					slog.Warn("skip apartment without the currency rate", "id", a.ID, "currency", a.Currency)
+					continue
+				}
+
+				a, updated := s.saveApartment(tracing.Extract(s.ctx, a.TraceContext), a)
 				if updated {
 					continue
 				}
 
-				s.filter.Check(s.ctx, &a)
+				s.estimator.Estimate(&a)
+				s.filter.Check(tracing.Extract(s.ctx, a.TraceContext), &a)
+				s.removeHidden(&a)
 				if len(a.Filter) == 0 {
 					continue
 				}
 
-				go func(a Apartment) {
-					select {
-					case <-s.ctx.Done():
-					default:
-						s.sendToSubscribers(a)
-					}
-				}(a)
+				s.deliver(a, time.Now())
+			case st := <-s.apartment.StateWatcher():
+				s.sendNotification(Notification{
+					Type:     ProviderStateNotification,
+					Provider: &st,
+				})
 			case <-checkTicker.C:
 				err := s.checkSavedApartment(s.ctx)
 				if err != nil {
 					slog.Error("check saved apartment", "err", err)
 				}
-
-				err = s.updatedCities()
-				if err != nil {
-					slog.Error("update cities", "err", err)
-				}
 			}
 		}
 	}()
@@ -141,8 +227,9 @@ func (s *service) Start() error {
 	return nil
 }
 
+// RefreshApartments archives the stored apartments and saves the apartments of the provider.
 func (s *service) RefreshApartments() error {
-	err := s.storage.DeleteApartments(s.ctx)
+	err := s.storage.ArchiveApartments(s.ctx, RefreshRemoval)
 	if err != nil {
 		return err
 	}
@@ -167,10 +254,20 @@ func (s *service) Stop() {
 		close(value.(chan Apartment)) // nolint: errcheck
 		return true
 	})
+	s.notificationSubscribers.Range(func(_, value any) bool {
+		close(value.(chan Notification)) // nolint: errcheck
+		return true
+	})
 }
 
 func (s *service) SaveFilter(ctx context.Context, f Filter) (int64, error) {
-	s.ConnectUser(ctx, User{ID: f.User.ID}) // nolint: errcheck
+	if err := s.ConnectUser(ctx, User{ID: f.User.ID}); err == errUserBanned {
+		return 0, err
+	}
+
+	if err := s.checkOwner(ctx, &f); err != nil {
+		return 0, err
+	}
 
 	filter, err := s.checkFilter(ctx, &f)
 	if err != nil {
@@ -178,12 +275,18 @@ func (s *service) SaveFilter(ctx context.Context, f Filter) (int64, error) {
 	}
 
 	s.stopSendHistoryData(*filter)
+	defer s.requestCrawlScopeUpdate()
 
 	if filter.PauseTimestamp != nil {
 		return 0, nil
 	}
 
-	count, err := s.storage.ApartmentCount(ctx, *filter)
+	usdFilter, err := s.withUSDPrices(*filter)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := s.storage.ApartmentCount(ctx, usdFilter)
 	if err != nil {
 		return 0, err
 	}
@@ -202,19 +305,14 @@ func (s *service) checkFilter(ctx context.Context, f *Filter) (*Filter, error) {
 		return nil, err
 	}
 
-	if len(existingFilters) > 0 {
-		if existingFilters[0].ID == f.ID {
+	for _, existing := range existingFilters {
+		if existing.ID == f.ID {
 			return f, nil
 		}
+	}
 
-		user, err := s.storage.User(ctx, *f)
-		if err != nil {
-			return nil, err
-		}
-
-		if !user.IsSuperuser {
-			return nil, errLimitExceeded
-		}
+	if err := s.checkFilterLimit(ctx, f, existingFilters); err != nil {
+		return nil, err
 	}
 
 	filter, err := s.filter.Add(ctx, *f)
@@ -227,6 +325,11 @@ func (s *service) checkFilter(ctx context.Context, f *Filter) (*Filter, error) {
 
 func (s *service) Filter(ctx context.Context, f Filter) (*Filter, error) {
 	filter, err := s.filter.Get(ctx, f)
+	if err != nil && f.User != nil {
+		if shared, sharedErr := s.sharedFilter(ctx, f); sharedErr == nil {
+			return shared, nil
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -235,11 +338,30 @@ func (s *service) Filter(ctx context.Context, f Filter) (*Filter, error) {
 }
 
 func (s *service) Filters(ctx context.Context, u User) ([]Filter, error) {
-	return s.filter.GetForUser(ctx, u.ID)
+	filters, err := s.filter.GetForUser(ctx, u.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	shared, err := s.filter.GetShared(ctx, u.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(filters, shared...), nil
 }
 
+// DeleteFilter deletes the filter of the owner and unsubscribes other users.
 func (s *service) DeleteFilter(ctx context.Context, f Filter) error {
+	if len(f.ID) != 0 && f.User != nil {
+		existing, err := s.filter.Get(ctx, Filter{ID: f.ID})
+		if err == nil && !existing.OwnedBy(f.User.ID) {
+			return s.unsubscribe(ctx, *existing, f.User.ID)
+		}
+	}
+
 	s.stopSendHistoryData(f)
+	defer s.requestCrawlScopeUpdate()
 
 	return s.filter.Delete(ctx, f)
 }
@@ -249,13 +371,44 @@ func (s *service) ConnectUser(ctx context.Context, u User) error {
 	user, _ := s.storage.User(ctx, f)
 	user.ID = u.ID
 
+	if user.IsBanned {
+		return errUserBanned
+	}
+
+	now := time.Now()
+	user.LastActivityAt = &now
+	user.IsBlocked = false
+
 	utils.UnpackVar(ctx, utils.IDKey, &user.ClientID) // nolint: errcheck
-	return s.storage.InsertUser(ctx, user)
+	if err := s.storage.InsertUser(ctx, user); err != nil {
+		return err
+	}
+
+	s.setUser(user)
+	return nil
 }
 
 func (s *service) DisconnectUser(ctx context.Context, u User) error {
 	f := Filter{User: &User{ID: u.ID}}
 
+	if err := s.deleteUserFilters(ctx, u.ID); err != nil {
+		return err
+	}
+
+	// the paid plan and the ban are kept till the user comes back
+	if user, err := s.storage.User(ctx, f); err == nil && (user.IsBanned || user.ActivePlan(time.Now()) != FreePlan) {
+		user.IsBlocked = true
+		return s.storage.InsertUser(ctx, user)
+	}
+
+	s.deleteUser(u.ID)
+	return s.storage.DeleteUser(ctx, u)
+}
+
+// deleteUserFilters deletes the filters of the user and unsubscribes the user from the shared filters.
+func (s *service) deleteUserFilters(ctx context.Context, userID int64) error {
+	f := Filter{User: &User{ID: userID}}
+
 	s.stopSendHistoryData(f)
 
 	err := s.filter.Delete(ctx, f)
@@ -263,27 +416,79 @@ func (s *service) DisconnectUser(ctx context.Context, u User) error {
 		return err
 	}
 
-	return s.storage.DeleteUser(ctx, u)
+	shared, err := s.filter.GetShared(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, sf := range shared {
+		if err := s.unsubscribe(ctx, sf, userID); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
+// Cities returns the cities of the gazetteer and the unknown cities and districts of the providers.
 func (s *service) Cities(ctx context.Context) ([]City, error) {
-	return s.storage.Cities(ctx)
+	stored, err := s.storage.Cities(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cities := s.places.Cities()
+	index := make(map[string]int, len(cities))
+	for i, city := range cities {
+		index[city.Name] = i
+	}
+
+	for _, city := range stored {
+		p, _ := s.places.Resolve(city.Name, "")
+
+		i, ok := index[p.City]
+		if !ok {
+			i = len(cities)
+			index[p.City] = i
+			cities = append(cities, City{Name: p.City, District: make(map[string]struct{})})
+		}
+
+		for district := range city.District {
+			if _, ok := s.places.Resolve(city.Name, district); !ok {
+				cities[i].District[district] = struct{}{}
+			}
+		}
+	}
+	return cities, nil
 }
 
 func (s *service) Apartments(ctx context.Context, f Filter) (<-chan Apartment, error) {
 	s.stopSendHistoryData(f)
 
-	filter, err := s.filter.Get(ctx, f)
+	filter, err := s.Filter(ctx, f)
 	if err != nil {
 		return nil, err
 	}
 
-	apartmentCh, err := s.storage.Apartments(ctx, *filter) // nolint: errcheck
+	userID := filter.User.ID
+	if f.User != nil {
+		userID = f.User.ID
+	}
+
+	usdFilter, err := s.withUSDPrices(*filter)
 	if err != nil {
 		return nil, err
 	}
 
-	resultCh := s.startSendHistoryData(ctx, *filter, apartmentCh)
+	if err := s.useHistoryReplay(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	apartmentCh, err := s.storage.Apartments(ctx, usdFilter) // nolint: errcheck
+	if err != nil {
+		return nil, err
+	}
+
+	resultCh := s.startSendHistoryData(ctx, *filter, userID, apartmentCh)
 	return resultCh, nil
 }
 
@@ -294,16 +499,26 @@ func (s *service) checkSavedApartment(ctx context.Context) error {
 	}
 
 	for apartment := range apartmentCh {
-		s.checkApartment(s.ctx, apartment)
+		if !s.checkApartment(s.ctx, apartment) {
+			continue
+		}
+
+		// the apartments saved without the district are located by the coordinates
+		if apartment.District == "" && s.locate(&apartment) {
+			if err := s.storage.UpdateApartment(ctx, apartment); err != nil {
+				slog.Error("update apartment district", "err", err)
+			}
+		}
 	}
 	return nil
 }
 
 // saveApartment returns true if apartment was updated
-func (s *service) saveApartment(a Apartment) (Apartment, bool) {
-	var ok bool
-	a.District, ok = s.district(a)
+func (s *service) saveApartment(ctx context.Context, a Apartment) (Apartment, bool) {
+	ctx, span := tracing.Start(ctx, "server.saveApartment", attribute.Int64("apartment.id", a.ID))
+	defer span.End()
 
+	ok := s.resolvePlace(&a)
 	if !ok {
 		city := City{
 			Name: a.City,
@@ -312,68 +527,81 @@ func (s *service) saveApartment(a Apartment) (Apartment, bool) {
 			city.District = map[string]struct{}{a.District: {}}
 		}
 
-		err := s.storage.SaveCity(s.ctx, city)
+		err := s.storage.SaveCity(ctx, city)
 		if err != nil {
 			slog.Error("save city", "err", err)
 		}
 	}
 
-	err := s.storage.SaveApartment(s.ctx, a)
+	err := s.storage.SaveApartment(ctx, a)
 	if err != nil {
-		_ = s.storage.UpdateApartment(s.ctx, a) // nolint: errcheck
+		_ = s.storage.UpdateApartment(ctx, a) // nolint: errcheck
+		span.SetAttributes(attribute.Bool("apartment.updated", true))
 		return a, true
 	}
 
+	a.TraceContext = tracing.Inject(ctx)
 	return a, false
 }
 
-func (s *service) district(a Apartment) (string, bool) {
-	districts, ok := s.cities[a.City]
-	if ok {
-		for _, district := range districts {
-			if strings.Contains(a.District, district) {
-				return district, true
-			}
-		}
+// resolvePlace sets the canonical city and district of the apartment,
+// the apartment without the district is located by the coordinates.
+// false is returned when the city or the district is unknown.
+func (s *service) resolvePlace(a *Apartment) bool {
+	p, ok := s.places.Resolve(a.City, cmp.Or(a.Subdistrict, a.District))
+	a.City, a.District, a.Subdistrict = p.City, p.District, p.Subdistrict
+
+	if a.District == "" {
+		return s.locate(a) || ok
 	}
-	return a.District, false
+	return ok
 }
 
-func (s *service) checkApartment(ctx context.Context, a Apartment) bool {
-	isActive, err := s.apartment.IsAvailable(ctx, a)
-	if err != nil && err != context.Canceled {
-		slog.Error("check_apartment", "err", err)
-		return true
+// locate sets the district of the apartment by the coordinates in the polygons of its city,
+// the city is set only for the apartment without it. False is returned when the place is unknown.
+func (s *service) locate(a *Apartment) bool {
+	if a.Coordinates == nil {
+		return false
 	}
 
-	if isActive {
-		return true
+	p, ok := s.places.Locate(a.City, *a.Coordinates)
+	if !ok {
+		return false
 	}
 
-	s.apartment.DeleteFromCache(a)
-	err = s.storage.DeleteApartment(ctx, a)
-	if err != nil {
-		slog.Error("delete_apartment", "err", err)
+	if a.City == "" {
+		a.City = p.City
 	}
-	slog.Info("delete_apartment", "url", a.URL, "data", a.OrderDate)
-	return false
+	a.District, a.Subdistrict = p.District, p.Subdistrict
+	return true
 }
 
-func (s *service) updatedCities() error {
-	cities, err := s.storage.Cities(s.ctx)
+// checkApartment archives the removed apartment, the apartments aren't archived
+// while the provider is unavailable, the failed check keeps the apartment.
+func (s *service) checkApartment(ctx context.Context, a Apartment) bool {
+	if s.apartment.ProviderState().State != BreakerClosed {
+		return true
+	}
+
+	reason, err := s.apartment.Check(ctx, a)
 	if err != nil {
-		return err
+		if !errors.Is(err, context.Canceled) {
+			slog.Error("check_apartment", "err", err)
+		}
+		return true
 	}
 
-	s.cityMutex.Lock()
-	defer s.cityMutex.Unlock()
+	if reason == NotRemoved {
+		return true
+	}
 
-	s.cities = make(map[string][]string, len(cities))
-	for _, city := range cities {
-		s.cities[city.Name] = make([]string, 0, len(city.District))
-		for district := range city.District {
-			s.cities[city.Name] = append(s.cities[city.Name], district)
-		}
+	s.apartment.DeleteFromCache(a)
+	err = s.storage.ArchiveApartment(ctx, a, reason)
+	if err != nil {
+		slog.Error("archive_apartment", "err", err)
 	}
-	return nil
+	slog.Info("archive_apartment", "url", a.URL, "data", a.OrderDate, "reason", reason)
+
+	s.notifyRemoved(ctx, a)
+	return false
 }
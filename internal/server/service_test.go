@@ -0,0 +1,132 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckApartment(t *testing.T) {
+	testCases := []struct {
+		testCaseName     string
+		apartment        *fakeApartment
+		expectedActive   bool
+		expectedChecked  int
+		expectedArchived map[int64]RemovalReason
+	}{
+		{
+			testCaseName:    "available",
+			apartment:       &fakeApartment{},
+			expectedActive:  true,
+			expectedChecked: 1,
+		},
+		{
+			testCaseName:     "inactive is archived",
+			apartment:        &fakeApartment{removal: InactiveRemoval},
+			expectedActive:   false,
+			expectedChecked:  1,
+			expectedArchived: map[int64]RemovalReason{1: InactiveRemoval},
+		},
+		{
+			testCaseName:     "not found is archived",
+			apartment:        &fakeApartment{removal: NotFoundRemoval},
+			expectedActive:   false,
+			expectedChecked:  1,
+			expectedArchived: map[int64]RemovalReason{1: NotFoundRemoval},
+		},
+		{
+			testCaseName:    "failed check keeps apartment",
+			apartment:       &fakeApartment{removal: InactiveRemoval, err: errors.New("status code: 502")},
+			expectedActive:  true,
+			expectedChecked: 1,
+		},
+		{
+			testCaseName:   "open breaker keeps apartment",
+			apartment:      &fakeApartment{removal: InactiveRemoval, state: BreakerOpen},
+			expectedActive: true,
+		},
+		{
+			testCaseName:   "half-open breaker keeps apartment",
+			apartment:      &fakeApartment{removal: InactiveRemoval, state: BreakerHalfOpen},
+			expectedActive: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testCaseName, func(t *testing.T) {
+			storage := &fakeStorage{archived: make(map[int64]RemovalReason)}
+			s := &service{apartment: tc.apartment, storage: storage}
+
+			isActive := s.checkApartment(context.Background(), Apartment{ID: 1})
+
+			require.Equal(t, tc.expectedActive, isActive)
+			require.Equal(t, tc.expectedChecked, tc.apartment.checked)
+			if tc.expectedArchived == nil {
+				require.Empty(t, storage.archived)
+				return
+			}
+			require.Equal(t, tc.expectedArchived, storage.archived)
+		})
+	}
+}
+
+func TestSaveApartmentPlace(t *testing.T) {
+	st := &fakeStorage{}
+	s := &service{storage: st, places: fakePlaces{}}
+
+	a, updated := s.saveApartment(context.Background(), Apartment{ID: 1, City: "თბილისი", District: "ვერა"})
+	require.False(t, updated)
+	require.Equal(t, "Tbilisi", a.City)
+	require.Equal(t, "Mtatsminda", a.District)
+	require.Equal(t, "Vera", a.Subdistrict)
+	require.Empty(t, st.savedCities)
+
+	a, _ = s.saveApartment(context.Background(), Apartment{ID: 2, City: "Tbilisi", District: "Lilo"})
+	require.Equal(t, "Lilo", a.District)
+	require.Equal(t, []City{{Name: "Tbilisi", District: map[string]struct{}{"Lilo": {}}}}, st.savedCities)
+
+	// the apartment without the district is located by the coordinates
+	a, _ = s.saveApartment(context.Background(), Apartment{
+		ID:          3,
+		City:        "Tbilisi",
+		Coordinates: &Coordinates{Lat: 41.705, Lng: 44.78},
+	})
+	require.Equal(t, "Mtatsminda", a.District)
+	require.Equal(t, "Vera", a.Subdistrict)
+	require.Len(t, st.savedCities, 1)
+
+	a, _ = s.saveApartment(context.Background(), Apartment{
+		ID:          4,
+		City:        "Tbilisi",
+		Coordinates: &Coordinates{Lat: 41.60, Lng: 44.78},
+	})
+	require.Empty(t, a.District)
+
+	// the polygons of the other city don't move the apartment
+	a, _ = s.saveApartment(context.Background(), Apartment{
+		ID:          5,
+		City:        "Batumi",
+		Coordinates: &Coordinates{Lat: 41.705, Lng: 44.78},
+	})
+	require.Equal(t, "Batumi", a.City)
+	require.Empty(t, a.District)
+}
+
+func TestCities(t *testing.T) {
+	s := &service{
+		storage: &fakeStorage{cities: []City{
+			{Name: "თბილისი", District: map[string]struct{}{"ვერა": {}, "Lilo": {}}},
+			{Name: "Zugdidi"},
+		}},
+		places: fakePlaces{},
+	}
+
+	cities, err := s.Cities(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []City{
+		{Name: "Tbilisi", District: map[string]struct{}{"Mtatsminda": {}, "Vera": {}, "Lilo": {}}},
+		{Name: "Zugdidi", District: map[string]struct{}{}},
+	}, cities)
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ShareFilter returns the token of the filter share link, the token is created once.
+func (s *service) ShareFilter(ctx context.Context, f Filter) (string, error) {
+	filter, err := s.filter.Get(ctx, Filter{ID: f.ID})
+	if err != nil {
+		return "", err
+	}
+
+	if !filter.OwnedBy(f.User.ID) {
+		return "", errPermissionDenied
+	}
+
+	if filter.ShareToken != nil {
+		return *filter.ShareToken, nil
+	}
+
+	token := strings.ReplaceAll(uuid.New().String(), "-", "")
+	filter.ShareToken = &token
+
+	if _, err := s.filter.Add(ctx, *filter); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// JoinFilter subscribes the user to the shared filter or copies it to the user.
+func (s *service) JoinFilter(ctx context.Context, j FilterJoin) (*Filter, error) {
+	shared, err := s.filter.Get(ctx, Filter{ShareToken: &j.Token})
+	if err != nil {
+		return nil, errSharedFilterNotFound
+	}
+
+	if shared.OwnedBy(j.User.ID) {
+		return shared, nil
+	}
+
+	if err := s.ConnectUser(ctx, j.User); err == errUserBanned {
+		return nil, err
+	}
+	defer s.requestCrawlScopeUpdate()
+
+	if j.Clone {
+		clone := *shared
+		clone.ID = ""
+		clone.User = &User{ID: j.User.ID}
+		clone.ShareToken = nil
+		clone.Subscribers = nil
+		clone.PauseTimestamp = nil
+		clone.FromTimestamp = nil
+
+		return s.checkFilter(ctx, &clone)
+	}
+
+	if slices.Contains(shared.Subscribers, j.User.ID) {
+		return shared, nil
+	}
+
+	shared.Subscribers = append(shared.Subscribers, j.User.ID)
+	return s.filter.Add(ctx, *shared)
+}
+
+// checkOwner denies changing the filter by subscribers and keeps the sharing settings of the filter.
+func (s *service) checkOwner(ctx context.Context, f *Filter) error {
+	if len(f.ID) == 0 {
+		return nil
+	}
+
+	existing, err := s.filter.Get(ctx, Filter{ID: f.ID})
+	if err != nil {
+		return err
+	}
+
+	if !existing.OwnedBy(f.User.ID) {
+		return errPermissionDenied
+	}
+
+	f.ShareToken = existing.ShareToken
+	f.Subscribers = existing.Subscribers
+	return nil
+}
+
+// sharedFilter returns the filter the user is subscribed to.
+func (s *service) sharedFilter(ctx context.Context, f Filter) (*Filter, error) {
+	shared := f
+	shared.User = nil
+	shared.SubscriberID = &f.User.ID
+
+	return s.filter.Get(ctx, shared)
+}
+
+func (s *service) unsubscribe(ctx context.Context, f Filter, userID int64) error {
+	f.Subscribers = slices.DeleteFunc(slices.Clone(f.Subscribers), func(id int64) bool {
+		return id == userID
+	})
+
+	_, err := s.filter.Add(ctx, f)
+	return err
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJoinFilter(t *testing.T) {
+	token := "token"
+	name := "flatmates"
+	f := &fakeFilter{
+		filters: map[string]Filter{
+			"shared": {
+				ID:         "shared",
+				User:       &User{ID: 1},
+				Name:       &name,
+				ShareToken: &token,
+			},
+		},
+	}
+	s := &service{filter: f, storage: &fakeStorage{}, plans: DefaultPlans}
+	ctx := context.Background()
+
+	joined, err := s.JoinFilter(ctx, FilterJoin{User: User{ID: 2}, Token: token})
+	require.NoError(t, err)
+	require.Equal(t, []int64{2}, joined.Subscribers)
+	shared := f.filters["shared"]
+	require.Equal(t, []int64{1, 2}, shared.Recipients())
+
+	// joining twice doesn't duplicate the subscriber
+	joined, err = s.JoinFilter(ctx, FilterJoin{User: User{ID: 2}, Token: token})
+	require.NoError(t, err)
+	require.Equal(t, []int64{2}, joined.Subscribers)
+
+	clone, err := s.JoinFilter(ctx, FilterJoin{User: User{ID: 3}, Token: token, Clone: true})
+	require.NoError(t, err)
+	require.Equal(t, "clone", clone.ID)
+	require.True(t, clone.OwnedBy(3))
+	require.Nil(t, clone.ShareToken)
+	require.Empty(t, clone.Subscribers)
+
+	_, err = s.JoinFilter(ctx, FilterJoin{User: User{ID: 2}, Token: "unknown"})
+	require.Equal(t, errSharedFilterNotFound, err)
+}
+
+func TestCheckOwner(t *testing.T) {
+	token := "token"
+	f := &fakeFilter{
+		filters: map[string]Filter{
+			"shared": {
+				ID:          "shared",
+				User:        &User{ID: 1},
+				ShareToken:  &token,
+				Subscribers: []int64{2},
+			},
+		},
+	}
+	s := &service{filter: f}
+	ctx := context.Background()
+
+	err := s.checkOwner(ctx, &Filter{ID: "shared", User: &User{ID: 2}})
+	require.Equal(t, errPermissionDenied, err)
+
+	update := &Filter{ID: "shared", User: &User{ID: 1}}
+	require.NoError(t, s.checkOwner(ctx, update))
+	require.Equal(t, &token, update.ShareToken)
+	require.Equal(t, []int64{2}, update.Subscribers)
+
+	// the filter that can't be loaded isn't overwritten
+	err = s.checkOwner(ctx, &Filter{ID: "unknown", User: &User{ID: 1}})
+	require.Equal(t, errSharedFilterNotFound, err)
+}
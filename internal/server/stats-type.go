@@ -0,0 +1,42 @@
+package server
+
+import (
+	"strconv"
+	"time"
+)
+
+// Stats is a daily snapshot of the listings of a market segment, including the ones removed during the day.
+// Empty District and zero Rooms mean the segment includes all districts and room counts.
+type Stats struct {
+	Date     time.Time
+	AdType   int64
+	City     string
+	District string
+	Rooms    int64
+
+	Count               int64
+	P25Price            float64
+	MedianPrice         float64
+	P75Price            float64
+	MedianPricePerMeter float64
+	MedianDaysOnMarket  float64
+}
+
+// Key identifies the market segment of the snapshot regardless of the date.
+func (s Stats) Key() string {
+	return strconv.FormatInt(s.AdType, 10) + ":" + s.City + ":" + s.District + ":" + strconv.FormatInt(s.Rooms, 10)
+}
+
+type StatsFilter struct {
+	Date     *time.Time
+	AdType   *int64
+	City     *string
+	District *string
+	Rooms    *int64
+}
+
+// StatsReport contains the latest snapshots and the snapshots of the week before for trends.
+type StatsReport struct {
+	Current []Stats
+	WeekAgo []Stats
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"context"
+	"time"
+)
+
+var (
+	statsTrendPeriod = 7 * 24 * time.Hour
+)
+
+// Stats returns the latest market snapshot and the snapshot of the week before.
+func (s *service) Stats(ctx context.Context, f StatsFilter) (*StatsReport, error) {
+	date := time.Now().UTC().Truncate(24 * time.Hour)
+
+	current, err := s.statsForDate(ctx, f, date)
+	if err != nil {
+		return nil, err
+	}
+
+	// today's snapshot may not be calculated yet
+	if len(current) == 0 {
+		date = date.Add(-24 * time.Hour)
+		if current, err = s.statsForDate(ctx, f, date); err != nil {
+			return nil, err
+		}
+	}
+
+	weekAgo, err := s.statsForDate(ctx, f, date.Add(-statsTrendPeriod))
+	if err != nil {
+		return nil, err
+	}
+
+	return &StatsReport{
+		Current: current,
+		WeekAgo: weekAgo,
+	}, nil
+}
+
+func (s *service) statsForDate(ctx context.Context, f StatsFilter, date time.Time) ([]Stats, error) {
+	f.Date = &date
+	return s.storage.Stats(ctx, f)
+}
@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"log/slog"
+	"strconv"
 
 	"github.com/irbgeo/apartment-bot/internal/utils"
 )
@@ -47,3 +48,25 @@ func (s *service) sendToSubscribers(a Apartment) {
 		},
 	)
 }
+
+// Health reports whether at least one client is subscribed.
+func (s *service) Health(_ context.Context) error {
+	if s.subscriberCount() == 0 {
+		return errNoSubscribers
+	}
+	return nil
+}
+
+// HealthReport reports the number of the subscribed clients.
+func (s *service) HealthReport() string {
+	return strconv.Itoa(s.subscriberCount())
+}
+
+func (s *service) subscriberCount() int {
+	var count int
+	s.subscribers.Range(func(_, _ any) bool {
+		count++
+		return true
+	})
+	return count
+}
@@ -1,12 +1,45 @@
 package server
 
+import "time"
+
 type User struct {
 	ID          int64
 	ClientID    int64
 	IsSuperuser bool
+	// IsBanned denies the user to use the bot
+	IsBanned bool
+	// IsBlocked is set when the user blocked the bot
+	IsBlocked      bool
+	LastActivityAt *time.Time
+
+	Plan          string
+	PlanExpiresAt *time.Time
+	// DigestInterval collects apartments of the user into digests, zero delivers them at once
+	DigestInterval time.Duration
+	// Currency is the currency of the messages and the prices of the new filters
+	Currency Currency
+
+	// HistoryReplays is the count of history requests on HistoryReplayDay
+	HistoryReplays   int64
+	HistoryReplayDay string
+}
+
+// ActivePlan returns the plan of the user, an expired plan falls back to the free one.
+func (u User) ActivePlan(now time.Time) string {
+	if u.Plan == "" || (u.PlanExpiresAt != nil && !u.PlanExpiresAt.After(now)) {
+		return FreePlan
+	}
+	return u.Plan
 }
 
 type City struct {
 	Name     string
 	District map[string]struct{}
 }
+
+// Place is the canonical city, district and subdistrict of the gazetteer.
+type Place struct {
+	City        string
+	District    string
+	Subdistrict string
+}
@@ -20,6 +20,11 @@ func distance(lat1, lon1, lat2, lon2 float64) float64 {
 	return distance
 }
 
+// Distance returns the distance in meters between two points.
+func (c Coordinates) Distance(to Coordinates) float64 {
+	return distance(c.Lat, c.Lng, to.Lat, to.Lng)
+}
+
 func toRadians(degrees float64) float64 {
 	return degrees * math.Pi / 180
 }
@@ -0,0 +1,122 @@
+package stats
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+var (
+	// maxRooms groups apartments with more rooms into one segment
+	maxRooms int64 = 4
+)
+
+type calculator struct {
+	segments map[string]*segment
+}
+
+type segment struct {
+	stats        server.Stats
+	prices       []float64
+	pricePerArea []float64
+	daysOnMarket []float64
+}
+
+func newCalculator() *calculator {
+	return &calculator{
+		segments: make(map[string]*segment),
+	}
+}
+
+// add puts the apartment into the segments of its city and district, both for its room count and for all rooms.
+func (c *calculator) add(a server.Apartment, now time.Time) {
+	if a.City == "" || a.Price <= 0 {
+		return
+	}
+
+	rooms := min(int64(a.Rooms), maxRooms)
+
+	districts := []string{""}
+	if a.District != "" {
+		districts = append(districts, a.District)
+	}
+
+	for _, district := range districts {
+		for _, r := range []int64{0, rooms} {
+			s := server.Stats{
+				AdType:   a.AdType,
+				City:     a.City,
+				District: district,
+				Rooms:    r,
+			}
+			c.segment(s).add(a, now)
+
+			if rooms == 0 {
+				break
+			}
+		}
+	}
+}
+
+func (c *calculator) segment(s server.Stats) *segment {
+	key := s.Key()
+
+	seg, ok := c.segments[key]
+	if !ok {
+		seg = &segment{stats: s}
+		c.segments[key] = seg
+	}
+	return seg
+}
+
+func (c *calculator) stats(date time.Time) []server.Stats {
+	result := make([]server.Stats, 0, len(c.segments))
+	for _, seg := range c.segments {
+		s := seg.stats
+		s.Date = date
+		s.Count = int64(len(seg.prices))
+		s.P25Price = percentile(seg.prices, 25)
+		s.MedianPrice = percentile(seg.prices, 50)
+		s.P75Price = percentile(seg.prices, 75)
+		s.MedianPricePerMeter = percentile(seg.pricePerArea, 50)
+		s.MedianDaysOnMarket = percentile(seg.daysOnMarket, 50)
+
+		result = append(result, s)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Key() < result[j].Key()
+	})
+	return result
+}
+
+func (s *segment) add(a server.Apartment, now time.Time) {
+	s.prices = append(s.prices, a.Price)
+
+	if a.Area > 0 {
+		s.pricePerArea = append(s.pricePerArea, a.Price/a.Area)
+	}
+
+	if !a.OrderDate.IsZero() {
+		s.daysOnMarket = append(s.daysOnMarket, math.Max(0, now.Sub(a.OrderDate).Hours()/24))
+	}
+}
+
+// percentile returns the p-th percentile using linear interpolation between closest ranks.
+func percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
@@ -0,0 +1,58 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+func TestCalculator(t *testing.T) {
+	now := time.Date(2024, 9, 10, 12, 0, 0, 0, time.UTC)
+	date := now.Truncate(24 * time.Hour)
+
+	apartments := []server.Apartment{
+		{AdType: server.RentAdType, City: "Tbilisi", District: "Vake", Rooms: 2, Price: 1000, Area: 50, OrderDate: now.Add(-48 * time.Hour)},
+		{AdType: server.RentAdType, City: "Tbilisi", District: "Vake", Rooms: 2, Price: 1400, Area: 70, OrderDate: now.Add(-24 * time.Hour)},
+		{AdType: server.RentAdType, City: "Tbilisi", District: "Saburtalo", Rooms: 5, Price: 600, Area: 60},
+		{AdType: server.RentAdType, City: "", Price: 700},
+		{AdType: server.SaleAdType, City: "Batumi", Rooms: 0, Price: 50000, Area: 40},
+	}
+
+	c := newCalculator()
+	for _, a := range apartments {
+		c.add(a, now)
+	}
+
+	expected := []server.Stats{
+		{Date: date, AdType: server.RentAdType, City: "Tbilisi", Rooms: 0, Count: 3, P25Price: 800, MedianPrice: 1000, P75Price: 1200, MedianPricePerMeter: 20, MedianDaysOnMarket: 1.5},
+		{Date: date, AdType: server.RentAdType, City: "Tbilisi", Rooms: 2, Count: 2, P25Price: 1100, MedianPrice: 1200, P75Price: 1300, MedianPricePerMeter: 20, MedianDaysOnMarket: 1.5},
+		{Date: date, AdType: server.RentAdType, City: "Tbilisi", Rooms: 4, Count: 1, P25Price: 600, MedianPrice: 600, P75Price: 600, MedianPricePerMeter: 10},
+		{Date: date, AdType: server.RentAdType, City: "Tbilisi", District: "Saburtalo", Rooms: 0, Count: 1, P25Price: 600, MedianPrice: 600, P75Price: 600, MedianPricePerMeter: 10},
+		{Date: date, AdType: server.RentAdType, City: "Tbilisi", District: "Saburtalo", Rooms: 4, Count: 1, P25Price: 600, MedianPrice: 600, P75Price: 600, MedianPricePerMeter: 10},
+		{Date: date, AdType: server.RentAdType, City: "Tbilisi", District: "Vake", Rooms: 0, Count: 2, P25Price: 1100, MedianPrice: 1200, P75Price: 1300, MedianPricePerMeter: 20, MedianDaysOnMarket: 1.5},
+		{Date: date, AdType: server.RentAdType, City: "Tbilisi", District: "Vake", Rooms: 2, Count: 2, P25Price: 1100, MedianPrice: 1200, P75Price: 1300, MedianPricePerMeter: 20, MedianDaysOnMarket: 1.5},
+		{Date: date, AdType: server.SaleAdType, City: "Batumi", Rooms: 0, Count: 1, P25Price: 50000, MedianPrice: 50000, P75Price: 50000, MedianPricePerMeter: 1250},
+	}
+
+	require.Equal(t, expected, c.stats(date))
+}
+
+func TestPercentile(t *testing.T) {
+	testCases := []struct {
+		values   []float64
+		p        float64
+		expected float64
+	}{
+		{values: nil, p: 50, expected: 0},
+		{values: []float64{5}, p: 25, expected: 5},
+		{values: []float64{4, 1, 3, 2}, p: 50, expected: 2.5},
+		{values: []float64{1, 2, 3, 4, 5}, p: 75, expected: 4},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.expected, percentile(tc.values, tc.p))
+	}
+}
@@ -0,0 +1,95 @@
+package stats
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+type service struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	storage storage
+}
+
+type storage interface {
+	Apartments(ctx context.Context, f server.Filter) (<-chan server.Apartment, error)
+	ArchivedApartments(ctx context.Context, f server.ArchiveFilter) (<-chan server.ArchivedApartment, error)
+	SaveStats(ctx context.Context, s []server.Stats) error
+}
+
+// NewService creates a service calculating daily market snapshots of stored listings
+func NewService(s storage) *service {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &service{
+		ctx:     ctx,
+		cancel:  cancel,
+		storage: s,
+	}
+}
+
+func (s *service) Start(updateInterval time.Duration) error {
+	if err := s.update(); err != nil {
+		return err
+	}
+
+	go func() {
+		ticker := time.NewTicker(updateInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.update(); err != nil {
+					slog.Error("update stats", "err", err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (s *service) Stop() {
+	s.cancel()
+}
+
+// update recalculates the snapshot of the current day, the apartments taken off the market
+// during the day are counted with their full days on market.
+func (s *service) update() error {
+	apartmentCh, err := s.storage.Apartments(s.ctx, server.Filter{})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	date := now.Truncate(24 * time.Hour)
+
+	c := newCalculator()
+	for a := range apartmentCh {
+		c.add(a, now)
+	}
+
+	archivedCh, err := s.storage.ArchivedApartments(s.ctx, server.ArchiveFilter{
+		RemovedFrom: &date,
+		Reasons:     []server.RemovalReason{server.InactiveRemoval, server.NotFoundRemoval},
+	})
+	if err != nil {
+		return err
+	}
+
+	for a := range archivedCh {
+		c.add(a.Apartment, a.RemovedAt)
+	}
+
+	stats := c.stats(date)
+	slog.Info("stats", "segments", len(stats))
+
+	return s.storage.SaveStats(s.ctx, stats)
+}
@@ -17,12 +17,16 @@ func toMongoApartment(in server.Apartment) apartment {
 		Phone:          in.Phone,
 		City:           in.City,
 		District:       in.District,
+		Subdistrict:    in.Subdistrict,
 		Comment:        in.Comment,
 		IsOwner:        in.IsOwner,
 		OrderDate:      in.OrderDate,
 
 		URL:       in.URL,
 		PhotoURLs: in.PhotoURLs,
+
+		OriginalPrice: in.OriginalPrice,
+		Currency:      string(in.Currency),
 	}
 
 	if in.Coordinates != nil {
@@ -47,6 +51,7 @@ func toApartment(in apartment) server.Apartment {
 		Floor:          in.Floor,
 		Phone:          in.Phone,
 		District:       in.District,
+		Subdistrict:    in.Subdistrict,
 		City:           in.City,
 		Comment:        in.Comment,
 		IsOwner:        in.IsOwner,
@@ -54,6 +59,9 @@ func toApartment(in apartment) server.Apartment {
 
 		PhotoURLs: in.PhotoURLs,
 		URL:       in.URL,
+
+		OriginalPrice: in.OriginalPrice,
+		Currency:      server.Currency(in.Currency),
 	}
 
 	if in.Coordinates != nil {
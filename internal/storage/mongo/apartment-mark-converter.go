@@ -0,0 +1,72 @@
+package mongo
+
+import (
+	"strconv"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+func toMongoApartmentMark(in server.ApartmentMark) apartmentMark {
+	out := apartmentMark{
+		ID:          apartmentMarkID(in.UserID, in.ApartmentID),
+		UserID:      in.UserID,
+		ApartmentID: in.ApartmentID,
+		IsSaved:     in.IsSaved,
+		IsHidden:    in.IsHidden,
+		Note:        in.Note,
+		Status:      int64(in.Status),
+		ViewingAt:   in.ViewingAt,
+		IsReminded:  in.IsReminded,
+		UpdatedAt:   in.UpdatedAt,
+	}
+
+	if in.Apartment != nil {
+		a := toMongoApartment(*in.Apartment)
+		out.Apartment = &a
+	}
+
+	return out
+}
+
+func toApartmentMark(in apartmentMark) server.ApartmentMark {
+	out := server.ApartmentMark{
+		UserID:      in.UserID,
+		ApartmentID: in.ApartmentID,
+		IsSaved:     in.IsSaved,
+		IsHidden:    in.IsHidden,
+		Note:        in.Note,
+		Status:      server.PipelineStatus(in.Status),
+		ViewingAt:   in.ViewingAt,
+		IsReminded:  in.IsReminded,
+		UpdatedAt:   in.UpdatedAt,
+	}
+
+	if in.Apartment != nil {
+		a := toApartment(*in.Apartment)
+		out.Apartment = &a
+	}
+
+	return out
+}
+
+func toMongoApartmentMarkFilter(in server.ApartmentMarkFilter) filter {
+	out := filter{
+		UserID:      in.UserID,
+		ApartmentID: in.ApartmentID,
+		IsSaved:     in.IsSaved,
+		IsHidden:    in.IsHidden,
+		ViewingFrom: in.ViewingFrom,
+		ViewingTill: in.ViewingTill,
+		IsReminded:  in.IsReminded,
+	}
+
+	for _, st := range in.Statuses {
+		out.Statuses = append(out.Statuses, int64(st))
+	}
+
+	return out
+}
+
+func apartmentMarkID(userID, apartmentID int64) string {
+	return strconv.FormatInt(userID, 10) + ":" + strconv.FormatInt(apartmentID, 10)
+}
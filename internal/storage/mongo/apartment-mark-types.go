@@ -0,0 +1,17 @@
+package mongo
+
+import "time"
+
+type apartmentMark struct {
+	ID          string     `bson:"_id"`
+	UserID      int64      `bson:"user_id"`
+	ApartmentID int64      `bson:"apartment_id"`
+	IsSaved     bool       `bson:"is_saved"`
+	IsHidden    bool       `bson:"is_hidden"`
+	Note        string     `bson:"note"`
+	Status      int64      `bson:"status"`
+	ViewingAt   *time.Time `bson:"viewing_at"`
+	IsReminded  bool       `bson:"is_reminded"`
+	UpdatedAt   time.Time  `bson:"updated_at"`
+	Apartment   *apartment `bson:"apartment"`
+}
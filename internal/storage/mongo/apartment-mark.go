@@ -0,0 +1,31 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+var apartmentMarkCollection = "apartment_mark"
+
+func (s *mongoDB) SaveApartmentMark(ctx context.Context, m server.ApartmentMark) error {
+	doc := toMongoApartmentMark(m)
+	f := filter{
+		ID: doc.ID,
+	}
+	return s.upsert(ctx, apartmentMarkCollection, f, doc)
+}
+
+func (s *mongoDB) ApartmentMarks(ctx context.Context, f server.ApartmentMarkFilter) ([]server.ApartmentMark, error) {
+	resultCh, err := find[apartmentMark](ctx, s, apartmentMarkCollection, toMongoApartmentMarkFilter(f))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]server.ApartmentMark, 0)
+	for m := range resultCh {
+		result = append(result, toApartmentMark(m))
+	}
+
+	return result, nil
+}
@@ -13,12 +13,16 @@ type apartment struct {
 	Floor          int64     `bson:"floor"`
 	Phone          string    `bson:"phone"`
 	District       string    `bson:"district"`
+	Subdistrict    string    `bson:"subdistrict"`
 	City           string    `bson:"city"`
 	Coordinates    *location `bson:"location"`
 	Comment        string    `bson:"comment"`
 	IsOwner        bool      `bson:"is_owner"`
 	OrderDate      time.Time `bson:"order_date"`
 
+	OriginalPrice float64 `bson:"original_price"`
+	Currency      string  `bson:"currency"`
+
 	URL       string   `bson:"url"`
 	PhotoURLs []string `bson:"photo_urls"`
 
@@ -2,9 +2,11 @@ package mongo // nolint: dupl
 
 import (
 	"context"
+	"errors"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/irbgeo/apartment-bot/internal/server"
 )
@@ -13,6 +15,12 @@ var (
 	apartmentCollection = "apartment"
 )
 
+// earthRadius converts the distance in meters to radians.
+const earthRadius = 6378100.0
+
+// distanceField is the distance of the apartment in meters set by $geoNear.
+const distanceField = "distance"
+
 func (s *mongoDB) apartmentCollectionSetting() error {
 	_, err := s.db.Collection(apartmentCollection).Indexes().CreateOne(
 		context.Background(),
@@ -61,17 +69,193 @@ func (s *mongoDB) Apartments(ctx context.Context, f server.Filter) (<-chan serve
 	return apartmentCh, nil
 }
 
-func (s *mongoDB) ApartmentCount(ctx context.Context, f server.Filter) (int64, error) {
-	return s.count(ctx, apartmentCollection, toMongoFilter(f))
+// SearchApartments returns the page of the sorted apartments matching the filter.
+func (s *mongoDB) SearchApartments(ctx context.Context, q server.ApartmentSearch) ([]server.Apartment, error) {
+	f, order := toSearchFilter(q)
+
+	var (
+		cur *mongo.Cursor
+		err error
+	)
+	switch {
+	case q.Sort == server.ClosestSort && f.Coordinates != nil:
+		pipeline := mongo.Pipeline{f.geoNear()}
+		if f.Cursor != nil {
+			pipeline = append(pipeline, bson.D{{Key: "$match", Value: f.Cursor.filter()}})
+		}
+		pipeline = append(pipeline,
+			bson.D{{Key: "$sort", Value: order}},
+			bson.D{{Key: "$skip", Value: q.Offset}},
+			bson.D{{Key: "$limit", Value: q.Limit}},
+		)
+		cur, err = s.db.Collection(apartmentCollection).Aggregate(ctx, pipeline)
+	default:
+		opts := options.Find().
+			SetSort(order).
+			SetSkip(q.Offset).
+			SetLimit(q.Limit)
+		cur, err = s.db.Collection(apartmentCollection).Find(ctx, f.forCollection(apartmentCollection), opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var result []apartment
+	if err := cur.All(ctx, &result); err != nil {
+		return nil, err
+	}
+
+	apartments := make([]server.Apartment, 0, len(result))
+	for _, a := range result {
+		apartments = append(apartments, toApartment(a))
+	}
+	return apartments, nil
 }
 
-func (s *mongoDB) DeleteApartment(ctx context.Context, a server.Apartment) error {
-	f := server.Filter{
-		ApartmentID: &a.ID,
+// SearchApartmentCount counts the apartments of the search from the cursor without the offset and the limit.
+func (s *mongoDB) SearchApartmentCount(ctx context.Context, q server.ApartmentSearch) (int64, error) {
+	f, _ := toSearchFilter(q)
+	if q.Sort != server.ClosestSort || f.Coordinates == nil {
+		return s.count(ctx, apartmentCollection, f)
+	}
+
+	pipeline := mongo.Pipeline{f.geoNear()}
+	if f.Cursor != nil {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: f.Cursor.filter()}})
+	}
+	pipeline = append(pipeline, bson.D{{Key: "$count", Value: "count"}})
+
+	cur, err := s.db.Collection(apartmentCollection).Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, err
+	}
+
+	var result []struct {
+		Count int64 `bson:"count"`
+	}
+	if err := cur.All(ctx, &result); err != nil || len(result) == 0 {
+		return 0, err
+	}
+	return result[0].Count, nil
+}
+
+// ApartmentSortKey returns the value of the sort field of the stored apartment,
+// the distance is measured from the coordinates of the filter the same way as the search does.
+func (s *mongoDB) ApartmentSortKey(ctx context.Context, q server.ApartmentSearch, id int64) (float64, error) {
+	f := toMongoFilter(q.Filter)
+	if q.Sort != server.ClosestSort || f.Coordinates == nil {
+		a, err := s.Apartment(ctx, id)
+		if err != nil {
+			return 0, err
+		}
+		if q.Sort == server.CheapestSort {
+			return a.Price, nil
+		}
+		return float64(a.OrderDate.UnixMilli()), nil
+	}
+
+	near := filter{ApartmentID: &id, Coordinates: f.Coordinates}
+	cur, err := s.db.Collection(apartmentCollection).Aggregate(ctx, mongo.Pipeline{near.geoNear()})
+	if err != nil {
+		return 0, err
+	}
+
+	var result []struct {
+		Distance float64 `bson:"distance"`
+	}
+	if err := cur.All(ctx, &result); err != nil {
+		return 0, err
+	}
+	if len(result) == 0 {
+		return 0, errNotFound
+	}
+	return result[0].Distance, nil
+}
+
+// toSearchFilter returns the filter of the search from the cursor and the sort of the found apartments.
+func toSearchFilter(q server.ApartmentSearch) (filter, bson.D) {
+	f := toMongoFilter(q.Filter)
+	f.WithinDistance = true
+
+	sort := q.Sort
+	if sort == server.ClosestSort && f.Coordinates == nil {
+		// the distance isn't known without the coordinates
+		sort = server.NewestSort
+	}
+
+	field, ascending := sortField(sort)
+	c := &cursor{Field: field, Ascending: ascending}
+	if q.Cursor != nil {
+		c = toMongoCursor(sort, *q.Cursor)
+		f.Cursor = c
 	}
-	return s.delete(ctx, apartmentCollection, toMongoFilter(f))
+	return f, c.order()
 }
 
-func (s *mongoDB) DeleteApartments(ctx context.Context) error {
-	return s.deleteAll(ctx, apartmentCollection)
+// sortField returns the field of the sort and whether the apartments go in the ascending order of it.
+func sortField(sort server.ApartmentSort) (string, bool) {
+	switch sort {
+	case server.CheapestSort:
+		return "price", true
+	case server.ClosestSort:
+		return distanceField, true
+	}
+	return "order_date", false
+}
+
+// Apartment returns the stored apartment by id.
+func (s *mongoDB) Apartment(ctx context.Context, id int64) (server.Apartment, error) {
+	f := filter{ApartmentID: &id}
+
+	var a apartment
+	err := s.db.Collection(apartmentCollection).FindOne(ctx, f.forCollection(apartmentCollection)).Decode(&a)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return server.Apartment{}, errNotFound
+	}
+	if err != nil {
+		return server.Apartment{}, err
+	}
+	return toApartment(a), nil
+}
+
+func (s *mongoDB) ApartmentCount(ctx context.Context, f server.Filter) (int64, error) {
+	filter := toMongoFilter(f)
+	filter.WithinDistance = true
+	return s.count(ctx, apartmentCollection, filter)
+}
+
+// ApartmentCountByHost counts the apartments by the host of the url,
+// the apartments without the url are counted by the empty host.
+func (s *mongoDB) ApartmentCountByHost(ctx context.Context) (map[string]int64, error) {
+	// https://home.ss.ge/... is split into "https:", "", "home.ss.ge", ...
+	host := bson.D{{Key: "$arrayElemAt", Value: bson.A{
+		bson.D{{Key: "$split", Value: bson.A{bson.D{{Key: "$ifNull", Value: bson.A{"$url", ""}}}, "/"}}},
+		2,
+	}}}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{{Key: "$ifNull", Value: bson.A{host, ""}}}},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+	}
+
+	cur, err := s.db.Collection(apartmentCollection).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	var hosts []struct {
+		Host  string `bson:"_id"`
+		Count int64  `bson:"count"`
+	}
+	if err := cur.All(ctx, &hosts); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]int64, len(hosts))
+	for _, h := range hosts {
+		result[h.Host] += h.Count
+	}
+	return result, nil
 }
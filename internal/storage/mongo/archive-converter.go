@@ -0,0 +1,24 @@
+package mongo
+
+import (
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+func toArchivedApartment(in archivedApartment) server.ArchivedApartment {
+	return server.ArchivedApartment{
+		Apartment: toApartment(in.Apartment),
+		RemovedAt: in.RemovedAt,
+		Reason:    server.RemovalReason(in.Reason),
+	}
+}
+
+func toMongoArchiveFilter(in server.ArchiveFilter) filter {
+	out := filter{
+		RemovedFrom: in.RemovedFrom,
+	}
+
+	for _, r := range in.Reasons {
+		out.Reasons = append(out.Reasons, int64(r))
+	}
+	return out
+}
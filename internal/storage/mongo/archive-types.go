@@ -0,0 +1,16 @@
+package mongo
+
+import "time"
+
+// archivedApartment is the apartment document with the time and the reason of the removal,
+// it's stored with its own _id and the id of the apartment in apartment_id.
+type archivedApartment struct {
+	Apartment apartment `bson:",inline"`
+	RemovedAt time.Time `bson:"removed_at"`
+	Reason    int64     `bson:"reason"`
+}
+
+// apartmentID is the projection of the apartment to the id.
+type apartmentID struct {
+	ID int64 `bson:"_id"`
+}
@@ -0,0 +1,186 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+var (
+	archiveCollection = "apartment_archive"
+	archiveTTLIndex   = "removed_at_ttl"
+
+	// archiveBatchSize limits the ids of the apartments archived by one request
+	archiveBatchSize = 1000
+)
+
+const (
+	namespaceNotFoundCode    = 26
+	indexNotFoundCode        = 27
+	indexOptionsConflictCode = 85
+)
+
+// archiveCollectionSetting expires the archived apartments after the retention,
+// the zero retention keeps them forever.
+func (s *mongoDB) archiveCollectionSetting(retention time.Duration) error {
+	ctx := context.Background()
+	indexes := s.db.Collection(archiveCollection).Indexes()
+
+	if retention <= 0 {
+		_, err := indexes.DropOne(ctx, archiveTTLIndex)
+		if hasErrorCode(err, namespaceNotFoundCode, indexNotFoundCode) {
+			return nil
+		}
+		return err
+	}
+
+	expireAfter := int32(retention.Seconds())
+	_, err := indexes.CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "removed_at", Value: 1}},
+		Options: options.Index().SetName(archiveTTLIndex).SetExpireAfterSeconds(expireAfter),
+	})
+	if !hasErrorCode(err, indexOptionsConflictCode) {
+		return err
+	}
+
+	// the retention is changed
+	return s.db.RunCommand(ctx, bson.D{
+		{Key: "collMod", Value: archiveCollection},
+		{Key: "index", Value: bson.D{
+			{Key: "name", Value: archiveTTLIndex},
+			{Key: "expireAfterSeconds", Value: expireAfter},
+		}},
+	}).Err()
+}
+
+// ArchiveApartment moves the apartment to the archive.
+func (s *mongoDB) ArchiveApartment(ctx context.Context, a server.Apartment, reason server.RemovalReason) error {
+	return s.archive(ctx, filter{ApartmentID: &a.ID}, reason)
+}
+
+// ArchiveApartments moves all apartments to the archive.
+func (s *mongoDB) ArchiveApartments(ctx context.Context, reason server.RemovalReason) error {
+	return s.archive(ctx, filter{}, reason)
+}
+
+// ArchivedApartments returns the removals of the apartments, the apartment removed again has the removal each time.
+func (s *mongoDB) ArchivedApartments(ctx context.Context, f server.ArchiveFilter) (<-chan server.ArchivedApartment, error) {
+	af := toMongoArchiveFilter(f)
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: af.forCollection(archiveCollection)}},
+		// the archive has its own ids, the apartment is read with its id,
+		// the apartments archived before keep it in _id
+		{{Key: "$set", Value: bson.D{
+			{Key: "_id", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$apartment_id", "$_id"}}}},
+		}}},
+	}
+
+	cur, err := s.db.Collection(archiveCollection).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	apartmentCh := make(chan server.ArchivedApartment)
+	go func() {
+		defer close(apartmentCh)
+		defer cur.Close(ctx)
+
+		for cur.Next(ctx) {
+			var a archivedApartment
+			if err := cur.Decode(&a); err != nil {
+				slog.Error("decode", "collection name", archiveCollection, "err", err)
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case apartmentCh <- toArchivedApartment(a):
+			}
+		}
+	}()
+
+	return apartmentCh, nil
+}
+
+// archive copies the apartments to the archive as is and deletes them,
+// the archive keeps the id of the apartment in apartment_id, so the apartment archived again
+// is added next to its previous removal. The ids are collected first,
+// so the apartments inserted while archiving aren't deleted.
+func (s *mongoDB) archive(ctx context.Context, f filter, reason server.RemovalReason) error {
+	ids, err := s.apartmentIDs(ctx, f)
+	if err != nil {
+		return err
+	}
+
+	for start := 0; start < len(ids); start += archiveBatchSize {
+		batch := filter{ApartmentIDs: ids[start:min(start+archiveBatchSize, len(ids))]}
+		if err := s.archiveBatch(ctx, batch, reason); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *mongoDB) archiveBatch(ctx context.Context, f filter, reason server.RemovalReason) error {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: f.forCollection(apartmentCollection)}},
+		{{Key: "$addFields", Value: bson.D{
+			{Key: "apartment_id", Value: "$_id"},
+			{Key: "removed_at", Value: time.Now()},
+			{Key: "reason", Value: int64(reason)},
+		}}},
+		// $merge generates the new _id of the archived apartment
+		{{Key: "$unset", Value: "_id"}},
+		{{Key: "$merge", Value: bson.D{
+			{Key: "into", Value: archiveCollection},
+			{Key: "whenMatched", Value: "fail"},
+			{Key: "whenNotMatched", Value: "insert"},
+		}}},
+	}
+
+	cur, err := s.db.Collection(apartmentCollection).Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	if err := cur.Close(ctx); err != nil {
+		return err
+	}
+
+	return s.deleteMany(ctx, apartmentCollection, f)
+}
+
+// apartmentIDs returns the ids of the apartments matched by the filter.
+func (s *mongoDB) apartmentIDs(ctx context.Context, f filter) ([]int64, error) {
+	resultCh, err := find[apartmentID](ctx, s, apartmentCollection, f, options.Find().SetProjection(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, 0)
+	for a := range resultCh {
+		ids = append(ids, a.ID)
+	}
+	return ids, nil
+}
+
+func hasErrorCode(err error, codes ...int) bool {
+	var se mongo.ServerError
+	if !errors.As(err, &se) {
+		return false
+	}
+
+	for _, code := range codes {
+		if se.HasErrorCode(code) {
+			return true
+		}
+	}
+	return false
+}
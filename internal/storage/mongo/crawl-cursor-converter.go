@@ -0,0 +1,23 @@
+package mongo
+
+import (
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+func toMongoCrawlCursor(in server.CrawlCursor) crawlCursor {
+	return crawlCursor{
+		Provider:     in.Provider,
+		OrderDate:    in.OrderDate,
+		ApartmentID:  in.ApartmentID,
+		BackfilledAt: in.BackfilledAt,
+	}
+}
+
+func toCrawlCursor(in crawlCursor) server.CrawlCursor {
+	return server.CrawlCursor{
+		Provider:     in.Provider,
+		OrderDate:    in.OrderDate,
+		ApartmentID:  in.ApartmentID,
+		BackfilledAt: in.BackfilledAt,
+	}
+}
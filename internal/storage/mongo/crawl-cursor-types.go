@@ -0,0 +1,10 @@
+package mongo
+
+import "time"
+
+type crawlCursor struct {
+	Provider     string    `bson:"_id"`
+	OrderDate    time.Time `bson:"order_date"`
+	ApartmentID  int64     `bson:"apartment_id"`
+	BackfilledAt time.Time `bson:"backfilled_at"`
+}
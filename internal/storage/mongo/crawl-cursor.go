@@ -0,0 +1,33 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+var crawlCursorCollection = "crawl_cursor"
+
+func (s *mongoDB) SaveCrawlCursor(ctx context.Context, c server.CrawlCursor) error {
+	f := filter{
+		ID: c.Provider,
+	}
+	return s.upsert(ctx, crawlCursorCollection, f, toMongoCrawlCursor(c))
+}
+
+// CrawlCursor returns the cursor of the provider, the empty cursor is returned before the first crawl.
+func (s *mongoDB) CrawlCursor(ctx context.Context, provider string) (server.CrawlCursor, error) {
+	resultCh, err := find[crawlCursor](ctx, s, crawlCursorCollection, filter{ID: provider})
+	if err != nil {
+		return server.CrawlCursor{}, err
+	}
+
+	result := server.CrawlCursor{
+		Provider: provider,
+	}
+	for c := range resultCh {
+		result = toCrawlCursor(c)
+	}
+
+	return result, nil
+}
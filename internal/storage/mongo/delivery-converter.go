@@ -0,0 +1,31 @@
+package mongo
+
+import (
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+func toMongoDelivery(in server.Delivery) delivery {
+	return delivery{
+		ID:          apartmentMarkID(in.UserID, in.ApartmentID),
+		UserID:      in.UserID,
+		ApartmentID: in.ApartmentID,
+		MessageID:   in.MessageID,
+		DeliveredAt: in.DeliveredAt,
+	}
+}
+
+func toDelivery(in delivery) server.Delivery {
+	return server.Delivery{
+		UserID:      in.UserID,
+		ApartmentID: in.ApartmentID,
+		MessageID:   in.MessageID,
+		DeliveredAt: in.DeliveredAt,
+	}
+}
+
+func toMongoDeliveryFilter(in server.DeliveryFilter) filter {
+	return filter{
+		UserID:      in.UserID,
+		ApartmentID: in.ApartmentID,
+	}
+}
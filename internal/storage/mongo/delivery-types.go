@@ -0,0 +1,11 @@
+package mongo
+
+import "time"
+
+type delivery struct {
+	ID          string    `bson:"_id"`
+	UserID      int64     `bson:"user_id"`
+	ApartmentID int64     `bson:"apartment_id"`
+	MessageID   int64     `bson:"message_id"`
+	DeliveredAt time.Time `bson:"delivered_at"`
+}
@@ -0,0 +1,35 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+var deliveryCollection = "delivery"
+
+func (s *mongoDB) SaveDelivery(ctx context.Context, d server.Delivery) error {
+	doc := toMongoDelivery(d)
+	f := filter{
+		ID: doc.ID,
+	}
+	return s.upsert(ctx, deliveryCollection, f, doc)
+}
+
+func (s *mongoDB) Deliveries(ctx context.Context, f server.DeliveryFilter) ([]server.Delivery, error) {
+	resultCh, err := find[delivery](ctx, s, deliveryCollection, toMongoDeliveryFilter(f))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]server.Delivery, 0)
+	for d := range resultCh {
+		result = append(result, toDelivery(d))
+	}
+
+	return result, nil
+}
+
+func (s *mongoDB) DeleteDeliveries(ctx context.Context, f server.DeliveryFilter) error {
+	return s.deleteMany(ctx, deliveryCollection, toMongoDeliveryFilter(f))
+}
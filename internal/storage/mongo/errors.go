@@ -1,7 +1,7 @@
 package mongo
 
-import "errors"
+import "github.com/irbgeo/apartment-bot/internal/server"
 
 var (
-	errNotFound = errors.New("not found")
+	errNotFound = server.ErrNotFound
 )
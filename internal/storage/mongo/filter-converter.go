@@ -1,6 +1,8 @@
 package mongo
 
 import (
+	"time"
+
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
@@ -24,8 +26,18 @@ func toMongoFilter(in server.Filter) filter {
 		MaxArea:        in.MaxArea,
 		IsOwner:        in.IsOwner,
 		MaxDistance:    in.MaxDistance,
+		MinScore:       in.MinScore,
+		Currency:       string(in.Currency),
+		ShareToken:     in.ShareToken,
+		Subscribers:    in.Subscribers,
+		SubscriberID:   in.SubscriberID,
 		FromTimestamp:  in.FromTimestamp,
 
+		USDMinPrice: in.USDMinPrice,
+		USDMaxPrice: in.USDMaxPrice,
+
+		ExcludedApartmentIDs: in.ExcludedApartmentIDs,
+
 		PauseTimestamp: in.PauseTimestamp,
 	}
 
@@ -58,6 +70,10 @@ func toFilter(in filter) server.Filter {
 		MaxArea:        in.MaxArea,
 		IsOwner:        in.IsOwner,
 		MaxDistance:    in.MaxDistance,
+		MinScore:       in.MinScore,
+		Currency:       server.Currency(in.Currency),
+		ShareToken:     in.ShareToken,
+		Subscribers:    in.Subscribers,
 
 		PauseTimestamp: in.PauseTimestamp,
 	}
@@ -78,6 +94,120 @@ func toFilter(in filter) server.Filter {
 	return out
 }
 
+// price compares the apartments in the currency of the filter by the original price
+// and the other apartments by the price in USD, the same way as the filter of the new apartments.
+func (s *filter) price() bson.D {
+	original := priceRange(s.MinPrice, s.MaxPrice)
+	if len(original) == 0 {
+		return nil
+	}
+
+	currencies := bson.D{{Key: "$in", Value: []string{s.Currency}}}
+	usd := priceRange(s.USDMinPrice, s.USDMaxPrice)
+	if s.Currency == "" || s.Currency == string(server.USD) {
+		currencies = bson.D{{Key: "$in", Value: []string{"", string(server.USD)}}}
+		usd = original
+	}
+
+	inCurrency := bson.D{
+		{Key: "currency", Value: currencies},
+		{Key: "original_price", Value: bson.D{{Key: "$gt", Value: 0}}},
+	}
+	byOriginalPrice := bson.D{
+		{Key: "currency", Value: currencies},
+		{Key: "original_price", Value: append(bson.D{{Key: "$gt", Value: 0}}, original...)},
+	}
+	byPrice := bson.D{{Key: "$nor", Value: bson.A{inCurrency}}}
+	if len(usd) != 0 {
+		byPrice = append(byPrice, bson.E{Key: "price", Value: usd})
+	}
+
+	return bson.D{{Key: "$or", Value: bson.A{byOriginalPrice, byPrice}}}
+}
+
+// toMongoCursor returns the cursor of the sort field, the previous apartments go in the reverse order.
+func toMongoCursor(sort server.ApartmentSort, in server.ApartmentCursor) *cursor {
+	field, ascending := sortField(sort)
+	out := &cursor{
+		Field:     field,
+		Key:       in.Key,
+		ID:        in.ApartmentID,
+		Ascending: ascending,
+		Inclusive: in.Direction == server.AtCursor,
+	}
+
+	if field == "order_date" {
+		out.Key = time.UnixMilli(int64(in.Key)).UTC()
+	}
+
+	if in.Direction == server.BeforeCursor {
+		out.Ascending = !ascending
+	}
+	return out
+}
+
+func (c *cursor) filter() bson.D {
+	compare := "$lt"
+	if c.Ascending {
+		compare = "$gt"
+	}
+
+	compareID := compare
+	if c.Inclusive {
+		compareID += "e"
+	}
+
+	return bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: c.Field, Value: bson.D{{Key: compare, Value: c.Key}}}},
+		bson.D{
+			{Key: c.Field, Value: c.Key},
+			{Key: "_id", Value: bson.D{{Key: compareID, Value: c.ID}}},
+		},
+	}}}
+}
+
+// order returns the sort of the apartments from the cursor.
+func (c *cursor) order() bson.D {
+	order := -1
+	if c.Ascending {
+		order = 1
+	}
+	return bson.D{{Key: c.Field, Value: order}, {Key: "_id", Value: order}}
+}
+
+// geoNear sorts the apartments by the distance from the coordinates of the filter,
+// the distance in meters is set to the distance field.
+func (s *filter) geoNear() bson.D {
+	query := *s
+	query.Coordinates, query.Cursor = nil, nil
+
+	geoNear := bson.D{
+		{Key: "near", Value: bson.D{
+			{Key: "type", Value: "Point"},
+			{Key: "coordinates", Value: primitive.A{s.Coordinates.Lng, s.Coordinates.Lat}},
+		}},
+		{Key: "distanceField", Value: distanceField},
+		{Key: "spherical", Value: true},
+		{Key: "query", Value: query.apartment()},
+	}
+	if s.MaxDistance != nil {
+		geoNear = append(geoNear, bson.E{Key: "maxDistance", Value: *s.MaxDistance})
+	}
+	return bson.D{{Key: "$geoNear", Value: geoNear}}
+}
+
+func priceRange(minPrice, maxPrice *float64) bson.D {
+	result := bson.D{}
+	if minPrice != nil {
+		result = append(result, bson.E{Key: "$gte", Value: *minPrice})
+	}
+
+	if maxPrice != nil {
+		result = append(result, bson.E{Key: "$lte", Value: *maxPrice})
+	}
+	return result
+}
+
 func (s *filter) forCollection(collectionName string) any {
 	switch collectionName {
 	case userCollection:
@@ -86,6 +216,18 @@ func (s *filter) forCollection(collectionName string) any {
 		return s.filter()
 	case cityCollection:
 		return s.city()
+	case statsCollection:
+		return s.stats()
+	case apartmentMarkCollection:
+		return s.apartmentMark()
+	case deliveryCollection:
+		return s.delivery()
+	case pendingDeliveryCollection:
+		return s.pendingDelivery()
+	case crawlCursorCollection:
+		return s.crawlCursor()
+	case archiveCollection:
+		return s.archive()
 	}
 	return s.apartment()
 }
@@ -99,6 +241,20 @@ func (s *filter) apartment() any {
 		})
 	}
 
+	if len(s.ExcludedApartmentIDs) != 0 {
+		filter = append(filter, bson.E{
+			Key:   "_id",
+			Value: bson.D{{Key: "$nin", Value: s.ExcludedApartmentIDs}},
+		})
+	}
+
+	if len(s.ApartmentIDs) != 0 {
+		filter = append(filter, bson.E{
+			Key:   "_id",
+			Value: bson.D{{Key: "$in", Value: s.ApartmentIDs}},
+		})
+	}
+
 	if s.AdType != nil {
 		filter = append(filter, bson.E{
 			Key:   "ad_type",
@@ -118,11 +274,15 @@ func (s *filter) apartment() any {
 		for d := range s.District {
 			districts = append(districts, d)
 		}
-		districts = append(districts, "")
 
+		// the filter district is the district or the subdistrict of the apartment,
+		// the apartments without the district aren't located for sure and are kept
 		filter = append(filter, bson.E{
-			Key:   "district",
-			Value: bson.D{{Key: "$in", Value: districts}},
+			Key: "$or",
+			Value: bson.A{
+				bson.D{{Key: "district", Value: bson.D{{Key: "$in", Value: append(districts, "")}}}},
+				bson.D{{Key: "subdistrict", Value: bson.D{{Key: "$in", Value: districts}}}},
+			},
 		})
 	}
 
@@ -138,17 +298,17 @@ func (s *filter) apartment() any {
 		})
 	}
 
-	price := bson.D{}
-	if s.MinPrice != nil {
-		price = append(price, bson.E{Key: "$gte", Value: *s.MinPrice})
+	and := bson.A{}
+	if price := s.price(); price != nil {
+		and = append(and, price)
 	}
 
-	if s.MaxPrice != nil {
-		price = append(price, bson.E{Key: "$lte", Value: *s.MaxPrice})
+	if s.Cursor != nil {
+		and = append(and, s.Cursor.filter())
 	}
 
-	if len(price) != 0 {
-		filter = append(filter, bson.E{Key: "price", Value: price})
+	if len(and) != 0 {
+		filter = append(filter, bson.E{Key: "$and", Value: and})
 	}
 
 	rooms := bson.D{}
@@ -181,7 +341,21 @@ func (s *filter) apartment() any {
 		filter = append(filter, bson.E{Key: "is_owner", Value: *s.IsOwner})
 	}
 
-	if s.Coordinates != nil && s.MaxDistance != nil {
+	if s.Coordinates != nil && s.MaxDistance != nil && s.WithinDistance {
+		filter = append(filter, bson.E{
+			Key: "location",
+			Value: bson.D{
+				{Key: "$geoWithin", Value: bson.D{
+					{Key: "$centerSphere", Value: primitive.A{
+						primitive.A{s.Coordinates.Lng, s.Coordinates.Lat},
+						*s.MaxDistance / earthRadius,
+					}},
+				}},
+			},
+		})
+	}
+
+	if s.Coordinates != nil && s.MaxDistance != nil && !s.WithinDistance {
 		filter = append(
 			filter,
 			bson.D{
@@ -225,13 +399,23 @@ func (s *filter) filter() any {
 		filter = append(filter, bson.E{Key: "name", Value: *s.Name})
 	}
 
+	if s.ShareToken != nil {
+		filter = append(filter, bson.E{Key: "share_token", Value: *s.ShareToken})
+	}
+
+	if s.SubscriberID != nil {
+		filter = append(filter, bson.E{Key: "subscribers", Value: *s.SubscriberID})
+	}
+
 	return filter
 }
 
 func (s *filter) user() any {
 	filter := bson.D{}
 
-	filter = append(filter, bson.E{Key: "tg_id", Value: s.UserID})
+	if s.UserID != nil {
+		filter = append(filter, bson.E{Key: "tg_id", Value: *s.UserID})
+	}
 
 	return filter
 }
@@ -245,3 +429,158 @@ func (s *filter) city() any {
 
 	return filter
 }
+
+func (s *filter) stats() any {
+	filter := bson.D{}
+
+	if len(s.ID) != 0 {
+		filter = append(filter, bson.E{Key: "_id", Value: s.ID})
+	}
+
+	if s.Date != nil {
+		filter = append(filter, bson.E{Key: "date", Value: *s.Date})
+	}
+
+	if s.AdType != nil {
+		filter = append(filter, bson.E{Key: "ad_type", Value: *s.AdType})
+	}
+
+	if s.CityName != nil {
+		filter = append(filter, bson.E{Key: "city", Value: *s.CityName})
+	}
+
+	if len(s.District) != 0 {
+		districts := make([]string, 0, len(s.District))
+		for d := range s.District {
+			districts = append(districts, d)
+		}
+
+		filter = append(filter, bson.E{
+			Key:   "district",
+			Value: bson.D{{Key: "$in", Value: districts}},
+		})
+	}
+
+	if s.MinRooms != nil {
+		filter = append(filter, bson.E{Key: "rooms", Value: int64(*s.MinRooms)})
+	}
+
+	return filter
+}
+
+func (s *filter) apartmentMark() any {
+	filter := bson.D{}
+
+	if len(s.ID) != 0 {
+		filter = append(filter, bson.E{Key: "_id", Value: s.ID})
+	}
+
+	if s.UserID != nil {
+		filter = append(filter, bson.E{Key: "user_id", Value: *s.UserID})
+	}
+
+	if s.ApartmentID != nil {
+		filter = append(filter, bson.E{Key: "apartment_id", Value: *s.ApartmentID})
+	}
+
+	if s.IsSaved != nil {
+		filter = append(filter, bson.E{Key: "is_saved", Value: *s.IsSaved})
+	}
+
+	if s.IsHidden != nil {
+		filter = append(filter, bson.E{Key: "is_hidden", Value: *s.IsHidden})
+	}
+
+	if len(s.Statuses) != 0 {
+		filter = append(filter, bson.E{
+			Key:   "status",
+			Value: bson.D{{Key: "$in", Value: s.Statuses}},
+		})
+	}
+
+	viewing := bson.D{}
+	if s.ViewingFrom != nil {
+		viewing = append(viewing, bson.E{Key: "$gt", Value: *s.ViewingFrom})
+	}
+
+	if s.ViewingTill != nil {
+		viewing = append(viewing, bson.E{Key: "$lte", Value: *s.ViewingTill})
+	}
+
+	if len(viewing) != 0 {
+		filter = append(filter, bson.E{Key: "viewing_at", Value: viewing})
+	}
+
+	if s.IsReminded != nil {
+		filter = append(filter, bson.E{Key: "is_reminded", Value: *s.IsReminded})
+	}
+
+	return filter
+}
+
+func (s *filter) delivery() any {
+	filter := bson.D{}
+
+	if len(s.ID) != 0 {
+		filter = append(filter, bson.E{Key: "_id", Value: s.ID})
+	}
+
+	if s.UserID != nil {
+		filter = append(filter, bson.E{Key: "user_id", Value: *s.UserID})
+	}
+
+	if s.ApartmentID != nil {
+		filter = append(filter, bson.E{Key: "apartment_id", Value: *s.ApartmentID})
+	}
+
+	return filter
+}
+
+func (s *filter) pendingDelivery() any {
+	filter := bson.D{}
+
+	if len(s.ID) != 0 {
+		filter = append(filter, bson.E{Key: "_id", Value: s.ID})
+	}
+
+	if s.UserID != nil {
+		filter = append(filter, bson.E{Key: "user_id", Value: *s.UserID})
+	}
+
+	if s.SendTill != nil {
+		filter = append(filter, bson.E{Key: "send_at", Value: bson.D{{Key: "$lte", Value: *s.SendTill}}})
+	}
+
+	return filter
+}
+
+func (s *filter) crawlCursor() any {
+	filter := bson.D{}
+
+	if len(s.ID) != 0 {
+		filter = append(filter, bson.E{Key: "_id", Value: s.ID})
+	}
+
+	return filter
+}
+
+func (s *filter) archive() any {
+	filter := bson.D{}
+
+	if s.ApartmentID != nil {
+		filter = append(filter, bson.E{Key: "apartment_id", Value: *s.ApartmentID})
+	}
+
+	if s.RemovedFrom != nil {
+		filter = append(filter, bson.E{Key: "removed_at", Value: bson.D{{Key: "$gte", Value: *s.RemovedFrom}}})
+	}
+
+	if len(s.Reasons) != 0 {
+		filter = append(filter, bson.E{
+			Key:   "reason",
+			Value: bson.D{{Key: "$in", Value: s.Reasons}},
+		})
+	}
+
+	return filter
+}
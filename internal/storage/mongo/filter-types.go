@@ -1,5 +1,7 @@
 package mongo
 
+import "time"
+
 type filter struct {
 	ID             string              `bson:"_id"`
 	AdType         *int64              `bson:"ad_type"`
@@ -18,8 +20,45 @@ type filter struct {
 	IsOwner        *bool               `bson:"is_owner"`
 	Coordinates    *coordinates        `bson:"location_coordinates"`
 	MaxDistance    *float64            `bson:"max_distance"`
+	MinScore       *float64            `bson:"min_score"`
+	Currency       string              `bson:"currency"`
+	ShareToken     *string             `bson:"share_token"`
+	Subscribers    []int64             `bson:"subscribers"`
+	SubscriberID   *int64              `bson:"-"`
 	PauseTimestamp *int64              `bson:"pause_timestamp"`
 	FromTimestamp  *int64              `bson:"-"`
+	Date           *time.Time          `bson:"-"`
+	IsSaved        *bool               `bson:"-"`
+	IsHidden       *bool               `bson:"-"`
+	Statuses       []int64             `bson:"-"`
+	ViewingFrom    *time.Time          `bson:"-"`
+	ViewingTill    *time.Time          `bson:"-"`
+	IsReminded     *bool               `bson:"-"`
+	RemovedFrom    *time.Time          `bson:"-"`
+	Reasons        []int64             `bson:"-"`
+	SendTill       *time.Time          `bson:"-"`
+
+	// USDMinPrice and USDMaxPrice compare the apartments in the other currency than the filter
+	USDMinPrice *float64 `bson:"-"`
+	USDMaxPrice *float64 `bson:"-"`
+
+	ExcludedApartmentIDs []int64 `bson:"-"`
+	ApartmentIDs         []int64 `bson:"-"`
+	// WithinDistance selects the apartments within the distance without sorting by it,
+	// $near isn't supported by the count
+	WithinDistance bool `bson:"-"`
+	// Cursor selects the apartments from the cursor of the sort
+	Cursor *cursor `bson:"-"`
+}
+
+// cursor selects the apartments from the key of the sort field,
+// the apartments with the same key are ordered by the id.
+type cursor struct {
+	Field     string
+	Key       any
+	ID        int64
+	Ascending bool
+	Inclusive bool
 }
 
 type coordinates struct {
@@ -29,6 +29,7 @@ func (s *mongoDB) filterCollectionSetting() error {
 func (s *mongoDB) SaveFilter(ctx context.Context, f server.Filter) error {
 	filter := toMongoFilter(f)
 	filter.Name = nil
+	filter.ShareToken = nil
 
 	newFilter := toMongoFilter(f)
 	return s.upsert(ctx, filterCollection, filter, newFilter)
@@ -45,9 +45,18 @@ func NewStorage(cfg Config) (*mongoDB, error) {
 		db: client.Database(cfg.Database),
 	}
 
+	if err := m.archiveCollectionSetting(cfg.ArchiveRetention); err != nil {
+		return nil, fmt.Errorf("failed to set archive retention: %w", err)
+	}
+
 	return m, nil
 }
 
+// Health pings the database
+func (s *mongoDB) Health(ctx context.Context) error {
+	return s.db.Client().Ping(ctx, nil)
+}
+
 func (s *mongoDB) insert(ctx context.Context, collectionName string, obj any) error {
 	_, err := s.db.Collection(collectionName).InsertOne(ctx, obj, options.InsertOne())
 
@@ -60,8 +69,8 @@ func (s *mongoDB) upsert(ctx context.Context, collectionName string, f filter, o
 	return err
 }
 
-func find[T any](ctx context.Context, m *mongoDB, collectionName string, f filter) (<-chan T, error) {
-	cur, err := m.db.Collection(collectionName).Find(ctx, f.forCollection(collectionName))
+func find[T any](ctx context.Context, m *mongoDB, collectionName string, f filter, opts ...*options.FindOptions) (<-chan T, error) {
+	cur, err := m.db.Collection(collectionName).Find(ctx, f.forCollection(collectionName), opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -97,7 +106,7 @@ func (s *mongoDB) delete(ctx context.Context, collectionName string, f filter) e
 	return err
 }
 
-func (s *mongoDB) deleteAll(ctx context.Context, collectionName string) error {
-	_, err := s.db.Collection(collectionName).DeleteMany(ctx, bson.M{})
+func (s *mongoDB) deleteMany(ctx context.Context, collectionName string, f filter) error {
+	_, err := s.db.Collection(collectionName).DeleteMany(ctx, f.forCollection(collectionName))
 	return err
 }
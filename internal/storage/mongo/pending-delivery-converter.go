@@ -0,0 +1,49 @@
+package mongo
+
+import (
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+func toMongoPendingDelivery(in server.PendingDelivery) pendingDelivery {
+	out := pendingDelivery{
+		ID:          apartmentMarkID(in.UserID, in.ApartmentID),
+		UserID:      in.UserID,
+		ApartmentID: in.ApartmentID,
+		Filters:     in.Filters,
+		SendAt:      in.SendAt,
+	}
+
+	if in.Score != nil {
+		out.Score = &score{
+			Value:   in.Score.Value,
+			Reasons: in.Score.Reasons,
+		}
+	}
+
+	return out
+}
+
+func toPendingDelivery(in pendingDelivery) server.PendingDelivery {
+	out := server.PendingDelivery{
+		UserID:      in.UserID,
+		ApartmentID: in.ApartmentID,
+		Filters:     in.Filters,
+		SendAt:      in.SendAt,
+	}
+
+	if in.Score != nil {
+		out.Score = &server.Score{
+			Value:   in.Score.Value,
+			Reasons: in.Score.Reasons,
+		}
+	}
+
+	return out
+}
+
+func toMongoPendingDeliveryFilter(in server.PendingDeliveryFilter) filter {
+	return filter{
+		UserID:   in.UserID,
+		SendTill: in.SendTill,
+	}
+}
@@ -0,0 +1,17 @@
+package mongo
+
+import "time"
+
+type pendingDelivery struct {
+	ID          string    `bson:"_id"`
+	UserID      int64     `bson:"user_id"`
+	ApartmentID int64     `bson:"apartment_id"`
+	Filters     []string  `bson:"filters"`
+	Score       *score    `bson:"score,omitempty"`
+	SendAt      time.Time `bson:"send_at"`
+}
+
+type score struct {
+	Value   float64  `bson:"value"`
+	Reasons []string `bson:"reasons"`
+}
@@ -0,0 +1,35 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+var pendingDeliveryCollection = "pending_delivery"
+
+func (s *mongoDB) SavePendingDelivery(ctx context.Context, d server.PendingDelivery) error {
+	doc := toMongoPendingDelivery(d)
+	f := filter{
+		ID: doc.ID,
+	}
+	return s.upsert(ctx, pendingDeliveryCollection, f, doc)
+}
+
+func (s *mongoDB) PendingDeliveries(ctx context.Context, f server.PendingDeliveryFilter) ([]server.PendingDelivery, error) {
+	resultCh, err := find[pendingDelivery](ctx, s, pendingDeliveryCollection, toMongoPendingDeliveryFilter(f))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]server.PendingDelivery, 0)
+	for d := range resultCh {
+		result = append(result, toPendingDelivery(d))
+	}
+
+	return result, nil
+}
+
+func (s *mongoDB) DeletePendingDeliveries(ctx context.Context, f server.PendingDeliveryFilter) error {
+	return s.deleteMany(ctx, pendingDeliveryCollection, toMongoPendingDeliveryFilter(f))
+}
@@ -0,0 +1,58 @@
+package mongo
+
+import (
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+func toMongoStats(in server.Stats) stats {
+	return stats{
+		ID:                  in.Date.Format("2006-01-02") + ":" + in.Key(),
+		Date:                in.Date,
+		AdType:              in.AdType,
+		City:                in.City,
+		District:            in.District,
+		Rooms:               in.Rooms,
+		Count:               in.Count,
+		P25Price:            in.P25Price,
+		MedianPrice:         in.MedianPrice,
+		P75Price:            in.P75Price,
+		MedianPricePerMeter: in.MedianPricePerMeter,
+		MedianDaysOnMarket:  in.MedianDaysOnMarket,
+	}
+}
+
+func toStats(in stats) server.Stats {
+	return server.Stats{
+		Date:                in.Date,
+		AdType:              in.AdType,
+		City:                in.City,
+		District:            in.District,
+		Rooms:               in.Rooms,
+		Count:               in.Count,
+		P25Price:            in.P25Price,
+		MedianPrice:         in.MedianPrice,
+		P75Price:            in.P75Price,
+		MedianPricePerMeter: in.MedianPricePerMeter,
+		MedianDaysOnMarket:  in.MedianDaysOnMarket,
+	}
+}
+
+func toMongoStatsFilter(in server.StatsFilter) filter {
+	out := filter{
+		Date:     in.Date,
+		AdType:   in.AdType,
+		CityName: in.City,
+	}
+
+	if in.District != nil {
+		out.District = map[string]struct{}{*in.District: {}}
+	}
+
+	if in.Rooms != nil {
+		// stats segments have an exact room count
+		rooms := float64(*in.Rooms)
+		out.MinRooms = &rooms
+	}
+
+	return out
+}
@@ -0,0 +1,19 @@
+package mongo
+
+import "time"
+
+type stats struct {
+	ID       string    `bson:"_id"`
+	Date     time.Time `bson:"date"`
+	AdType   int64     `bson:"ad_type"`
+	City     string    `bson:"city"`
+	District string    `bson:"district"`
+	Rooms    int64     `bson:"rooms"`
+
+	Count               int64   `bson:"count"`
+	P25Price            float64 `bson:"p25_price"`
+	MedianPrice         float64 `bson:"median_price"`
+	P75Price            float64 `bson:"p75_price"`
+	MedianPricePerMeter float64 `bson:"median_price_per_meter"`
+	MedianDaysOnMarket  float64 `bson:"median_days_on_market"`
+}
@@ -0,0 +1,37 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/irbgeo/apartment-bot/internal/server"
+)
+
+var statsCollection = "stats"
+
+func (s *mongoDB) SaveStats(ctx context.Context, stats []server.Stats) error {
+	for _, st := range stats {
+		doc := toMongoStats(st)
+		f := filter{
+			ID: doc.ID,
+		}
+
+		if err := s.upsert(ctx, statsCollection, f, doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *mongoDB) Stats(ctx context.Context, f server.StatsFilter) ([]server.Stats, error) {
+	resultCh, err := find[stats](ctx, s, statsCollection, toMongoStatsFilter(f))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]server.Stats, 0)
+	for st := range resultCh {
+		result = append(result, toStats(st))
+	}
+
+	return result, nil
+}
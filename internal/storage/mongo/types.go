@@ -1,8 +1,12 @@
 package mongo
 
+import "time"
+
 type Config struct {
 	Address  string
 	Username string
 	Password string
 	Database string
+	// ArchiveRetention is the time the removed apartments are kept, zero keeps them forever
+	ArchiveRetention time.Duration
 }
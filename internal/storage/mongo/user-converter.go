@@ -1,21 +1,41 @@
 package mongo
 
 import (
+	"time"
+
 	"github.com/irbgeo/apartment-bot/internal/server"
 )
 
 func toMongoUser(in server.User) user {
 	return user{
-		ID:          in.ID,
-		ClientID:    in.ClientID,
-		IsSuperuser: in.IsSuperuser,
+		ID:               in.ID,
+		ClientID:         in.ClientID,
+		IsSuperuser:      in.IsSuperuser,
+		IsBanned:         in.IsBanned,
+		IsBlocked:        in.IsBlocked,
+		LastActivityAt:   in.LastActivityAt,
+		Plan:             in.Plan,
+		PlanExpiresAt:    in.PlanExpiresAt,
+		DigestInterval:   int64(in.DigestInterval.Seconds()),
+		HistoryReplays:   in.HistoryReplays,
+		HistoryReplayDay: in.HistoryReplayDay,
+		Currency:         string(in.Currency),
 	}
 }
 
 func toserverUser(in user) server.User {
 	return server.User{
-		ID:          in.ID,
-		ClientID:    in.ClientID,
-		IsSuperuser: in.IsSuperuser,
+		ID:               in.ID,
+		ClientID:         in.ClientID,
+		IsSuperuser:      in.IsSuperuser,
+		IsBanned:         in.IsBanned,
+		IsBlocked:        in.IsBlocked,
+		LastActivityAt:   in.LastActivityAt,
+		Plan:             in.Plan,
+		PlanExpiresAt:    in.PlanExpiresAt,
+		DigestInterval:   time.Duration(in.DigestInterval) * time.Second,
+		HistoryReplays:   in.HistoryReplays,
+		HistoryReplayDay: in.HistoryReplayDay,
+		Currency:         server.Currency(in.Currency),
 	}
 }
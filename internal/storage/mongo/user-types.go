@@ -1,7 +1,18 @@
 package mongo
 
+import "time"
+
 type user struct {
-	ID          int64 `bson:"tg_id"`
-	ClientID    int64 `bson:"client_id"`
-	IsSuperuser bool  `bson:"is_superuser"`
+	ID               int64      `bson:"tg_id"`
+	ClientID         int64      `bson:"client_id"`
+	IsSuperuser      bool       `bson:"is_superuser"`
+	IsBanned         bool       `bson:"is_banned"`
+	IsBlocked        bool       `bson:"is_blocked"`
+	LastActivityAt   *time.Time `bson:"last_activity_at"`
+	Plan             string     `bson:"plan"`
+	PlanExpiresAt    *time.Time `bson:"plan_expires_at"`
+	DigestInterval   int64      `bson:"digest_interval"`
+	HistoryReplays   int64      `bson:"history_replays"`
+	HistoryReplayDay string     `bson:"history_replay_day"`
+	Currency         string     `bson:"currency"`
 }
@@ -51,3 +51,17 @@ func (s *mongoDB) User(ctx context.Context, f server.Filter) (server.User, error
 		return toserverUser(u), nil
 	}
 }
+
+func (s *mongoDB) Users(ctx context.Context) ([]server.User, error) {
+	resultCh, err := find[user](ctx, s, userCollection, toMongoFilter(server.Filter{}))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]server.User, 0)
+	for u := range resultCh {
+		result = append(result, toserverUser(u))
+	}
+
+	return result, nil
+}
@@ -0,0 +1,31 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestInjectExtract(t *testing.T) {
+	_, err := Init(Config{})
+	require.NoError(t, err)
+
+	provider := sdktrace.NewTracerProvider()
+	defer provider.Shutdown(context.Background()) // nolint: errcheck
+
+	ctx, span := provider.Tracer("test").Start(context.Background(), "test")
+	defer span.End()
+
+	carrier := Inject(ctx)
+	require.NotEmpty(t, carrier)
+
+	extracted := trace.SpanContextFromContext(Extract(context.Background(), carrier))
+	require.Equal(t, span.SpanContext().TraceID(), extracted.TraceID())
+	require.Equal(t, span.SpanContext().SpanID(), extracted.SpanID())
+
+	require.Nil(t, Inject(context.Background()))
+	require.Equal(t, context.Background(), Extract(context.Background(), nil))
+}
@@ -0,0 +1,10 @@
+package tracing
+
+// Config contains configuration for the trace exporter
+type Config struct {
+	ServiceName string
+	// Endpoint is the OTLP/HTTP collector address, tracing is no-op when it is empty
+	Endpoint    string
+	Insecure    bool
+	SampleRatio float64
+}